@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/joshckidd/chirpy/internal/auth"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func (cfg *apiConfig) deleteUser(w http.ResponseWriter, r *http.Request) {
+	type deleteParam struct {
+		Password string `json:"password"`
+	}
+
+	type returnDeletion struct {
+		DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+	}
+
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := deleteParam{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Invalid request")
+		return
+	}
+
+	user, err := cfg.db.GetUser(r.Context(), id)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	val, err := auth.CheckPasswordHash(params.Password, user.HashedPassword)
+	if err != nil || !val {
+		respondWithError(w, 401, "Incorrect password")
+		return
+	}
+
+	// Scheduling, signing the user out everywhere and the audit event go
+	// together, so a failure can't leave a scheduled deletion with live
+	// sessions.
+	tx, err := cfg.db.BeginTx(r.Context())
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	scheduledAt, err := tx.ScheduleUserDeletion(r.Context(), id)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	err = tx.RevokeAllRefreshTokensForUser(r.Context(), id)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	err = appendAudit(r.Context(), tx, r, auditRecord{
+		Action:     "user.deletion_scheduled",
+		ActorID:    id,
+		TargetType: "user",
+		TargetID:   id.String(),
+		Metadata:   map[string]time.Time{"scheduled_at": scheduledAt.Time},
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 202, returnDeletion{DeletionScheduledAt: scheduledAt.Time})
+}
+
+// purgeDeletedUsers hard-deletes accounts whose grace period has ended.
+func (cfg *apiConfig) purgeDeletedUsers(ctx context.Context) error {
+	users, err := cfg.db.GetUsersDueForDeletion(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, u := range users {
+		err = cfg.purgeUser(ctx, u)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// purgeUser deletes the user and records it in the same transaction. Chirps,
+// tokens and everything else owned by the user go with it through the
+// ON DELETE CASCADE foreign keys; export files on disk are removed once the
+// transaction commits.
+func (cfg *apiConfig) purgeUser(ctx context.Context, u database.GetUsersDueForDeletionRow) error {
+	tx, err := cfg.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	exports, err := tx.GetDataExportFilesForUser(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+
+	// A login since the user was listed cancels the deletion, in which
+	// case nothing is deleted here.
+	n, err := tx.DeleteUser(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
+
+	err = tx.CreateAccountDeletion(ctx, database.CreateAccountDeletionParams{
+		UserID:      u.ID,
+		ScheduledAt: u.DeletionScheduledAt.Time,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = appendAudit(ctx, tx, nil, auditRecord{
+		Action:     "user.deleted",
+		TargetType: "user",
+		TargetID:   u.ID.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range exports {
+		os.Remove(p.String)
+	}
+
+	return nil
+}
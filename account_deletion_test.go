@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func TestDeleteUser(t *testing.T) {
+	type returnDeletion struct {
+		DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+	}
+
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	c := a.chirp(bob, "gone soon")
+
+	a.expect(401, "DELETE", "/api/users/me", "", map[string]string{"password": "hunter2"})
+	a.expect(401, "DELETE", "/api/users/me", bob.Token, map[string]string{"password": "wrong"})
+	res := decode[returnDeletion](t, a.expect(202, "DELETE", "/api/users/me", bob.Token, map[string]string{"password": "hunter2"}))
+	if res.DeletionScheduledAt.Before(time.Now()) {
+		t.Errorf("Want the deletion scheduled in the future, got %v", res.DeletionScheduledAt)
+	}
+	a.expect(401, "POST", "/api/refresh", bob.RefreshToken, nil)
+	a.expectAudited("user.deletion_scheduled")
+
+	// Logging back in during the grace period cancels the deletion.
+	a.expect(200, "POST", "/api/login", "", map[string]string{"email": bob.Email, "password": "hunter2"})
+	a.store.SetClock(func() time.Time { return time.Now().AddDate(0, 1, 0) })
+	err := a.cfg.purgeDeletedUsers(context.Background())
+	if err != nil {
+		t.Fatalf("purgeDeletedUsers returned error: %v", err)
+	}
+	a.expect(200, "GET", "/api/chirps/"+c.ID.String(), alice.Token, nil)
+
+	a.store.SetClock(time.Now)
+	a.expect(202, "DELETE", "/api/users/me", bob.Token, map[string]string{"password": "hunter2"})
+	a.store.SetClock(func() time.Time { return time.Now().AddDate(0, 1, 0) })
+	err = a.cfg.purgeDeletedUsers(context.Background())
+	if err != nil {
+		t.Fatalf("purgeDeletedUsers returned error: %v", err)
+	}
+	a.expect(404, "GET", "/api/chirps/"+c.ID.String(), alice.Token, nil)
+	a.expect(500, "POST", "/api/login", "", map[string]string{"email": bob.Email, "password": "hunter2"})
+}
+
+// TestPurgeDeletedUser checks that the purge takes everything the user owns
+// with it, including export files on disk, and is audited.
+func TestPurgeDeletedUser(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	ctx := context.Background()
+	c := a.chirp(alice, "bob likes this")
+	a.expect(204, "POST", "/api/chirps/"+c.ID.String()+"/bookmark", bob.Token, nil)
+	a.expect(200, "POST", "/api/users/"+bob.ID.String()+"/follow", alice.Token, nil)
+	export := decode[returnDataExport](t, a.expect(202, "POST", "/api/users/me/export", bob.Token, nil))
+	a.cfg.background.Wait()
+	built, err := a.store.GetDataExport(ctx, export.ID)
+	if err != nil || !built.FilePath.Valid {
+		t.Fatalf("Want the export built, got %+v, %v", built, err)
+	}
+
+	a.expect(400, "DELETE", "/api/users/me", bob.Token, []byte("not json"))
+	a.expect(202, "DELETE", "/api/users/me", bob.Token, map[string]string{"password": "hunter2"})
+	a.store.SetClock(func() time.Time { return time.Now().AddDate(0, 1, 0) })
+	err = a.cfg.purgeDeletedUsers(ctx)
+	if err != nil {
+		t.Fatalf("purgeDeletedUsers returned error: %v", err)
+	}
+
+	_, err = a.store.GetUser(ctx, bob.ID)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Want bob deleted, got %v", err)
+	}
+	_, err = a.store.GetFollow(ctx, database.GetFollowParams{FollowerID: alice.ID, FolloweeID: bob.ID})
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Want alice's follow of bob deleted, got %v", err)
+	}
+	ids, err := a.store.GetBookmarkedChirpIDs(ctx, bob.ID)
+	if err != nil || len(ids) != 0 {
+		t.Errorf("Want bob's bookmarks deleted, got %v, %v", ids, err)
+	}
+	_, err = a.store.GetDataExport(ctx, export.ID)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Want bob's export deleted, got %v", err)
+	}
+	if _, err := os.Stat(built.FilePath.String); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Want the export file removed, got %v", err)
+	}
+	// alice's chirp isn't bob's to take with him.
+	a.expect(200, "GET", "/api/chirps/"+c.ID.String(), alice.Token, nil)
+	a.expectAudited("user.deleted")
+}
@@ -0,0 +1,484 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/audit"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+const (
+	auditVerifyBatchSize = 1000
+	// auditQueueMax bounds the auth events held while the database is
+	// unreachable. Beyond it new events are dropped and logged.
+	auditQueueMax = 10000
+	// auditFlushInterval is how often queued auth events are written.
+	auditFlushInterval = time.Second
+)
+
+// auditRecord describes something worth recording in the audit log. A nil
+// ActorID means the system did it, e.g. a background worker or a webhook.
+type auditRecord struct {
+	Action     string
+	ActorID    uuid.UUID
+	TargetType string
+	TargetID   string
+	Metadata   any
+}
+
+// appendAudit adds rec to the end of the audit chain. q must belong to a
+// transaction: the chain is locked until it ends, so concurrent writers
+// can't both link to the same previous event. r may be nil outside of a
+// request.
+func appendAudit(ctx context.Context, q database.Querier, r *http.Request, rec auditRecord) error {
+	entry, err := newAuditEntry(r, rec)
+	if err != nil {
+		return err
+	}
+	return writeAuditEntries(ctx, q, []audit.Entry{entry})
+}
+
+// newAuditEntry describes rec as it will be stored, without its place in
+// the chain.
+func newAuditEntry(r *http.Request, rec auditRecord) (audit.Entry, error) {
+	metadata := []byte("{}")
+	if rec.Metadata != nil {
+		var err error
+		metadata, err = json.Marshal(rec.Metadata)
+		if err != nil {
+			return audit.Entry{}, err
+		}
+	}
+
+	entry := audit.Entry{
+		// Postgres keeps microseconds, and the hash has to match what is
+		// read back.
+		CreatedAt:  time.Now().UTC().Truncate(time.Microsecond),
+		Action:     rec.Action,
+		TargetType: rec.TargetType,
+		TargetID:   rec.TargetID,
+		Metadata:   metadata,
+	}
+	if rec.ActorID != uuid.Nil {
+		entry.ActorID = rec.ActorID.String()
+	}
+	if r != nil {
+		entry.IP = clientIP(r)
+		entry.UserAgent = r.UserAgent()
+		entry.RequestID = requestID(r.Context())
+	}
+	return entry, nil
+}
+
+// writeAuditEntries links entries, in order, to the end of the audit chain.
+// Like appendAudit, q must belong to a transaction.
+func writeAuditEntries(ctx context.Context, q database.Querier, entries []audit.Entry) error {
+	err := q.LockAuditChain(ctx)
+	if err != nil {
+		return err
+	}
+
+	prevHash, err := q.GetLastAuditHash(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		entry.PrevHash = prevHash
+		entry.Hash = entry.ComputeHash()
+
+		actorID := uuid.NullUUID{}
+		if entry.ActorID != "" {
+			actorID.UUID, err = uuid.Parse(entry.ActorID)
+			if err != nil {
+				return err
+			}
+			actorID.Valid = true
+		}
+
+		_, err = q.CreateAuditEvent(ctx, database.CreateAuditEventParams{
+			CreatedAt:  entry.CreatedAt,
+			Action:     entry.Action,
+			ActorID:    actorID,
+			TargetType: entry.TargetType,
+			TargetID:   entry.TargetID,
+			Ip:         entry.IP,
+			UserAgent:  entry.UserAgent,
+			RequestID:  entry.RequestID,
+			Metadata:   entry.Metadata,
+			PrevHash:   entry.PrevHash,
+			Hash:       entry.Hash,
+		})
+		if err != nil {
+			return err
+		}
+		prevHash = entry.Hash
+	}
+
+	return q.SetAuditChainHead(ctx, prevHash)
+}
+
+// audit records rec in a transaction of its own, for changes that weren't
+// made in one. A failure is logged rather than failing the request, as the
+// change it describes has already happened.
+func (cfg *apiConfig) audit(r *http.Request, rec auditRecord) {
+	err := cfg.appendAuditTx(r.Context(), r, rec)
+	if err != nil {
+		cfg.logs.Logger("audit").Error("failed to record audit event",
+			"action", rec.Action,
+			"request_id", requestID(r.Context()),
+			"error", err,
+		)
+	}
+}
+
+// auditQueue holds audit events waiting to be written together by
+// flushAuditQueue.
+type auditQueue struct {
+	mu      sync.Mutex
+	pending []audit.Entry
+	// writing is held while a batch is written, so batches are chained in
+	// the order they were queued.
+	writing sync.Mutex
+}
+
+// auditAuth queues rec to be written with others in the background. It is
+// for auth events, such as logins and refreshes, which are frequent enough
+// that locking the chain for each one would make every login wait on every
+// other. Admin and security actions are written with audit or appendAudit
+// instead, so they're in the chain before the request returns.
+func (cfg *apiConfig) auditAuth(r *http.Request, rec auditRecord) {
+	logger := cfg.logs.Logger("audit")
+
+	entry, err := newAuditEntry(r, rec)
+	if err != nil {
+		logger.Error("failed to record audit event",
+			"action", rec.Action,
+			"request_id", requestID(r.Context()),
+			"error", err,
+		)
+		return
+	}
+
+	q := &cfg.auditQueue
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) >= auditQueueMax {
+		logger.Error("audit queue full, dropping event",
+			"action", rec.Action,
+			"request_id", requestID(r.Context()),
+		)
+		return
+	}
+	q.pending = append(q.pending, entry)
+}
+
+// flushAuditQueue writes the queued audit events in one transaction, so the
+// chain is locked once for the batch. If that fails they're kept for the
+// next flush.
+func (cfg *apiConfig) flushAuditQueue(ctx context.Context) error {
+	q := &cfg.auditQueue
+	q.writing.Lock()
+	defer q.writing.Unlock()
+
+	q.mu.Lock()
+	batch := q.pending
+	q.pending = nil
+	q.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	err := cfg.writeAuditBatch(ctx, batch)
+	if err != nil {
+		q.mu.Lock()
+		q.pending = append(batch, q.pending...)
+		q.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (cfg *apiConfig) writeAuditBatch(ctx context.Context, entries []audit.Entry) error {
+	tx, err := cfg.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = writeAuditEntries(ctx, tx, entries)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (cfg *apiConfig) appendAuditTx(ctx context.Context, r *http.Request, rec auditRecord) error {
+	tx, err := cfg.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = appendAudit(ctx, tx, r, rec)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// pruneAuditEvents applies the retention policy. It is the only thing the
+// database lets delete audit events. The oldest remaining event keeps its
+// link to the last pruned one, and that hash is recorded with the pruning
+// as the boundary the chain is verified from.
+func (cfg *apiConfig) pruneAuditEvents(ctx context.Context) error {
+	if cfg.auditRetention <= 0 {
+		return nil
+	}
+
+	tx, err := cfg.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.LockAuditChain(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = tx.AllowAuditPrune(ctx)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().UTC().Add(-cfg.auditRetention)
+	n, err := tx.DeleteAuditEventsBefore(ctx, cutoff)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
+
+	// If every event was pruned, the boundary is the head, which the
+	// audit.pruned event links to.
+	boundary, err := tx.GetFirstAuditPrevHash(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		boundary, err = tx.GetLastAuditHash(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = appendAudit(ctx, tx, nil, auditRecord{
+		Action: "audit.pruned",
+		Metadata: auditPrune{
+			Deleted:      n,
+			Before:       cutoff,
+			BoundaryHash: &boundary,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// auditPrune is the metadata of an audit.pruned event.
+type auditPrune struct {
+	Deleted      int64     `json:"deleted"`
+	Before       time.Time `json:"before"`
+	BoundaryHash *string   `json:"boundary_hash"`
+}
+
+func (cfg *apiConfig) getAuditEvents(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := getPagination(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	params := database.GetAuditEventsParams{
+		Lim: limit,
+		Off: offset,
+	}
+
+	if s := query.Get("actor_id"); s != "" {
+		actorID, err := uuid.Parse(s)
+		if err != nil {
+			respondWithError(w, 400, err.Error())
+			return
+		}
+		params.ActorID = uuid.NullUUID{UUID: actorID, Valid: true}
+	}
+	if s := query.Get("action"); s != "" {
+		params.Action = sql.NullString{String: s, Valid: true}
+	}
+	if s := query.Get("target_id"); s != "" {
+		params.TargetID = sql.NullString{String: s, Valid: true}
+	}
+	for name, dest := range map[string]*sql.NullTime{"since": &params.Since, "until": &params.Until} {
+		s := query.Get(name)
+		if s == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			respondWithError(w, 400, "Invalid "+name+" time")
+			return
+		}
+		*dest = sql.NullTime{Time: t.UTC(), Valid: true}
+	}
+
+	events, err := cfg.db.GetAuditEvents(r.Context(), params)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	if events == nil {
+		events = []database.AuditEvent{}
+	}
+
+	respondWithJSON(w, 200, events)
+}
+
+// verifyAuditEvents walks the whole chain and reports the first event that
+// was changed, removed or reordered. The chain has to start at the boundary
+// the last pruning recorded and reach the head stored alongside it, so
+// removing the oldest or newest events is caught too.
+func (cfg *apiConfig) verifyAuditEvents(w http.ResponseWriter, r *http.Request) {
+	type verifyResult struct {
+		Valid          bool   `json:"valid"`
+		Checked        int    `json:"checked"`
+		FirstInvalidID *int64 `json:"first_invalid_id,omitempty"`
+		Error          string `json:"error,omitempty"`
+	}
+
+	// The head is read first: events appended during the walk come after
+	// it, so it is still reached.
+	head, err := cfg.db.GetLastAuditHash(r.Context())
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	anchor, trustAnchor, err := cfg.auditAnchor(r.Context())
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	res := verifyResult{Valid: true}
+	prevHash := anchor
+	reachedHead := head == anchor
+	var afterID int64
+	for {
+		events, err := cfg.db.GetAuditEventsAfter(r.Context(), database.GetAuditEventsAfterParams{
+			ID:    afterID,
+			Limit: auditVerifyBatchSize,
+		})
+		if err != nil {
+			respondWithError(w, 500, err.Error())
+			return
+		}
+		if len(events) == 0 {
+			break
+		}
+
+		entries := make([]audit.Entry, len(events))
+		for i, e := range events {
+			entries[i] = toAuditEntry(e)
+		}
+		if trustAnchor {
+			prevHash = entries[0].PrevHash
+			trustAnchor = false
+		}
+
+		i, err := audit.Verify(entries, prevHash)
+		if err != nil {
+			res.Valid = false
+			res.FirstInvalidID = &events[i].ID
+			res.Error = err.Error()
+			res.Checked += i
+			break
+		}
+
+		for _, e := range events {
+			reachedHead = reachedHead || e.Hash == head
+		}
+		res.Checked += len(events)
+		prevHash = events[len(events)-1].Hash
+		afterID = events[len(events)-1].ID
+	}
+
+	if res.Valid && !reachedHead {
+		res.Valid = false
+		res.Error = "the newest events have been removed"
+	}
+
+	respondWithJSON(w, 200, res)
+}
+
+// auditAnchor returns the hash the oldest remaining event has to link to:
+// the boundary recorded by the last pruning, or "" if there hasn't been
+// one. Prunings from before the boundary was recorded don't have one, so
+// the oldest event's link is taken on trust.
+func (cfg *apiConfig) auditAnchor(ctx context.Context) (string, bool, error) {
+	event, err := cfg.db.GetLatestAuditPrune(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	var prune auditPrune
+	err = json.Unmarshal(event.Metadata, &prune)
+	if err != nil {
+		return "", false, err
+	}
+	if prune.BoundaryHash == nil {
+		return "", true, nil
+	}
+	return *prune.BoundaryHash, false, nil
+}
+
+func toAuditEntry(e database.AuditEvent) audit.Entry {
+	entry := audit.Entry{
+		CreatedAt:  e.CreatedAt,
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		IP:         e.Ip,
+		UserAgent:  e.UserAgent,
+		RequestID:  e.RequestID,
+		Metadata:   e.Metadata,
+		PrevHash:   e.PrevHash,
+		Hash:       e.Hash,
+	}
+	if e.ActorID.Valid {
+		entry.ActorID = e.ActorID.UUID.String()
+	}
+	return entry
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func TestAuditEvents(t *testing.T) {
+	a := newTestAPI(t)
+	admin := a.signup("admin")
+	a.setRole(admin, roleAdmin)
+	bob := a.signup("bob")
+	a.expect(401, "POST", "/api/login", "", map[string]string{"email": bob.Email, "password": "wrong"})
+	a.flushAudit()
+
+	a.expect(403, "GET", "/admin/audit", bob.Token, nil)
+	events := decode[[]database.AuditEvent](t, a.expect(200, "GET", "/admin/audit", admin.Token, nil))
+	if len(events) != 3 || events[0].Action != "auth.login_failed" {
+		t.Errorf("Want 3 events newest first, got %+v", events)
+	}
+
+	events = decode[[]database.AuditEvent](t, a.expect(200, "GET", "/admin/audit?action=auth.login&actor_id="+bob.ID.String(), admin.Token, nil))
+	if len(events) != 1 || events[0].ActorID.UUID != bob.ID {
+		t.Errorf("Want bob's login, got %+v", events)
+	}
+
+	since := time.Now().Add(time.Hour).Format(time.RFC3339)
+	events = decode[[]database.AuditEvent](t, a.expect(200, "GET", "/admin/audit?since="+since, admin.Token, nil))
+	if len(events) != 0 {
+		t.Errorf("Want no events in the future, got %+v", events)
+	}
+	a.expect(400, "GET", "/admin/audit?since=yesterday", admin.Token, nil)
+	a.expect(400, "GET", "/admin/audit?actor_id=nobody", admin.Token, nil)
+}
+
+func TestVerifyAuditEvents(t *testing.T) {
+	type verifyResult struct {
+		Valid   bool `json:"valid"`
+		Checked int  `json:"checked"`
+	}
+
+	a := newTestAPI(t)
+	admin := a.signup("admin")
+	a.setRole(admin, roleAdmin)
+	a.signup("bob")
+	a.flushAudit()
+
+	a.expect(401, "GET", "/admin/audit/verify", "", nil)
+	res := decode[verifyResult](t, a.expect(200, "GET", "/admin/audit/verify", admin.Token, nil))
+	if !res.Valid || res.Checked != 2 {
+		t.Errorf("Want 2 valid events, got %+v", res)
+	}
+}
+
+// TestPruneAuditEvents checks that the chain still verifies after the
+// retention policy has run, and that nothing else can delete events.
+func TestPruneAuditEvents(t *testing.T) {
+	type verifyResult struct {
+		Valid   bool   `json:"valid"`
+		Checked int    `json:"checked"`
+		Error   string `json:"error"`
+	}
+
+	a := newTestAPI(t)
+	admin := a.signup("admin")
+	a.setRole(admin, roleAdmin)
+	a.signup("bob")
+	a.flushAudit()
+
+	_, err := a.store.DeleteAuditEventsBefore(context.Background(), time.Now().Add(time.Hour))
+	if err == nil {
+		t.Errorf("Want deleting audit events outside retention refused")
+	}
+
+	a.cfg.auditRetention = time.Nanosecond
+	err = a.cfg.pruneAuditEvents(context.Background())
+	if err != nil {
+		t.Fatalf("Error pruning audit events: %v", err)
+	}
+	res := decode[verifyResult](t, a.expect(200, "GET", "/admin/audit/verify", admin.Token, nil))
+	if !res.Valid || res.Checked != 1 {
+		t.Errorf("Want only the valid audit.pruned event, got %+v", res)
+	}
+
+	a.signup("carol")
+	a.flushAudit()
+	res = decode[verifyResult](t, a.expect(200, "GET", "/admin/audit/verify", admin.Token, nil))
+	if !res.Valid || res.Checked != 2 {
+		t.Errorf("Want 2 valid events, got %+v", res)
+	}
+	a.expectAudited("audit.pruned")
+}
+
+// TestAuthAuditQueue checks that auth events are written in batches, in the
+// order they happened, and that shutdown writes what is left.
+func TestAuthAuditQueue(t *testing.T) {
+	type verifyResult struct {
+		Valid   bool `json:"valid"`
+		Checked int  `json:"checked"`
+	}
+
+	a := newTestAPI(t)
+	admin := a.signup("admin")
+	a.setRole(admin, roleAdmin)
+	a.flushAudit()
+
+	bob := a.signup("bob")
+	a.expect(401, "POST", "/api/login", "", map[string]string{"email": bob.Email, "password": "wrong"})
+	a.expect(200, "POST", "/api/refresh", bob.RefreshToken, nil)
+	events := decode[[]database.AuditEvent](t, a.expect(200, "GET", "/admin/audit", admin.Token, nil))
+	if len(events) != 1 {
+		t.Errorf("Want the auth events queued, got %+v", events)
+	}
+
+	a.flushAudit()
+	want := []string{"auth.login", "auth.login", "auth.login_failed", "auth.refresh"}
+	if got := a.auditActions(); !slices.Equal(got, want) {
+		t.Errorf("Want %v, got %v", want, got)
+	}
+	res := decode[verifyResult](t, a.expect(200, "GET", "/admin/audit/verify", admin.Token, nil))
+	if !res.Valid || res.Checked != 4 {
+		t.Errorf("Want 4 valid events, got %+v", res)
+	}
+
+	a.expect(204, "POST", "/api/revoke", bob.RefreshToken, nil)
+	if code := a.cfg.shutdown(0, time.Second); code != 0 {
+		t.Errorf("Want a clean shutdown, got exit code %d", code)
+	}
+	a.expectAudited("auth.revoke")
+}
+
+// TestAuditedAccountEvents checks that changes to an account are audited
+// along with where the request came from.
+func TestAuditedAccountEvents(t *testing.T) {
+	a := newTestAPI(t)
+	bob := a.signup("bob")
+
+	a.expect(200, "POST", "/api/refresh", bob.RefreshToken, nil)
+	a.expect(204, "POST", "/api/revoke", bob.RefreshToken, nil)
+	a.expect(200, "PUT", "/api/users", bob.Token, map[string]string{"email": "robert@example.com", "password": "hunter3"})
+	rec := a.polka(map[string]any{"id": "evt_1", "event": "user.upgraded", "data": map[string]any{"user_id": bob.ID}}, testPolkaSecret)
+	if rec.Code != 204 {
+		t.Fatalf("Want 204 upgrading bob, got %d", rec.Code)
+	}
+	a.expect(202, "DELETE", "/api/users/me", bob.Token, map[string]string{"password": "hunter3"})
+
+	actions := a.auditActions()
+	for _, want := range []string{"auth.refresh", "auth.revoke", "user.email_changed", "user.password_changed", "billing.plan_changed", "user.deletion_scheduled"} {
+		if !slices.Contains(actions, want) {
+			t.Errorf("Want a %s audit event, got %v", want, actions)
+		}
+	}
+
+	req := httptest.NewRequest("POST", "/api/login", strings.NewReader(`{"email": "robert@example.com", "password": "wrong"}`))
+	req.Header.Set("User-Agent", "curl/8.0")
+	req.Header.Set("X-Request-ID", "req-123")
+	a.serve(req)
+	a.flushAudit()
+
+	events, err := a.store.GetAuditEvents(context.Background(), database.GetAuditEventsParams{Lim: 1})
+	if err != nil {
+		t.Fatalf("Error getting audit events: %v", err)
+	}
+	e := events[0]
+	if e.Action != "auth.login_failed" || e.Ip != "192.0.2.1" || e.UserAgent != "curl/8.0" || e.RequestID != "req-123" {
+		t.Errorf("Want the failed login with its request details, got %+v", e)
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/auth"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+// postBlock, like the other block and mute handlers, is open to suspended
+// and read-only users. See checkCanWrite.
+func (cfg *apiConfig) postBlock(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	target, ok := cfg.getTargetUser(w, r, id)
+	if !ok {
+		return
+	}
+
+	// The follows go with the block, so a failure can't leave a blocked
+	// user still following.
+	tx, err := cfg.db.BeginTx(r.Context())
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	err = tx.CreateBlock(r.Context(), database.CreateBlockParams{
+		BlockerID: id,
+		BlockedID: target.ID,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	err = tx.DeleteFollowsBetween(r.Context(), database.DeleteFollowsBetweenParams{
+		UserID:      id,
+		OtherUserID: target.ID,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 204, nil)
+}
+
+func (cfg *apiConfig) deleteBlock(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	targetID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	err = cfg.db.DeleteBlock(r.Context(), database.DeleteBlockParams{
+		BlockerID: id,
+		BlockedID: targetID,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 204, nil)
+}
+
+func (cfg *apiConfig) getBlocks(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	limit, offset, err := getPagination(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	blocks, err := cfg.db.GetBlocks(r.Context(), database.GetBlocksParams{
+		BlockerID: id,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	if blocks == nil {
+		blocks = []database.Block{}
+	}
+
+	respondWithJSON(w, 200, blocks)
+}
+
+func (cfg *apiConfig) postMute(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	target, ok := cfg.getTargetUser(w, r, id)
+	if !ok {
+		return
+	}
+
+	err = cfg.db.CreateMute(r.Context(), database.CreateMuteParams{
+		MuterID: id,
+		MutedID: target.ID,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 204, nil)
+}
+
+func (cfg *apiConfig) deleteMute(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	targetID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	err = cfg.db.DeleteMute(r.Context(), database.DeleteMuteParams{
+		MuterID: id,
+		MutedID: targetID,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 204, nil)
+}
+
+func (cfg *apiConfig) getMutes(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	limit, offset, err := getPagination(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	mutes, err := cfg.db.GetMutes(r.Context(), database.GetMutesParams{
+		MuterID: id,
+		Limit:   limit,
+		Offset:  offset,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	if mutes == nil {
+		mutes = []database.Mute{}
+	}
+
+	respondWithJSON(w, 200, mutes)
+}
+
+// getTargetUser reads the {userID} path value for block, mute and follow
+// requests and checks that it names another existing user. It writes the
+// error response itself and reports whether the handler should continue.
+func (cfg *apiConfig) getTargetUser(w http.ResponseWriter, r *http.Request, id uuid.UUID) (database.User, bool) {
+	targetID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return database.User{}, false
+	}
+	if targetID == id {
+		respondWithError(w, 400, "Cannot target yourself")
+		return database.User{}, false
+	}
+
+	target, err := cfg.db.GetUser(r.Context(), targetID)
+	if err != nil {
+		respondWithError(w, 404, "User not found")
+		return database.User{}, false
+	}
+
+	return target, true
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func TestBlocks(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	c := a.chirp(bob, "hello alice")
+	a.expect(200, "POST", "/api/users/"+bob.ID.String()+"/follow", alice.Token, nil)
+
+	path := "/api/users/" + bob.ID.String() + "/block"
+	a.expect(401, "POST", path, "", nil)
+	a.expect(400, "POST", "/api/users/"+alice.ID.String()+"/block", alice.Token, nil)
+	a.expect(404, "POST", "/api/users/"+uuid.NewString()+"/block", alice.Token, nil)
+	a.expect(204, "POST", path, alice.Token, nil)
+
+	blocks := decode[[]database.Block](t, a.expect(200, "GET", "/api/users/me/blocks", alice.Token, nil))
+	if len(blocks) != 1 || blocks[0].BlockedID != bob.ID {
+		t.Errorf("Want bob blocked, got %+v", blocks)
+	}
+
+	// A block hides chirps both ways and ends any follow between the two.
+	a.expect(404, "GET", "/api/chirps/"+c.ID.String(), alice.Token, nil)
+	a.expect(403, "POST", "/api/users/"+alice.ID.String()+"/follow", bob.Token, nil)
+	_, err := a.store.GetFollow(context.Background(), database.GetFollowParams{FollowerID: alice.ID, FolloweeID: bob.ID})
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Want the follow removed by the block, got %v", err)
+	}
+
+	a.expect(204, "DELETE", path, alice.Token, nil)
+	a.expect(200, "GET", "/api/chirps/"+c.ID.String(), alice.Token, nil)
+	blocks = decode[[]database.Block](t, a.expect(200, "GET", "/api/users/me/blocks", alice.Token, nil))
+	if len(blocks) != 0 {
+		t.Errorf("Want no blocks, got %+v", blocks)
+	}
+}
+
+func TestMutes(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	a.chirp(bob, "noise")
+
+	path := "/api/users/" + bob.ID.String() + "/mute"
+	a.expect(401, "POST", path, "", nil)
+	a.expect(400, "POST", "/api/users/"+alice.ID.String()+"/mute", alice.Token, nil)
+	a.expect(204, "POST", path, alice.Token, nil)
+
+	mutes := decode[[]database.Mute](t, a.expect(200, "GET", "/api/users/me/mutes", alice.Token, nil))
+	if len(mutes) != 1 || mutes[0].MutedID != bob.ID {
+		t.Errorf("Want bob muted, got %+v", mutes)
+	}
+	chirps := decode[[]returnChirp](t, a.expect(200, "GET", "/api/chirps", alice.Token, nil))
+	if len(chirps) != 0 {
+		t.Errorf("Want muted chirps hidden, got %+v", chirps)
+	}
+
+	a.expect(204, "DELETE", path, alice.Token, nil)
+	chirps = decode[[]returnChirp](t, a.expect(200, "GET", "/api/chirps", alice.Token, nil))
+	if len(chirps) != 1 {
+		t.Errorf("Want the chirp visible again, got %+v", chirps)
+	}
+}
+
+// TestBlockWhileRestricted checks that a restricted user, whose JWT still
+// works until it expires, can block and mute.
+func TestBlockWhileRestricted(t *testing.T) {
+	a := newTestAPI(t)
+	mod := a.signup("mod")
+	a.setRole(mod, roleModerator)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+
+	for _, action := range []string{"read_only", "suspend"} {
+		a.expect(201, "PUT", "/admin/users/"+alice.ID.String()+"/suspension", mod.Token, map[string]any{"action": action, "reason": "spam"})
+		a.expect(403, "POST", "/api/chirps", alice.Token, map[string]string{"body": "restricted"})
+
+		for _, kind := range []string{"block", "mute"} {
+			path := "/api/users/" + bob.ID.String() + "/" + kind
+			a.expect(204, "POST", path, alice.Token, nil)
+			a.expect(204, "DELETE", path, alice.Token, nil)
+		}
+
+		a.expect(201, "DELETE", "/admin/users/"+alice.ID.String()+"/suspension", mod.Token, map[string]any{"reason": "appeal"})
+	}
+}
+
+// TestBlockListings checks that blocks hide chirps both ways in every
+// listing, while mutes only hide them from the muter's timeline.
+func TestBlockListings(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	carol := a.signup("carol")
+	a.chirp(alice, "from alice")
+	a.chirp(bob, "from bob")
+	a.chirp(carol, "from carol")
+
+	authors := func(u testUser, path string) map[uuid.UUID]bool {
+		t.Helper()
+		seen := map[uuid.UUID]bool{}
+		for _, c := range decode[[]returnChirp](t, a.expect(200, "GET", path, u.Token, nil)) {
+			seen[c.UserID] = true
+		}
+		return seen
+	}
+
+	a.expect(204, "POST", "/api/users/"+bob.ID.String()+"/block", alice.Token, nil)
+	a.expect(204, "POST", "/api/users/"+carol.ID.String()+"/mute", alice.Token, nil)
+
+	if seen := authors(alice, "/api/chirps"); seen[bob.ID] || seen[carol.ID] || !seen[alice.ID] {
+		t.Errorf("Want alice's timeline without bob or carol, got %v", seen)
+	}
+	if seen := authors(bob, "/api/chirps"); seen[alice.ID] || !seen[carol.ID] {
+		t.Errorf("Want bob's timeline without alice, got %v", seen)
+	}
+	if seen := authors(carol, "/api/chirps"); !seen[alice.ID] {
+		t.Errorf("Want a mute not to hide alice from carol, got %v", seen)
+	}
+	if seen := authors(bob, "/api/chirps?author_id="+alice.ID.String()); len(seen) != 0 {
+		t.Errorf("Want alice's chirps hidden from bob, got %v", seen)
+	}
+	if seen := authors(alice, "/api/chirps?author_id="+bob.ID.String()); len(seen) != 0 {
+		t.Errorf("Want bob's chirps hidden from alice, got %v", seen)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestBookmarks(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	c := a.chirp(alice, "worth keeping")
+	path := "/api/chirps/" + c.ID.String() + "/bookmark"
+
+	a.expect(401, "POST", path, "", nil)
+	a.expect(404, "POST", "/api/chirps/"+uuid.NewString()+"/bookmark", bob.Token, nil)
+	a.expect(204, "POST", path, bob.Token, nil)
+	// Bookmarking twice is a no-op.
+	a.expect(204, "POST", path, bob.Token, nil)
+
+	chirps := decode[[]returnChirp](t, a.expect(200, "GET", "/api/bookmarks", bob.Token, nil))
+	if len(chirps) != 1 || chirps[0].ID != c.ID || chirps[0].Bookmarked == nil || !*chirps[0].Bookmarked {
+		t.Errorf("Want the bookmarked chirp, got %+v", chirps)
+	}
+	chirps = decode[[]returnChirp](t, a.expect(200, "GET", "/api/bookmarks", alice.Token, nil))
+	if len(chirps) != 0 {
+		t.Errorf("Want no bookmarks for alice, got %+v", chirps)
+	}
+
+	a.expect(204, "DELETE", path, bob.Token, nil)
+	chirps = decode[[]returnChirp](t, a.expect(200, "GET", "/api/bookmarks", bob.Token, nil))
+	if len(chirps) != 0 {
+		t.Errorf("Want the bookmark removed, got %+v", chirps)
+	}
+	a.expect(400, "GET", "/api/bookmarks?limit=x", bob.Token, nil)
+}
+
+func TestBookmarkedFlag(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	c := a.chirp(alice, "worth keeping")
+	a.chirp(alice, "not so much")
+	a.expect(204, "POST", "/api/chirps/"+c.ID.String()+"/bookmark", bob.Token, nil)
+
+	got := decode[returnChirp](t, a.expect(200, "GET", "/api/chirps/"+c.ID.String(), bob.Token, nil))
+	if got.Bookmarked == nil || !*got.Bookmarked {
+		t.Errorf("Want the chirp bookmarked for bob, got %+v", got)
+	}
+	got = decode[returnChirp](t, a.expect(200, "GET", "/api/chirps/"+c.ID.String(), alice.Token, nil))
+	if got.Bookmarked == nil || *got.Bookmarked {
+		t.Errorf("Want the chirp not bookmarked for alice, got %+v", got)
+	}
+	got = decode[returnChirp](t, a.expect(200, "GET", "/api/chirps/"+c.ID.String(), "", nil))
+	if got.Bookmarked != nil {
+		t.Errorf("Want no bookmarked flag without a token, got %+v", got)
+	}
+
+	chirps := decode[[]returnChirp](t, a.expect(200, "GET", "/api/chirps", bob.Token, nil))
+	for _, chirp := range chirps {
+		if chirp.Bookmarked == nil || *chirp.Bookmarked != (chirp.ID == c.ID) {
+			t.Errorf("Want only the bookmarked chirp flagged, got %+v", chirps)
+		}
+	}
+}
+
+func TestBookmarksPagination(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	for _, body := range []string{"one", "two", "three"} {
+		c := a.chirp(alice, body)
+		a.expect(204, "POST", "/api/chirps/"+c.ID.String()+"/bookmark", bob.Token, nil)
+	}
+
+	first := decode[[]returnChirp](t, a.expect(200, "GET", "/api/bookmarks?limit=2", bob.Token, nil))
+	rest := decode[[]returnChirp](t, a.expect(200, "GET", "/api/bookmarks?limit=2&offset=2", bob.Token, nil))
+	if len(first) != 2 || len(rest) != 1 {
+		t.Fatalf("Want pages of 2 and 1, got %+v and %+v", first, rest)
+	}
+	seen := map[uuid.UUID]bool{}
+	for _, c := range append(first, rest...) {
+		seen[c.ID] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("Want every bookmark once across the pages, got %+v and %+v", first, rest)
+	}
+}
+
+// TestBookmarkDeletedChirp checks that a bookmark goes with its chirp.
+func TestBookmarkDeletedChirp(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	c := a.chirp(alice, "here today")
+	a.expect(204, "POST", "/api/chirps/"+c.ID.String()+"/bookmark", bob.Token, nil)
+
+	a.expect(204, "DELETE", "/api/chirps/"+c.ID.String(), alice.Token, nil)
+	chirps := decode[[]returnChirp](t, a.expect(200, "GET", "/api/bookmarks", bob.Token, nil))
+	if len(chirps) != 0 {
+		t.Errorf("Want the bookmark gone with the chirp, got %+v", chirps)
+	}
+	ids, err := a.store.GetBookmarkedChirpIDs(context.Background(), bob.ID)
+	if err != nil || len(ids) != 0 {
+		t.Errorf("Want no bookmark rows left, got %v, %v", ids, err)
+	}
+}
@@ -1,10 +1,15 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,6 +18,8 @@ import (
 	"github.com/joshckidd/chirpy/internal/database"
 )
 
+const polkaSignatureTolerance = 5 * time.Minute
+
 type returnUserRow struct {
 	ID           uuid.UUID `json:"id"`
 	CreatedAt    time.Time `json:"created_at"`
@@ -23,10 +30,14 @@ type returnUserRow struct {
 	IsChirpyRed  bool      `json:"is_chirpy_red"`
 }
 
-func readinessEndpoint(w http.ResponseWriter, _ *http.Request) {
-	w.Header().Add("Content-Type", "text/plain; charset=utf-8")
-	w.WriteHeader(200)
-	w.Write([]byte("OK"))
+type returnChirp struct {
+	ID         uuid.UUID `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Body       string    `json:"body"`
+	UserID     uuid.UUID `json:"user_id"`
+	Bookmarked *bool     `json:"bookmarked,omitempty"`
+	Pinned     bool      `json:"pinned,omitempty"`
 }
 
 func (cfg *apiConfig) middlewareMetricsInc(next http.Handler) http.Handler {
@@ -43,8 +54,20 @@ func (cfg *apiConfig) returnMetrics(w http.ResponseWriter, _ *http.Request) {
   <body>
     <h1>Welcome, Chirpy Admin</h1>
     <p>Chirpy has been visited %d times!</p>
+    <ul>
+      <li>Signups: %.0f</li>
+      <li>Logins: %.0f successful, %.0f failed</li>
+      <li>Chirps created: %.0f</li>
+    </ul>
+    <p>Full metrics are served at /metrics on the metrics address.</p>
   </body>
-</html>`, cfg.fileserverHits.Load())))
+</html>`,
+		cfg.fileserverHits.Load(),
+		counterValue(cfg.metrics.signups),
+		counterValue(cfg.metrics.logins.WithLabelValues("success")),
+		counterValue(cfg.metrics.logins.WithLabelValues("failure")),
+		counterValue(cfg.metrics.chirpsCreated),
+	)))
 }
 
 func (cfg *apiConfig) resetMetrics(w http.ResponseWriter, r *http.Request) {
@@ -58,6 +81,10 @@ func (cfg *apiConfig) resetMetrics(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("OK"))
 	cfg.fileserverHits.Store(0)
 	cfg.db.ResetUsers(r.Context())
+	cfg.audit(r, auditRecord{
+		Action:  "admin.reset",
+		ActorID: actorID(r),
+	})
 }
 
 func (cfg *apiConfig) postUser(w http.ResponseWriter, r *http.Request) {
@@ -99,13 +126,13 @@ func (cfg *apiConfig) postUser(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, 500, err.Error())
 		return
 	}
+	cfg.metrics.signups.Inc()
 
 	respondWithJSON(w, 201, returnUserRow{
-		ID:          user.ID,
-		CreatedAt:   user.CreatedAt,
-		UpdatedAt:   user.UpdatedAt,
-		Email:       user.Email,
-		IsChirpyRed: user.IsChirpyRed.Bool,
+		ID:        user.ID,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+		Email:     user.Email,
 	})
 }
 
@@ -120,12 +147,16 @@ func (cfg *apiConfig) postChirp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	id, err := cfg.validateToken(r, tokenString)
 	if err != nil {
 		respondWithError(w, 401, err.Error())
 		return
 	}
 
+	if !cfg.checkCanWrite(w, r, id) {
+		return
+	}
+
 	decoder := json.NewDecoder(r.Body)
 	params := chirpParam{}
 	err = decoder.Decode(&params)
@@ -134,28 +165,76 @@ func (cfg *apiConfig) postChirp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(params.Body) > 140 {
+	limits, err := cfg.getLimits(r.Context(), id)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	if len(params.Body) > limits.MaxChirpLength {
 		respondWithError(w, 400, "Chirp is too long")
 		return
 	}
 
+	if limits.ChirpsPerHour > 0 {
+		count, err := cfg.db.CountChirpsInLastHour(r.Context(), id)
+		if err != nil {
+			respondWithError(w, 500, err.Error())
+			return
+		}
+		if count >= int64(limits.ChirpsPerHour) {
+			respondWithError(w, 429, "Too many chirps, try again later")
+			return
+		}
+	}
+
 	createParams := database.CreateChirpParams{
 		Body:   cleanChirp(params.Body),
 		UserID: id,
 	}
 
-	chirp, err := cfg.db.CreateChirp(r.Context(), createParams)
+	tx, err := cfg.db.BeginTx(r.Context())
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	chirp, err := tx.CreateChirp(r.Context(), createParams)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	err = enqueueEvent(r.Context(), tx, "chirp.created", chirp.UserID, chirp)
 	if err != nil {
 		respondWithError(w, 500, err.Error())
 		return
 	}
 
-	respondWithJSON(w, 201, chirp)
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	cfg.metrics.chirpsCreated.Inc()
+
+	bookmarked := false
+	respondWithJSON(w, 201, returnChirp{
+		ID:         chirp.ID,
+		CreatedAt:  chirp.CreatedAt,
+		UpdatedAt:  chirp.UpdatedAt,
+		Body:       chirp.Body,
+		UserID:     chirp.UserID,
+		Bookmarked: &bookmarked,
+	})
 }
 
 func (cfg *apiConfig) getChirps(w http.ResponseWriter, r *http.Request) {
 	var chirps []database.Chirp
 
+	userID, ok := cfg.getOptionalUserID(r)
+
 	s := r.URL.Query().Get("sort")
 	a := r.URL.Query().Get("author_id")
 	if a != "" {
@@ -164,7 +243,10 @@ func (cfg *apiConfig) getChirps(w http.ResponseWriter, r *http.Request) {
 			respondWithError(w, 500, err.Error())
 			return
 		}
-		chirps, err = cfg.db.GetChirpsForUser(r.Context(), id)
+		chirps, err = cfg.db.GetChirpsForUser(r.Context(), database.GetChirpsForUserParams{
+			UserID:   id,
+			ViewerID: userID,
+		})
 		if err != nil {
 			respondWithError(w, 500, err.Error())
 			return
@@ -172,7 +254,7 @@ func (cfg *apiConfig) getChirps(w http.ResponseWriter, r *http.Request) {
 
 	} else {
 		var err error
-		chirps, err = cfg.db.GetAllChirps(r.Context())
+		chirps, err = cfg.db.GetAllChirps(r.Context(), userID)
 		if err != nil {
 			respondWithError(w, 500, err.Error())
 			return
@@ -183,7 +265,21 @@ func (cfg *apiConfig) getChirps(w http.ResponseWriter, r *http.Request) {
 		sort.Slice(chirps, func(i, j int) bool { return chirps[i].CreatedAt.After(chirps[j].CreatedAt) })
 	}
 
-	respondWithJSON(w, 200, chirps)
+	res, err := cfg.toReturnChirps(r.Context(), chirps, userID, ok)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	if a != "" && r.URL.Query().Get("pinned") == "true" {
+		res, err = cfg.pinnedFirst(r.Context(), res)
+		if err != nil {
+			respondWithError(w, 500, err.Error())
+			return
+		}
+	}
+
+	respondWithJSON(w, 200, res)
 }
 
 func (cfg *apiConfig) getChirp(w http.ResponseWriter, r *http.Request) {
@@ -198,7 +294,24 @@ func (cfg *apiConfig) getChirp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respondWithJSON(w, 200, chirp)
+	userID, ok := cfg.getOptionalUserID(r)
+	visible, err := cfg.canViewChirp(r.Context(), userID, chirp)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	if !visible {
+		respondWithError(w, 404, "Chirp not found")
+		return
+	}
+
+	res, err := cfg.toReturnChirps(r.Context(), []database.Chirp{chirp}, userID, ok)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 200, res[0])
 }
 
 func (cfg *apiConfig) userLogin(w http.ResponseWriter, r *http.Request) {
@@ -227,6 +340,11 @@ func (cfg *apiConfig) userLogin(w http.ResponseWriter, r *http.Request) {
 
 	user, err := cfg.db.GetUserWithEmail(r.Context(), params.Email)
 	if err != nil {
+		cfg.metrics.logins.WithLabelValues("failure").Inc()
+		cfg.auditAuth(r, auditRecord{
+			Action:   "auth.login_failed",
+			Metadata: map[string]string{"email": params.Email, "reason": "unknown_email"},
+		})
 		respondWithError(w, 500, err.Error())
 		return
 	}
@@ -238,6 +356,31 @@ func (cfg *apiConfig) userLogin(w http.ResponseWriter, r *http.Request) {
 
 	val, err := auth.CheckPasswordHash(params.Password, user.HashedPassword)
 	if val == true {
+		suspension, err := cfg.db.GetActiveSuspension(r.Context(), user.ID)
+		if err != nil {
+			respondWithError(w, 500, err.Error())
+			return
+		}
+		if suspension.String == "suspended" {
+			cfg.metrics.logins.WithLabelValues("failure").Inc()
+			cfg.auditAuth(r, auditRecord{
+				Action:     "auth.login_failed",
+				TargetType: "user",
+				TargetID:   user.ID.String(),
+				Metadata:   map[string]string{"email": params.Email, "reason": "suspended"},
+			})
+			respondWithError(w, 403, "Account suspended")
+			return
+		}
+
+		if user.DeletionScheduledAt.Valid {
+			err = cfg.db.CancelUserDeletion(r.Context(), user.ID)
+			if err != nil {
+				respondWithError(w, 500, err.Error())
+				return
+			}
+		}
+
 		randTok, _ := auth.MakeRefreshToken()
 		rt, err := cfg.db.CreateRefreshToken(r.Context(), database.CreateRefreshTokenParams{
 			UserID: user.ID,
@@ -247,6 +390,11 @@ func (cfg *apiConfig) userLogin(w http.ResponseWriter, r *http.Request) {
 			respondWithError(w, 500, err.Error())
 			return
 		}
+		isRed, err := cfg.db.IsChirpyRed(r.Context(), user.ID)
+		if err != nil {
+			respondWithError(w, 500, err.Error())
+			return
+		}
 		userResp := returnUserRow{
 			ID:           user.ID,
 			CreatedAt:    user.CreatedAt,
@@ -254,11 +402,25 @@ func (cfg *apiConfig) userLogin(w http.ResponseWriter, r *http.Request) {
 			Email:        user.Email,
 			Token:        tok,
 			RefreshToken: rt.Token,
-			IsChirpyRed:  user.IsChirpyRed.Bool,
+			IsChirpyRed:  isRed,
 		}
+		cfg.metrics.logins.WithLabelValues("success").Inc()
+		cfg.auditAuth(r, auditRecord{
+			Action:     "auth.login",
+			ActorID:    user.ID,
+			TargetType: "user",
+			TargetID:   user.ID.String(),
+		})
 		respondWithJSON(w, 200, userResp)
 		return
 	}
+	cfg.metrics.logins.WithLabelValues("failure").Inc()
+	cfg.auditAuth(r, auditRecord{
+		Action:     "auth.login_failed",
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+		Metadata:   map[string]string{"email": params.Email, "reason": "wrong_password"},
+	})
 	respondWithError(w, 401, "Incorrect email or password")
 }
 
@@ -279,12 +441,28 @@ func (cfg *apiConfig) refreshJWT(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	suspension, err := cfg.db.GetActiveSuspension(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	if suspension.String == "suspended" {
+		respondWithError(w, 403, "Account suspended")
+		return
+	}
+
 	accessToken, err := auth.MakeJWT(userID, cfg.tokenSecret, time.Hour)
 	if err != nil {
 		respondWithError(w, 500, err.Error())
 		return
 	}
 
+	cfg.auditAuth(r, auditRecord{
+		Action:     "auth.refresh",
+		ActorID:    userID,
+		TargetType: "user",
+		TargetID:   userID.String(),
+	})
 	respondWithJSON(w, 200, returnUserRow{Token: accessToken})
 }
 
@@ -295,12 +473,23 @@ func (cfg *apiConfig) revokeToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Tokens that are already revoked or expired have no owner to record.
+	userID, lookupErr := cfg.db.GetUserFromRefreshToken(r.Context(), tok)
+
 	err = cfg.db.RevokeRefreshToken(r.Context(), tok)
 	if err != nil {
 		respondWithError(w, 500, err.Error())
 		return
 	}
 
+	if lookupErr == nil {
+		cfg.auditAuth(r, auditRecord{
+			Action:     "auth.revoke",
+			ActorID:    userID,
+			TargetType: "user",
+			TargetID:   userID.String(),
+		})
+	}
 	respondWithJSON(w, 204, nil)
 }
 
@@ -310,18 +499,30 @@ func (cfg *apiConfig) putUser(w http.ResponseWriter, r *http.Request) {
 		Password string `json:"password"`
 	}
 
+	type returnUserRow struct {
+		ID          uuid.UUID `json:"id"`
+		CreatedAt   time.Time `json:"created_at"`
+		UpdatedAt   time.Time `json:"updated_at"`
+		Email       string    `json:"email"`
+		IsChirpyRed bool      `json:"is_chirpy_red"`
+	}
+
 	tokenString, err := auth.GetBearerToken(r.Header)
 	if err != nil {
 		respondWithError(w, 401, err.Error())
 		return
 	}
 
-	id, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	id, err := cfg.validateToken(r, tokenString)
 	if err != nil {
 		respondWithError(w, 401, err.Error())
 		return
 	}
 
+	if !cfg.checkCanWrite(w, r, id) {
+		return
+	}
+
 	decoder := json.NewDecoder(r.Body)
 	inParams := userParam{}
 
@@ -343,13 +544,47 @@ func (cfg *apiConfig) putUser(w http.ResponseWriter, r *http.Request) {
 		ID:             id,
 	}
 
+	oldUser, err := cfg.db.GetUser(r.Context(), id)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
 	user, err := cfg.db.UpdateUser(r.Context(), params)
 	if err != nil {
 		respondWithError(w, 401, err.Error())
 		return
 	}
 
-	respondWithJSON(w, 200, user)
+	cfg.audit(r, auditRecord{
+		Action:     "user.password_changed",
+		ActorID:    id,
+		TargetType: "user",
+		TargetID:   id.String(),
+	})
+	if oldUser.Email != user.Email {
+		cfg.audit(r, auditRecord{
+			Action:     "user.email_changed",
+			ActorID:    id,
+			TargetType: "user",
+			TargetID:   id.String(),
+			Metadata:   map[string]string{"old_email": oldUser.Email, "new_email": user.Email},
+		})
+	}
+
+	isRed, err := cfg.db.IsChirpyRed(r.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 200, returnUserRow{
+		ID:          user.ID,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+		Email:       user.Email,
+		IsChirpyRed: isRed,
+	})
 }
 
 func (cfg *apiConfig) deleteChirp(w http.ResponseWriter, r *http.Request) {
@@ -359,12 +594,16 @@ func (cfg *apiConfig) deleteChirp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	id, err := cfg.validateToken(r, tokenString)
 	if err != nil {
 		respondWithError(w, 401, err.Error())
 		return
 	}
 
+	if !cfg.checkCanWrite(w, r, id) {
+		return
+	}
+
 	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
 	if err != nil {
 		respondWithError(w, 500, err.Error())
@@ -381,61 +620,140 @@ func (cfg *apiConfig) deleteChirp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = cfg.db.DeleteChirp(r.Context(), chirpID)
+	tx, err := cfg.db.BeginTx(r.Context())
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	err = deleteChirpWithEvent(r.Context(), tx, c)
 	if err != nil {
 		respondWithError(w, 404, err.Error())
 		return
 	}
 
+	err = appendAudit(r.Context(), tx, r, auditRecord{
+		Action:     "chirp.deleted",
+		ActorID:    id,
+		TargetType: "chirp",
+		TargetID:   c.ID.String(),
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
 	respondWithJSON(w, 204, nil)
 }
 
-func (cfg *apiConfig) userRed(w http.ResponseWriter, r *http.Request) {
-	type dataParams struct {
-		UserID uuid.UUID `json:"user_id"`
+// deleteChirpWithEvent deletes the chirp and queues its chirp.deleted
+// event. q should belong to a transaction so the two happen together.
+func deleteChirpWithEvent(ctx context.Context, q database.Querier, c database.Chirp) error {
+	err := q.DeleteChirp(ctx, c.ID)
+	if err != nil {
+		return err
 	}
 
+	return enqueueEvent(ctx, q, "chirp.deleted", c.UserID, map[string]uuid.UUID{
+		"id":      c.ID,
+		"user_id": c.UserID,
+	})
+}
+
+func (cfg *apiConfig) userRed(w http.ResponseWriter, r *http.Request) {
 	type redParams struct {
-		Event string     `json:"event"`
-		Data  dataParams `json:"data"`
+		ID    string `json:"id"`
+		Event string `json:"event"`
 	}
 
-	apiKey, err := auth.GetAPIKey(r.Header)
-	if err != nil || apiKey != cfg.polkaKey {
-		respondWithError(w, 401, "Invalid API key")
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		respondWithError(w, 400, "Invalid request")
+		return
+	}
+
+	err = auth.ValidateWebhookSignature(
+		r.Header.Get("X-Polka-Timestamp"),
+		r.Header.Get("X-Polka-Signature"),
+		body,
+		cfg.polkaSecrets,
+		polkaSignatureTolerance,
+	)
+	if err != nil {
+		cfg.metrics.webhookEvents.WithLabelValues("polka", "invalid_signature").Inc()
+		respondWithError(w, 401, err.Error())
 		return
 	}
 
-	decoder := json.NewDecoder(r.Body)
 	inParams := redParams{}
 
-	err = decoder.Decode(&inParams)
+	err = json.Unmarshal(body, &inParams)
 	if err != nil {
 		respondWithError(w, 500, "Invalid request")
 		return
 	}
-
-	if inParams.Event != "user.upgraded" {
-		respondWithJSON(w, 204, nil)
+	if inParams.ID == "" {
+		respondWithError(w, 400, "Missing event ID")
 		return
 	}
 
-	err = cfg.db.UpdateUserRed(r.Context(), inParams.Data.UserID)
+	err = cfg.recordWebhookEvent(r.Context(), database.CreateWebhookEventParams{
+		Provider:  "polka",
+		EventID:   inParams.ID,
+		EventType: inParams.Event,
+		Payload:   body,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// Polka redelivered an event we already have. Only a failed one, or
+		// one left received before events were stored with their changes,
+		// is worth another try; anything else is acknowledged as is.
+		var event database.WebhookEvent
+		event, err = cfg.db.GetWebhookEventByEventID(r.Context(), database.GetWebhookEventByEventIDParams{
+			Provider: "polka",
+			EventID:  inParams.ID,
+		})
+		if err != nil {
+			respondWithError(w, 500, err.Error())
+			return
+		}
+		if !retryableWebhookEvent(event) {
+			cfg.metrics.webhookEvents.WithLabelValues("polka", "duplicate").Inc()
+			respondWithJSON(w, 204, nil)
+			return
+		}
+		err = cfg.processWebhookEvent(r.Context(), event)
+	}
 	if err != nil {
-		respondWithError(w, 404, err.Error())
+		cfg.metrics.webhookEvents.WithLabelValues("polka", "failed").Inc()
+		respondWithError(w, 500, err.Error())
 		return
 	}
 
+	cfg.metrics.webhookEvents.WithLabelValues("polka", "processed").Inc()
 	respondWithJSON(w, 204, nil)
 }
 
 func respondWithError(w http.ResponseWriter, code int, msg string) {
 	type returnError struct {
-		Error string `json:"error"`
+		Error     string `json:"error"`
+		RequestID string `json:"request_id,omitempty"`
 	}
 
+	if code >= 500 {
+		recordError(w, msg)
+	}
+
+	// The request ID lets a user's error report be matched to the logs.
 	respError := returnError{
-		Error: msg,
+		Error:     msg,
+		RequestID: w.Header().Get("X-Request-ID"),
 	}
 
 	dat, err := json.Marshal(respError)
@@ -461,6 +779,126 @@ func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	w.Write(val)
 }
 
+// validateToken validates the caller's access token and records who they
+// are for the request log, so it doesn't have to parse the token again.
+func (cfg *apiConfig) validateToken(r *http.Request, tokenString string) (uuid.UUID, error) {
+	id, err := auth.ValidateJWT(tokenString, cfg.tokenSecret)
+	if err == nil {
+		setUserID(r, id)
+	}
+	return id, err
+}
+
+// getOptionalUserID returns the caller's user ID for endpoints that also
+// serve anonymous requests. A missing or invalid token is not an error.
+func (cfg *apiConfig) getOptionalUserID(r *http.Request) (uuid.UUID, bool) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		return uuid.UUID{}, false
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		return uuid.UUID{}, false
+	}
+
+	return id, true
+}
+
+// canViewChirp applies the same block and protected-account rules as the
+// chirp listings to a single chirp. Anonymous callers pass uuid.Nil.
+func (cfg *apiConfig) canViewChirp(ctx context.Context, viewerID uuid.UUID, c database.Chirp) (bool, error) {
+	blocked, err := cfg.db.IsBlocked(ctx, database.IsBlockedParams{
+		UserID:      viewerID,
+		OtherUserID: c.UserID,
+	})
+	if err != nil || blocked {
+		return false, err
+	}
+
+	return cfg.db.CanViewUser(ctx, database.CanViewUserParams{
+		ViewerID: viewerID,
+		UserID:   c.UserID,
+	})
+}
+
+func (cfg *apiConfig) toReturnChirps(ctx context.Context, chirps []database.Chirp, userID uuid.UUID, authenticated bool) ([]returnChirp, error) {
+	bookmarks := map[uuid.UUID]bool{}
+	if authenticated {
+		ids, err := cfg.db.GetBookmarkedChirpIDs(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			bookmarks[id] = true
+		}
+	}
+
+	res := make([]returnChirp, len(chirps))
+	for i, c := range chirps {
+		res[i] = returnChirp{
+			ID:        c.ID,
+			CreatedAt: c.CreatedAt,
+			UpdatedAt: c.UpdatedAt,
+			Body:      c.Body,
+			UserID:    c.UserID,
+		}
+		if authenticated {
+			bookmarked := bookmarks[c.ID]
+			res[i].Bookmarked = &bookmarked
+		}
+	}
+
+	return res, nil
+}
+
+// pinnedFirst moves the author's pinned chirps to the front of an
+// author-filtered listing, keeping the existing order otherwise.
+func (cfg *apiConfig) pinnedFirst(ctx context.Context, chirps []returnChirp) ([]returnChirp, error) {
+	if len(chirps) == 0 {
+		return chirps, nil
+	}
+
+	ids, err := cfg.db.GetPinnedChirpIDs(ctx, chirps[0].UserID)
+	if err != nil {
+		return nil, err
+	}
+	pinned := map[uuid.UUID]bool{}
+	for _, id := range ids {
+		pinned[id] = true
+	}
+
+	for i := range chirps {
+		chirps[i].Pinned = pinned[chirps[i].ID]
+	}
+	sort.SliceStable(chirps, func(i, j int) bool { return chirps[i].Pinned && !chirps[j].Pinned })
+
+	return chirps, nil
+}
+
+func getPagination(r *http.Request) (int32, int32, error) {
+	limit := 20
+	offset := 0
+
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 || n > 100 {
+			return 0, 0, errors.New("limit must be between 1 and 100")
+		}
+		limit = n
+	}
+
+	if o := r.URL.Query().Get("offset"); o != "" {
+		n, err := strconv.Atoi(o)
+		if err != nil || n < 0 {
+			return 0, 0, errors.New("offset must be a non-negative integer")
+		}
+		offset = n
+	}
+
+	return int32(limit), int32(offset), nil
+}
+
 func cleanChirp(c string) string {
 	words := strings.Split(c, " ")
 
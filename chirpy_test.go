@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/auth"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func TestFileServer(t *testing.T) {
+	a := newTestAPI(t)
+
+	rec := a.expect(200, "GET", "/app/", "", nil)
+	if !strings.Contains(rec.Body.String(), "Welcome to Chirpy") {
+		t.Errorf("Want the index page, got %q", rec.Body.String())
+	}
+	if a.cfg.fileserverHits.Load() != 1 {
+		t.Errorf("Want 1 hit, got %d", a.cfg.fileserverHits.Load())
+	}
+}
+
+func TestAdminMetrics(t *testing.T) {
+	a := newTestAPI(t)
+	admin := a.signup("admin")
+	a.setRole(admin, roleAdmin)
+	user := a.signup("user")
+
+	a.expect(200, "GET", "/app/", "", nil)
+	a.expect(403, "GET", "/admin/metrics", user.Token, nil)
+	a.expect(401, "GET", "/admin/metrics", "", nil)
+
+	rec := a.expect(200, "GET", "/admin/metrics", admin.Token, nil)
+	body := rec.Body.String()
+	if !strings.Contains(body, "Chirpy has been visited 1 times!") {
+		t.Errorf("Want the hit count, got %q", body)
+	}
+	if !strings.Contains(body, "Signups: 2") {
+		t.Errorf("Want the signup count, got %q", body)
+	}
+}
+
+func TestReset(t *testing.T) {
+	a := newTestAPI(t)
+	admin := a.signup("admin")
+	a.setRole(admin, roleAdmin)
+	a.signup("user")
+
+	a.cfg.environment = "prod"
+	a.expect(403, "POST", "/admin/reset", admin.Token, nil)
+
+	a.cfg.environment = "dev"
+	a.expect(200, "POST", "/admin/reset", admin.Token, nil)
+	a.expect(500, "POST", "/api/login", "", map[string]string{"email": "user@example.com", "password": "hunter2"})
+}
+
+func TestCreateUser(t *testing.T) {
+	a := newTestAPI(t)
+
+	rec := a.expect(201, "POST", "/api/users", "", map[string]string{"email": "a@example.com", "password": "1234"})
+	res := decode[map[string]any](t, rec)
+	if res["email"] != "a@example.com" || res["is_chirpy_red"] != false {
+		t.Errorf("Want the new user, got %v", res)
+	}
+	if _, ok := res["password"]; ok {
+		t.Errorf("Want no password in the response, got %v", res)
+	}
+
+	a.expect(500, "POST", "/api/users", "", []byte("not json"))
+}
+
+func TestLogin(t *testing.T) {
+	a := newTestAPI(t)
+	u := a.signup("user")
+
+	if id, err := auth.ValidateJWT(u.Token, testTokenSecret); err != nil || id != u.ID {
+		t.Errorf("Want a token for %v, got %v, %v", u.ID, id, err)
+	}
+
+	a.expect(401, "POST", "/api/login", "", map[string]string{"email": u.Email, "password": "wrong"})
+	a.expect(500, "POST", "/api/login", "", map[string]string{"email": "nobody@example.com", "password": "hunter2"})
+	a.expectAudited("auth.login")
+	a.expectAudited("auth.login_failed")
+}
+
+func TestRefreshAndRevoke(t *testing.T) {
+	a := newTestAPI(t)
+	u := a.signup("user")
+
+	rec := a.expect(200, "POST", "/api/refresh", u.RefreshToken, nil)
+	res := decode[map[string]string](t, rec)
+	if id, err := auth.ValidateJWT(res["token"], testTokenSecret); err != nil || id != u.ID {
+		t.Errorf("Want a token for %v, got %v, %v", u.ID, id, err)
+	}
+
+	a.expect(401, "POST", "/api/refresh", "not-a-token", nil)
+	a.expect(500, "POST", "/api/refresh", "", nil)
+
+	a.expect(204, "POST", "/api/revoke", u.RefreshToken, nil)
+	a.expect(401, "POST", "/api/refresh", u.RefreshToken, nil)
+	a.expectAudited("auth.revoke")
+}
+
+func TestUpdateUser(t *testing.T) {
+	a := newTestAPI(t)
+	u := a.signup("user")
+
+	a.expect(401, "PUT", "/api/users", "", map[string]string{"email": "new@example.com", "password": "5678"})
+
+	rec := a.expect(200, "PUT", "/api/users", u.Token, map[string]string{"email": "new@example.com", "password": "5678"})
+	res := decode[map[string]any](t, rec)
+	if res["email"] != "new@example.com" {
+		t.Errorf("Want the new email, got %v", res)
+	}
+
+	a.expect(200, "POST", "/api/login", "", map[string]string{"email": "new@example.com", "password": "5678"})
+	a.expectAudited("user.email_changed")
+}
+
+func TestCreateChirp(t *testing.T) {
+	a := newTestAPI(t)
+	u := a.signup("user")
+
+	c := a.chirp(u, "I had something interesting for breakfast")
+	if c.UserID != u.ID || c.Bookmarked == nil || *c.Bookmarked {
+		t.Errorf("Want an unbookmarked chirp by %v, got %+v", u.ID, c)
+	}
+
+	c = a.chirp(u, "This is a kerfuffle opinion I need to share with the world")
+	if c.Body != "This is a **** opinion I need to share with the world" {
+		t.Errorf("Want profanity cleaned, got %q", c.Body)
+	}
+
+	a.expect(400, "POST", "/api/chirps", u.Token, map[string]string{"body": strings.Repeat("a", 141)})
+	a.expect(401, "POST", "/api/chirps", "", map[string]string{"body": "hi"})
+	a.expect(401, "POST", "/api/chirps", "not-a-token", map[string]string{"body": "hi"})
+}
+
+func TestChirpRateLimit(t *testing.T) {
+	a := newTestAPI(t)
+	u := a.signup("user")
+
+	limit := a.cfg.plans.For("free").ChirpsPerHour
+	for i := range limit {
+		a.chirp(u, "chirp "+strconv.Itoa(i))
+	}
+	a.expect(429, "POST", "/api/chirps", u.Token, map[string]string{"body": "one too many"})
+}
+
+func TestGetChirps(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+
+	first := a.chirp(alice, "first")
+	a.chirp(bob, "second")
+	a.store.SetClock(func() time.Time { return time.Now().Add(time.Minute) })
+	last := a.chirp(alice, "third")
+
+	chirps := decode[[]returnChirp](t, a.expect(200, "GET", "/api/chirps", "", nil))
+	if len(chirps) != 3 || chirps[0].ID != first.ID {
+		t.Errorf("Want 3 chirps oldest first, got %+v", chirps)
+	}
+	if chirps[0].Bookmarked != nil {
+		t.Errorf("Want no bookmarked flag for anonymous callers, got %+v", chirps[0])
+	}
+
+	chirps = decode[[]returnChirp](t, a.expect(200, "GET", "/api/chirps?author_id="+alice.ID.String()+"&sort=desc", bob.Token, nil))
+	if len(chirps) != 2 || chirps[0].ID != last.ID {
+		t.Errorf("Want alice's 2 chirps newest first, got %+v", chirps)
+	}
+	if chirps[0].Bookmarked == nil {
+		t.Errorf("Want a bookmarked flag for signed in callers, got %+v", chirps[0])
+	}
+
+	rec := a.expect(200, "GET", "/api/chirps/"+first.ID.String(), "", nil)
+	if c := decode[returnChirp](t, rec); c.Body != "first" {
+		t.Errorf("Want the first chirp, got %+v", c)
+	}
+	a.expect(404, "GET", "/api/chirps/"+uuid.NewString(), "", nil)
+}
+
+func TestDeleteChirp(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	c := a.chirp(alice, "hello")
+
+	a.expect(403, "DELETE", "/api/chirps/"+c.ID.String(), bob.Token, nil)
+	a.expect(404, "DELETE", "/api/chirps/"+uuid.NewString(), alice.Token, nil)
+	a.expect(204, "DELETE", "/api/chirps/"+c.ID.String(), alice.Token, nil)
+	a.expect(404, "GET", "/api/chirps/"+c.ID.String(), "", nil)
+}
+
+// polka sends body to the Polka webhook, signed with secret.
+func (a *testAPI) polka(body any, secret string) *httptest.ResponseRecorder {
+	a.t.Helper()
+
+	dat, err := json.Marshal(body)
+	if err != nil {
+		a.t.Fatalf("Error encoding webhook: %v", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req := httptest.NewRequest("POST", "/api/polka/webhooks", bytes.NewReader(dat))
+	req.Header.Set("X-Polka-Timestamp", timestamp)
+	req.Header.Set("X-Polka-Signature", auth.SignWebhook(timestamp, dat, secret))
+	return a.serve(req)
+}
+
+func TestPolkaWebhook(t *testing.T) {
+	a := newTestAPI(t)
+	u := a.signup("user")
+
+	event := map[string]any{
+		"id":    "evt_1",
+		"event": "user.upgraded",
+		"data":  map[string]any{"user_id": u.ID},
+	}
+	if rec := a.polka(event, testPolkaSecret); rec.Code != 204 {
+		t.Fatalf("Want 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec := a.expect(200, "POST", "/api/login", "", map[string]string{"email": u.Email, "password": "hunter2"})
+	if res := decode[returnUserRow](t, rec); !res.IsChirpyRed {
+		t.Errorf("Want the user upgraded to Chirpy Red")
+	}
+
+	// Polka redelivering an event is acknowledged without applying it again.
+	if rec := a.polka(event, testPolkaSecret); rec.Code != 204 {
+		t.Errorf("Want 204 for a duplicate event, got %d", rec.Code)
+	}
+	if rec := a.polka(map[string]any{"id": "evt_2", "event": "user.unknown"}, testPolkaSecret); rec.Code != 204 {
+		t.Errorf("Want 204 for an ignored event, got %d", rec.Code)
+	}
+	if rec := a.polka(map[string]any{"event": "user.upgraded"}, testPolkaSecret); rec.Code != 400 {
+		t.Errorf("Want 400 without an event ID, got %d", rec.Code)
+	}
+	if rec := a.polka(event, "wrong-secret"); rec.Code != 401 {
+		t.Errorf("Want 401 for a bad signature, got %d", rec.Code)
+	}
+}
+
+// TestPolkaWebhookUnsigned checks that requests without a valid, recent
+// signature are turned away before anything is recorded.
+func TestPolkaWebhookUnsigned(t *testing.T) {
+	a := newTestAPI(t)
+	u := a.signup("user")
+	dat, err := json.Marshal(map[string]any{"id": "evt_1", "event": "user.upgraded", "data": map[string]any{"user_id": u.ID}})
+	if err != nil {
+		t.Fatalf("Error encoding webhook: %v", err)
+	}
+	stale := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	tests := map[string]map[string]string{
+		"no headers":  {},
+		"old api key": {"Authorization": "ApiKey " + testPolkaSecret},
+		"stale": {
+			"X-Polka-Timestamp": stale,
+			"X-Polka-Signature": auth.SignWebhook(stale, dat, testPolkaSecret),
+		},
+	}
+	for name, headers := range tests {
+		req := httptest.NewRequest("POST", "/api/polka/webhooks", bytes.NewReader(dat))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		if rec := a.serve(req); rec.Code != 401 {
+			t.Errorf("%s: want 401, got %d", name, rec.Code)
+		}
+	}
+
+	events, err := a.store.GetWebhookEvents(context.Background(), database.GetWebhookEventsParams{Limit: 10})
+	if err != nil || len(events) != 0 {
+		t.Errorf("Want no events recorded, got %+v, %v", events, err)
+	}
+}
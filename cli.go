@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+const cliUsage = `usage: chirpy [flags] [command]
+
+With no command, chirpy runs the server.
+
+flags:
+  -config <file>                 TOML config file, also set by CONFIG_FILE
+  -addr <addr>                   address to listen on, also ADDR
+  -metrics-addr <addr>           internal address to serve /metrics on, also
+                                 METRICS_ADDR; metrics aren't served without it
+  -platform <dev|prod>           also PLATFORM
+  -fileserver-root <dir>         directory served under /app/, also FILESERVER_ROOT
+  -export-dir <dir>              also EXPORT_DIR
+  -entitlements-file <file>      also ENTITLEMENTS_FILE
+  -audit-retention-days <days>   also AUDIT_RETENTION_DAYS
+  -read-timeout, -read-header-timeout, -write-timeout, -idle-timeout,
+  -shutdown-delay, -shutdown-timeout <duration>
+                                 e.g. 30s, also READ_TIMEOUT and so on
+  -max-header-bytes <bytes>      also MAX_HEADER_BYTES
+  -tls-cert-file, -tls-key-file <file>
+                                 serve TLS, reloading the files when they
+                                 change; also TLS_CERT_FILE and TLS_KEY_FILE
+  -log-level <levels>            e.g. info,http=debug,worker=warn, also LOG_LEVEL
+  -auto-migrate <true|false>     apply pending migrations at startup, also AUTO_MIGRATE
+  -otlp-endpoint <url>           OpenTelemetry collector traces are sent to, e.g.
+                                 http://localhost:4318, also OTEL_EXPORTER_OTLP_ENDPOINT
+  -service-name <name>           service name traces are reported under, also
+                                 OTEL_SERVICE_NAME
+
+commands:
+  set-role <email> <role>  give an existing user the user, moderator or admin role
+  migrate up               apply pending migrations
+  migrate down             roll back the latest migration
+  migrate redo             roll back the latest migration and apply it again
+  migrate status           list migrations and when they were applied`
+
+// runCommand runs a one-off administrative command instead of the server.
+// It's how the first admin is created, since nobody can reach the admin
+// API before one exists.
+func runCommand(ctx context.Context, db *sql.DB, dialect database.Dialect, args []string) error {
+	switch args[0] {
+	case "migrate":
+		if len(args) != 2 {
+			return errors.New(cliUsage)
+		}
+		return runMigrate(ctx, db, dialect, args[1])
+	case "set-role":
+		if len(args) != 3 {
+			return errors.New(cliUsage)
+		}
+		email, role := args[1], args[2]
+		if !slices.Contains(roles, role) {
+			return fmt.Errorf("unknown role %q", role)
+		}
+
+		err := setRoleByEmail(ctx, database.NewStore(db, dialect, nil), email, role)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s is now %s\n", email, role)
+		return nil
+	default:
+		return errors.New(cliUsage)
+	}
+}
+
+// setRoleByEmail gives the user with email the role, recording it in the
+// audit log as done by the system, since the CLI has no logged-in actor.
+func setRoleByEmail(ctx context.Context, store database.Store, email, role string) error {
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	user, err := tx.GetUserWithEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no user with email %q", email)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.UpdateUserRoleByEmail(ctx, database.UpdateUserRoleByEmailParams{
+		Role:  role,
+		Email: email,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = appendAudit(ctx, tx, nil, auditRecord{
+		Action:     "admin.role_changed",
+		TargetType: "user",
+		TargetID:   user.ID.String(),
+		Metadata:   map[string]string{"role": role, "source": "cli"},
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
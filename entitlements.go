@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+	"github.com/joshckidd/chirpy/internal/entitlements"
+)
+
+// getLimits returns the user's effective limits: those of their current
+// plan with any per-user override applied on top.
+func (cfg *apiConfig) getLimits(ctx context.Context, userID uuid.UUID) (entitlements.Limits, error) {
+	plan := entitlements.PlanFree
+	sub, err := cfg.db.GetSubscription(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return entitlements.Limits{}, err
+	}
+	if err == nil && sub.Status != "expired" {
+		plan = sub.Plan
+	}
+
+	limits := cfg.plans.For(plan)
+
+	raw, err := cfg.db.GetEntitlementOverride(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return limits, nil
+	}
+	if err != nil {
+		return entitlements.Limits{}, err
+	}
+
+	override := entitlements.Override{}
+	err = json.Unmarshal(raw, &override)
+	if err != nil {
+		return entitlements.Limits{}, err
+	}
+
+	return limits.Apply(override), nil
+}
+
+func (cfg *apiConfig) getUserEntitlements(w http.ResponseWriter, r *http.Request) {
+	type returnEntitlements struct {
+		Limits   entitlements.Limits    `json:"limits"`
+		Override *entitlements.Override `json:"override"`
+	}
+
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	_, err = cfg.db.GetUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 404, "User not found")
+		return
+	}
+
+	limits, err := cfg.getLimits(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	res := returnEntitlements{Limits: limits}
+	raw, err := cfg.db.GetEntitlementOverride(r.Context(), userID)
+	if err == nil {
+		res.Override = &entitlements.Override{}
+		err = json.Unmarshal(raw, res.Override)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 200, res)
+}
+
+func (cfg *apiConfig) putUserEntitlements(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	override := entitlements.Override{}
+	err = decoder.Decode(&override)
+	if err != nil {
+		respondWithError(w, 400, "Invalid request")
+		return
+	}
+
+	_, err = cfg.db.GetUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 404, "User not found")
+		return
+	}
+
+	dat, err := json.Marshal(override)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	err = cfg.db.UpsertEntitlementOverride(r.Context(), database.UpsertEntitlementOverrideParams{
+		UserID:    userID,
+		Overrides: dat,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	cfg.audit(r, auditRecord{
+		Action:     "admin.entitlements_changed",
+		ActorID:    actorID(r),
+		TargetType: "user",
+		TargetID:   userID.String(),
+		Metadata:   override,
+	})
+	respondWithJSON(w, 200, override)
+}
+
+func (cfg *apiConfig) deleteUserEntitlements(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	err = cfg.db.DeleteEntitlementOverride(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	cfg.audit(r, auditRecord{
+		Action:     "admin.entitlements_reset",
+		ActorID:    actorID(r),
+		TargetType: "user",
+		TargetID:   userID.String(),
+	})
+
+	respondWithJSON(w, 204, nil)
+}
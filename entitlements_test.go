@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/entitlements"
+)
+
+func TestUserEntitlements(t *testing.T) {
+	type returnEntitlements struct {
+		Limits   entitlements.Limits    `json:"limits"`
+		Override *entitlements.Override `json:"override"`
+	}
+
+	a := newTestAPI(t)
+	admin := a.signup("admin")
+	a.setRole(admin, roleAdmin)
+	bob := a.signup("bob")
+	path := "/admin/users/" + bob.ID.String() + "/entitlements"
+
+	a.expect(403, "GET", path, bob.Token, nil)
+	a.expect(404, "GET", "/admin/users/"+uuid.NewString()+"/entitlements", admin.Token, nil)
+	res := decode[returnEntitlements](t, a.expect(200, "GET", path, admin.Token, nil))
+	if res.Override != nil || res.Limits != a.cfg.plans.For(entitlements.PlanFree) {
+		t.Errorf("Want the free plan's limits, got %+v", res)
+	}
+
+	a.expect(400, "PUT", path, admin.Token, map[string]any{"max_chirp_length": 200, "unknown": true})
+	a.expect(404, "PUT", "/admin/users/"+uuid.NewString()+"/entitlements", admin.Token, map[string]any{"max_chirp_length": 200})
+	a.expect(200, "PUT", path, admin.Token, map[string]any{"max_chirp_length": 200})
+
+	res = decode[returnEntitlements](t, a.expect(200, "GET", path, admin.Token, nil))
+	if res.Override == nil || res.Limits.MaxChirpLength != 200 {
+		t.Errorf("Want the override applied, got %+v", res)
+	}
+	a.chirp(bob, strings.Repeat("a", 200))
+
+	a.expect(204, "DELETE", path, admin.Token, nil)
+	a.expect(400, "POST", "/api/chirps", bob.Token, map[string]string{"body": strings.Repeat("a", 200)})
+	a.expectAudited("admin.entitlements_reset")
+}
+
+// TestPlanEntitlements checks that limits follow the user's subscription,
+// with an override applied on top of whichever plan that is.
+func TestPlanEntitlements(t *testing.T) {
+	a := newTestAPI(t)
+	admin := a.signup("admin")
+	a.setRole(admin, roleAdmin)
+	bob := a.signup("bob")
+	red := a.cfg.plans.For(entitlements.PlanRed)
+	long := strings.Repeat("a", red.MaxChirpLength)
+
+	a.expect(400, "POST", "/api/chirps", bob.Token, map[string]string{"body": long})
+	rec := a.polka(map[string]any{"id": "evt_1", "event": "user.upgraded", "data": map[string]any{"user_id": bob.ID}}, testPolkaSecret)
+	if rec.Code != 204 {
+		t.Fatalf("Want 204 upgrading bob, got %d", rec.Code)
+	}
+	a.chirp(bob, long)
+	a.expect(400, "POST", "/api/chirps", bob.Token, map[string]string{"body": long + "a"})
+
+	path := "/admin/users/" + bob.ID.String() + "/entitlements"
+	a.expect(200, "PUT", path, admin.Token, map[string]any{"max_pinned_chirps": 10})
+	limits, err := a.cfg.getLimits(context.Background(), bob.ID)
+	if err != nil || limits.MaxPinnedChirps != 10 || limits.MaxChirpLength != red.MaxChirpLength {
+		t.Errorf("Want the override on top of Red, got %+v, %v", limits, err)
+	}
+	a.expectAudited("admin.entitlements_changed")
+
+	rec = a.polka(map[string]any{"id": "evt_2", "event": "user.downgraded", "data": map[string]any{"user_id": bob.ID}}, testPolkaSecret)
+	if rec.Code != 204 {
+		t.Fatalf("Want 204 downgrading bob, got %d", rec.Code)
+	}
+	a.expect(400, "POST", "/api/chirps", bob.Token, map[string]string{"body": long})
+	limits, err = a.cfg.getLimits(context.Background(), bob.ID)
+	if err != nil || limits.MaxPinnedChirps != 10 || limits.MaxChirpLength != a.cfg.plans.For(entitlements.PlanFree).MaxChirpLength {
+		t.Errorf("Want the override on top of the free plan, got %+v, %v", limits, err)
+	}
+}
@@ -0,0 +1,573 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/auth"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+const (
+	exportBatchSize = 500
+	exportURLTTL    = 15 * time.Minute
+	// exportTimeout bounds building one export. It is shorter than the 15
+	// minute lease in ClaimAbandonedDataExports, and resumeDataExports
+	// claims each export just before building it, so an export is never
+	// built twice at once.
+	exportTimeout = 10 * time.Minute
+	// exportResumeBatchSize is how many abandoned exports one run of the
+	// worker builds.
+	exportResumeBatchSize = 5
+)
+
+type returnDataExport struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	Status    string     `json:"status"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+func (cfg *apiConfig) postDataExport(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	export, err := cfg.db.CreateDataExport(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, 409, "A data export is already being built")
+		return
+	}
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	cfg.background.Add(1)
+	go func() {
+		defer cfg.background.Done()
+		// Outlive the request but keep its values, such as the request ID.
+		cfg.buildDataExport(context.WithoutCancel(r.Context()), export)
+	}()
+
+	respondWithJSON(w, 202, returnDataExport{
+		ID:        export.ID,
+		CreatedAt: export.CreatedAt,
+		Status:    export.Status,
+	})
+}
+
+func (cfg *apiConfig) getDataExport(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	exportID, err := uuid.Parse(r.PathValue("exportID"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	export, err := cfg.db.GetDataExport(r.Context(), exportID)
+	if err != nil || export.UserID != id {
+		respondWithError(w, 404, "Export not found")
+		return
+	}
+
+	if export.Status != "ready" {
+		code := 200
+		if export.Status == "pending" {
+			code = 202
+		}
+		respondWithJSON(w, code, returnDataExport{
+			ID:        export.ID,
+			CreatedAt: export.CreatedAt,
+			Status:    export.Status,
+		})
+		return
+	}
+
+	if export.ExpiresAt.Time.Before(time.Now()) {
+		respondWithError(w, 410, "Export expired")
+		return
+	}
+
+	downloadPath := fmt.Sprintf("/api/exports/%s/download", export.ID)
+	http.Redirect(w, r, auth.SignURL(downloadPath, time.Now().Add(exportURLTTL), cfg.exportSecret), http.StatusFound)
+}
+
+func (cfg *apiConfig) downloadDataExport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	err := auth.ValidateSignedURL(r.URL.Path, q.Get("expires"), q.Get("signature"), cfg.exportSecret)
+	if err != nil {
+		respondWithError(w, 403, err.Error())
+		return
+	}
+
+	exportID, err := uuid.Parse(r.PathValue("exportID"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	export, err := cfg.db.GetDataExport(r.Context(), exportID)
+	if err != nil || export.Status != "ready" || !export.FilePath.Valid {
+		respondWithError(w, 404, "Export not found")
+		return
+	}
+
+	f, err := os.Open(export.FilePath.String)
+	if err != nil {
+		respondWithError(w, 404, "Export not found")
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="chirpy-export-%s.zip"`, export.ID))
+	http.ServeContent(w, r, "", export.UpdatedAt, f)
+}
+
+// buildDataExport runs in the background after postDataExport returns, and
+// notifies the user once the export is ready or has failed. An export whose server stops
+// before it is built is picked up by resumeDataExports.
+func (cfg *apiConfig) buildDataExport(ctx context.Context, export database.DataExport) {
+	ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+
+	path := filepath.Join(cfg.exportDir, export.ID.String()+".zip")
+	logger := cfg.logs.Logger("exports").With(
+		"export_id", export.ID,
+		"request_id", requestID(ctx),
+	)
+
+	err := cfg.writeDataExport(ctx, export.UserID, path)
+	if err != nil {
+		logger.Error("failed to write data export", "error", err)
+		os.Remove(path)
+		err = cfg.failDataExport(context.WithoutCancel(ctx), export)
+		if err != nil {
+			logger.Error("failed to mark data export failed", "error", err)
+		}
+		return
+	}
+
+	err = cfg.completeDataExport(ctx, export, path)
+	if err != nil {
+		logger.Error("failed to mark data export complete", "error", err)
+	}
+}
+
+// completeDataExport marks the export ready and tells the user, together.
+func (cfg *apiConfig) completeDataExport(ctx context.Context, export database.DataExport, path string) error {
+	tx, err := cfg.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.CompleteDataExport(ctx, database.CompleteDataExportParams{
+		FilePath: sql.NullString{String: path, Valid: true},
+		ID:       export.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = tx.CreateNotification(ctx, database.CreateNotificationParams{
+		UserID: export.UserID,
+		Kind:   "data_export",
+		Body:   fmt.Sprintf("Your data export is ready to download from /api/users/me/export/%s.", export.ID),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// failDataExport marks the export failed and tells the user, together, so
+// they know to ask for another.
+func (cfg *apiConfig) failDataExport(ctx context.Context, export database.DataExport) error {
+	tx, err := cfg.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.FailDataExport(ctx, export.ID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.CreateNotification(ctx, database.CreateNotificationParams{
+		UserID: export.UserID,
+		Kind:   "data_export",
+		Body:   "Your data export could not be built. Please request a new one.",
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// resumeDataExports builds exports left pending by a server that stopped
+// while building them. Exports are claimed one at a time, as the lease on
+// a claimed export only covers one build.
+func (cfg *apiConfig) resumeDataExports(ctx context.Context) error {
+	for range exportResumeBatchSize {
+		exports, err := cfg.db.ClaimAbandonedDataExports(ctx, 1)
+		if err != nil {
+			return err
+		}
+		if len(exports) == 0 {
+			return nil
+		}
+		cfg.buildDataExport(ctx, exports[0])
+	}
+
+	return nil
+}
+
+func (cfg *apiConfig) writeDataExport(ctx context.Context, userID uuid.UUID, path string) error {
+	type profile struct {
+		ID                  uuid.UUID  `json:"id"`
+		CreatedAt           time.Time  `json:"created_at"`
+		UpdatedAt           time.Time  `json:"updated_at"`
+		Email               string     `json:"email"`
+		IsChirpyRed         bool       `json:"is_chirpy_red"`
+		Protected           bool       `json:"protected"`
+		DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+	}
+
+	err := os.MkdirAll(cfg.exportDir, 0o700)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+
+	user, err := cfg.db.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	isRed, err := cfg.db.IsChirpyRed(ctx, userID)
+	if err != nil {
+		return err
+	}
+	p := profile{
+		ID:          user.ID,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+		Email:       user.Email,
+		IsChirpyRed: isRed,
+		Protected:   user.Protected,
+	}
+	if user.DeletionScheduledAt.Valid {
+		p.DeletionScheduledAt = &user.DeletionScheduledAt.Time
+	}
+	err = writeZipJSON(zw, "profile.json", p)
+	if err != nil {
+		return err
+	}
+
+	fw, err := zw.Create("chirps.json")
+	if err != nil {
+		return err
+	}
+	err = streamJSONArray(fw, cfg.exportChirpPager(ctx, userID))
+	if err != nil {
+		return err
+	}
+
+	fw, err = zw.Create("chirps.html")
+	if err != nil {
+		return err
+	}
+	err = writeChirpsHTML(fw, user.Email, cfg.exportChirpPager(ctx, userID))
+	if err != nil {
+		return err
+	}
+
+	fw, err = zw.Create("bookmarks.json")
+	if err != nil {
+		return err
+	}
+	err = streamJSONArray(fw, cfg.exportBookmarkPager(ctx, userID))
+	if err != nil {
+		return err
+	}
+
+	pinned, err := cfg.db.GetPinnedChirpIDs(ctx, userID)
+	if err != nil {
+		return err
+	}
+	err = writeZipJSON(zw, "pinned_chirps.json", pinned)
+	if err != nil {
+		return err
+	}
+
+	follows, err := cfg.db.GetFollowsForExport(ctx, userID)
+	if err != nil {
+		return err
+	}
+	err = writeZipJSON(zw, "follows.json", follows)
+	if err != nil {
+		return err
+	}
+
+	fw, err = zw.Create("blocks.json")
+	if err != nil {
+		return err
+	}
+	var blockOffset int32
+	err = streamJSONArray(fw, func() ([]database.Block, error) {
+		blocks, err := cfg.db.GetBlocks(ctx, database.GetBlocksParams{
+			BlockerID: userID,
+			Limit:     exportBatchSize,
+			Offset:    blockOffset,
+		})
+		blockOffset += int32(len(blocks))
+		return blocks, err
+	})
+	if err != nil {
+		return err
+	}
+
+	fw, err = zw.Create("mutes.json")
+	if err != nil {
+		return err
+	}
+	var muteOffset int32
+	err = streamJSONArray(fw, func() ([]database.Mute, error) {
+		mutes, err := cfg.db.GetMutes(ctx, database.GetMutesParams{
+			MuterID: userID,
+			Limit:   exportBatchSize,
+			Offset:  muteOffset,
+		})
+		muteOffset += int32(len(mutes))
+		return mutes, err
+	})
+	if err != nil {
+		return err
+	}
+
+	subscription, err := cfg.db.GetSubscription(ctx, userID)
+	if err == nil {
+		err = writeZipJSON(zw, "subscription.json", subscription)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	fw, err = zw.Create("notifications.json")
+	if err != nil {
+		return err
+	}
+	var notificationOffset int32
+	err = streamJSONArray(fw, func() ([]database.Notification, error) {
+		notifications, err := cfg.db.GetNotificationsForUser(ctx, database.GetNotificationsForUserParams{
+			UserID: userID,
+			Limit:  exportBatchSize,
+			Offset: notificationOffset,
+		})
+		notificationOffset += int32(len(notifications))
+		return notifications, err
+	})
+	if err != nil {
+		return err
+	}
+
+	sessions, err := cfg.db.GetRefreshTokensForExport(ctx, userID)
+	if err != nil {
+		return err
+	}
+	err = writeZipJSON(zw, "sessions.json", sessions)
+	if err != nil {
+		return err
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func (cfg *apiConfig) exportChirpPager(ctx context.Context, userID uuid.UUID) func() ([]database.Chirp, error) {
+	var afterCreatedAt time.Time
+	var afterID uuid.UUID
+
+	return func() ([]database.Chirp, error) {
+		chirps, err := cfg.db.GetChirpsForExport(ctx, database.GetChirpsForExportParams{
+			UserID:         userID,
+			AfterCreatedAt: afterCreatedAt,
+			AfterID:        afterID,
+			BatchSize:      exportBatchSize,
+		})
+		if err != nil || len(chirps) == 0 {
+			return chirps, err
+		}
+		last := chirps[len(chirps)-1]
+		afterCreatedAt, afterID = last.CreatedAt, last.ID
+		return chirps, nil
+	}
+}
+
+func (cfg *apiConfig) exportBookmarkPager(ctx context.Context, userID uuid.UUID) func() ([]database.Bookmark, error) {
+	var afterCreatedAt time.Time
+	var afterChirpID uuid.UUID
+
+	return func() ([]database.Bookmark, error) {
+		bookmarks, err := cfg.db.GetBookmarksForExport(ctx, database.GetBookmarksForExportParams{
+			UserID:         userID,
+			AfterCreatedAt: afterCreatedAt,
+			AfterChirpID:   afterChirpID,
+			BatchSize:      exportBatchSize,
+		})
+		if err != nil || len(bookmarks) == 0 {
+			return bookmarks, err
+		}
+		last := bookmarks[len(bookmarks)-1]
+		afterCreatedAt, afterChirpID = last.CreatedAt, last.ChirpID
+		return bookmarks, nil
+	}
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v any) error {
+	fw, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(fw).Encode(v)
+}
+
+// streamJSONArray writes the batches returned by next as one JSON array,
+// so only a single batch is held in memory at a time. An empty batch ends
+// the array.
+func streamJSONArray[T any](w io.Writer, next func() ([]T, error)) error {
+	_, err := io.WriteString(w, "[")
+	if err != nil {
+		return err
+	}
+
+	first := true
+	for {
+		batch, err := next()
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		for _, item := range batch {
+			if !first {
+				_, err = io.WriteString(w, ",")
+				if err != nil {
+					return err
+				}
+			}
+			first = false
+
+			dat, err := json.Marshal(item)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(dat)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = io.WriteString(w, "]\n")
+	return err
+}
+
+func writeChirpsHTML(w io.Writer, email string, next func() ([]database.Chirp, error)) error {
+	_, err := fmt.Fprintf(w, `<html>
+  <head>
+    <meta charset="utf-8">
+    <title>Chirps by %s</title>
+  </head>
+  <body>
+    <h1>Chirps by %s</h1>
+    <ul>
+`, html.EscapeString(email), html.EscapeString(email))
+	if err != nil {
+		return err
+	}
+
+	for {
+		chirps, err := next()
+		if err != nil {
+			return err
+		}
+		if len(chirps) == 0 {
+			break
+		}
+
+		for _, c := range chirps {
+			_, err = fmt.Fprintf(w, "      <li><time>%s</time> %s</li>\n", c.CreatedAt.Format(time.RFC3339), html.EscapeString(c.Body))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = io.WriteString(w, `    </ul>
+  </body>
+</html>
+`)
+	return err
+}
+
+func (cfg *apiConfig) purgeExpiredExports(ctx context.Context) error {
+	paths, err := cfg.db.DeleteExpiredDataExports(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range paths {
+		if p.Valid {
+			os.Remove(p.String)
+		}
+	}
+
+	return nil
+}
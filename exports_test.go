@@ -0,0 +1,263 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/auth"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func TestDataExport(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	a.chirp(alice, "take this with me")
+
+	a.expect(401, "POST", "/api/users/me/export", "", nil)
+	export := decode[returnDataExport](t, a.expect(202, "POST", "/api/users/me/export", alice.Token, nil))
+	if export.Status != "pending" {
+		t.Errorf("Want a pending export, got %+v", export)
+	}
+	a.expect(409, "POST", "/api/users/me/export", alice.Token, nil)
+	a.cfg.background.Wait()
+
+	notifications := decode[[]database.Notification](t, a.expect(200, "GET", "/api/notifications", alice.Token, nil))
+	if len(notifications) != 1 || notifications[0].Kind != "data_export" {
+		t.Errorf("Want alice told the export is ready, got %+v", notifications)
+	}
+
+	path := "/api/users/me/export/" + export.ID.String()
+	a.expect(404, "GET", path, bob.Token, nil)
+	a.expect(404, "GET", "/api/users/me/export/"+uuid.NewString(), alice.Token, nil)
+	rec := a.expect(302, "GET", path, alice.Token, nil)
+
+	download, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("Error parsing download URL: %v", err)
+	}
+	a.expect(403, "GET", download.Path, "", nil)
+	a.expect(403, "GET", download.Path+"?expires="+download.Query().Get("expires")+"&signature=bad", "", nil)
+	// Links are signed with their own secret, not the token secret.
+	expires, err := strconv.ParseInt(download.Query().Get("expires"), 10, 64)
+	if err != nil {
+		t.Fatalf("Error parsing expires: %v", err)
+	}
+	a.expect(403, "GET", auth.SignURL(download.Path, time.Unix(expires, 0), testTokenSecret), "", nil)
+
+	rec = a.expect(200, "GET", download.String(), "", nil)
+	if ct := rec.Header().Get("Content-Type"); ct != "application/zip" {
+		t.Errorf("Want a zip, got %q", ct)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("Error reading zip: %v", err)
+	}
+	files := map[string]bool{}
+	for _, f := range zr.File {
+		files[f.Name] = true
+	}
+	for _, name := range []string{"profile.json", "chirps.json", "chirps.html", "bookmarks.json"} {
+		if !files[name] {
+			t.Errorf("Want %s in the export, got %v", name, files)
+		}
+	}
+
+	// Once the export is built another may be asked for.
+	a.expect(202, "POST", "/api/users/me/export", alice.Token, nil)
+	a.cfg.background.Wait()
+}
+
+func TestDataExportFailed(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+
+	// A file where the export directory should be makes the build fail.
+	dir := filepath.Join(t.TempDir(), "exports")
+	err := os.WriteFile(dir, nil, 0o600)
+	if err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+	a.cfg.exportDir = dir
+
+	export := decode[returnDataExport](t, a.expect(202, "POST", "/api/users/me/export", alice.Token, nil))
+	a.cfg.background.Wait()
+
+	got := decode[returnDataExport](t, a.expect(200, "GET", "/api/users/me/export/"+export.ID.String(), alice.Token, nil))
+	if got.Status != "failed" {
+		t.Errorf("Want the export failed, got %+v", got)
+	}
+	notifications := decode[[]database.Notification](t, a.expect(200, "GET", "/api/notifications", alice.Token, nil))
+	if len(notifications) != 1 || notifications[0].Kind != "data_export" || !strings.Contains(notifications[0].Body, "could not be built") {
+		t.Errorf("Want alice told the export failed, got %+v", notifications)
+	}
+
+	// A failed export doesn't stop another being asked for.
+	a.cfg.exportDir = t.TempDir()
+	a.expect(202, "POST", "/api/users/me/export", alice.Token, nil)
+	a.cfg.background.Wait()
+}
+
+// TestResumeDataExports checks that an export left pending, as a restart
+// leaves one, is built once its lease runs out.
+func TestResumeDataExports(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	ctx := context.Background()
+
+	export, err := a.store.CreateDataExport(ctx, alice.ID)
+	if err != nil {
+		t.Fatalf("Error creating export: %v", err)
+	}
+	other, err := a.store.CreateDataExport(ctx, bob.ID)
+	if err != nil {
+		t.Fatalf("Error creating export: %v", err)
+	}
+
+	// A build that may still be running is left alone.
+	err = a.cfg.resumeDataExports(ctx)
+	if err != nil {
+		t.Fatalf("resumeDataExports returned error: %v", err)
+	}
+	if got, err := a.store.GetDataExport(ctx, export.ID); err != nil || got.Status != "pending" {
+		t.Fatalf("Want the export still pending, got %+v, %v", got, err)
+	}
+
+	a.store.SetClock(func() time.Time { return time.Now().Add(time.Hour) })
+	err = a.cfg.resumeDataExports(ctx)
+	if err != nil {
+		t.Fatalf("resumeDataExports returned error: %v", err)
+	}
+	for _, id := range []uuid.UUID{export.ID, other.ID} {
+		if got, err := a.store.GetDataExport(ctx, id); err != nil || got.Status != "ready" {
+			t.Errorf("Want the abandoned export built, got %+v, %v", got, err)
+		}
+	}
+}
+
+func TestDataExportContents(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	c := a.chirp(alice, "<b>bold</b> claim")
+	a.expect(200, "POST", "/api/users/"+bob.ID.String()+"/follow", alice.Token, nil)
+
+	export := decode[returnDataExport](t, a.expect(202, "POST", "/api/users/me/export", alice.Token, nil))
+	a.cfg.background.Wait()
+	got, err := a.store.GetDataExport(context.Background(), export.ID)
+	if err != nil {
+		t.Fatalf("Error getting export: %v", err)
+	}
+	zr, err := zip.OpenReader(got.FilePath.String)
+	if err != nil {
+		t.Fatalf("Error opening export: %v", err)
+	}
+	defer zr.Close()
+
+	read := func(name string) []byte {
+		t.Helper()
+		f, err := zr.Open(name)
+		if err != nil {
+			t.Fatalf("Error opening %s: %v", name, err)
+		}
+		defer f.Close()
+		dat, err := io.ReadAll(f)
+		if err != nil {
+			t.Fatalf("Error reading %s: %v", name, err)
+		}
+		return dat
+	}
+
+	var profile struct {
+		ID    uuid.UUID `json:"id"`
+		Email string    `json:"email"`
+	}
+	if err := json.Unmarshal(read("profile.json"), &profile); err != nil || profile.ID != alice.ID || profile.Email != alice.Email {
+		t.Errorf("Want alice's profile, got %+v, %v", profile, err)
+	}
+	var chirps []database.Chirp
+	if err := json.Unmarshal(read("chirps.json"), &chirps); err != nil || len(chirps) != 1 || chirps[0].ID != c.ID {
+		t.Errorf("Want alice's chirp, got %+v, %v", chirps, err)
+	}
+	if page := string(read("chirps.html")); !strings.Contains(page, "&lt;b&gt;bold&lt;/b&gt; claim") {
+		t.Errorf("Want the chirp escaped in the HTML, got %s", page)
+	}
+	var follows []database.Follow
+	if err := json.Unmarshal(read("follows.json"), &follows); err != nil || len(follows) != 1 || follows[0].FolloweeID != bob.ID {
+		t.Errorf("Want alice's follow of bob, got %+v, %v", follows, err)
+	}
+	var sessions []database.GetRefreshTokensForExportRow
+	if err := json.Unmarshal(read("sessions.json"), &sessions); err != nil || len(sessions) != 1 {
+		t.Errorf("Want alice's session, got %+v, %v", sessions, err)
+	}
+	if strings.Contains(string(read("sessions.json")), alice.RefreshToken) {
+		t.Errorf("Want no refresh tokens in the export")
+	}
+}
+
+// TestExpiredDataExport checks that an expired export can't be downloaded,
+// and that the cleanup worker removes it and its file.
+func TestExpiredDataExport(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	ctx := context.Background()
+
+	export := decode[returnDataExport](t, a.expect(202, "POST", "/api/users/me/export", alice.Token, nil))
+	a.cfg.background.Wait()
+	got, err := a.store.GetDataExport(ctx, export.ID)
+	if err != nil {
+		t.Fatalf("Error getting export: %v", err)
+	}
+	path := "/api/users/me/export/" + export.ID.String()
+	rec := a.expect(302, "GET", path, alice.Token, nil)
+
+	a.store.SetClock(func() time.Time { return time.Now().AddDate(0, 0, 8) })
+	err = a.cfg.purgeExpiredExports(ctx)
+	if err != nil {
+		t.Fatalf("purgeExpiredExports returned error: %v", err)
+	}
+	a.expect(404, "GET", path, alice.Token, nil)
+	a.expect(404, "GET", rec.Header().Get("Location"), "", nil)
+	if _, err := os.Stat(got.FilePath.String); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Want the export file removed, got %v", err)
+	}
+}
+
+func TestStreamJSONArray(t *testing.T) {
+	batches := [][]int{{1, 2}, {3}, {4, 5}}
+	var buf bytes.Buffer
+	err := streamJSONArray(&buf, func() ([]int, error) {
+		if len(batches) == 0 {
+			return nil, nil
+		}
+		batch := batches[0]
+		batches = batches[1:]
+		return batch, nil
+	})
+	if err != nil {
+		t.Fatalf("streamJSONArray returned error: %v", err)
+	}
+	if got := buf.String(); got != "[1,2,3,4,5]\n" {
+		t.Errorf("Want one array across the batches, got %q", got)
+	}
+
+	buf.Reset()
+	err = streamJSONArray(&buf, func() ([]int, error) { return nil, nil })
+	if err != nil || buf.String() != "[]\n" {
+		t.Errorf("Want an empty array, got %q, %v", buf.String(), err)
+	}
+}
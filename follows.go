@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/auth"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func (cfg *apiConfig) postFollow(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	if !cfg.checkCanWrite(w, r, id) {
+		return
+	}
+
+	target, ok := cfg.getTargetUser(w, r, id)
+	if !ok {
+		return
+	}
+
+	blocked, err := cfg.db.IsBlocked(r.Context(), database.IsBlockedParams{
+		UserID:      id,
+		OtherUserID: target.ID,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	if blocked {
+		respondWithError(w, 403, "Cannot follow this user")
+		return
+	}
+
+	status := "accepted"
+	if target.Protected {
+		status = "pending"
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context())
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	n, err := tx.CreateFollow(r.Context(), database.CreateFollowParams{
+		FollowerID: id,
+		FolloweeID: target.ID,
+		Status:     status,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	if n > 0 && status == "accepted" {
+		err = enqueueFollowed(r.Context(), tx, id, target.ID)
+		if err != nil {
+			respondWithError(w, 500, err.Error())
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	follow, err := cfg.db.GetFollow(r.Context(), database.GetFollowParams{
+		FollowerID: id,
+		FolloweeID: target.ID,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 200, follow)
+}
+
+func (cfg *apiConfig) deleteFollow(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	if !cfg.checkCanWrite(w, r, id) {
+		return
+	}
+
+	targetID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	err = cfg.db.DeleteFollow(r.Context(), database.DeleteFollowParams{
+		FollowerID: id,
+		FolloweeID: targetID,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 204, nil)
+}
+
+func (cfg *apiConfig) getFollowRequests(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	limit, offset, err := getPagination(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	requests, err := cfg.db.GetFollowRequests(r.Context(), database.GetFollowRequestsParams{
+		FolloweeID: id,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	if requests == nil {
+		requests = []database.Follow{}
+	}
+
+	respondWithJSON(w, 200, requests)
+}
+
+func (cfg *apiConfig) acceptFollowRequest(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	if !cfg.checkCanWrite(w, r, id) {
+		return
+	}
+
+	followerID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context())
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	n, err := tx.AcceptFollowRequest(r.Context(), database.AcceptFollowRequestParams{
+		FollowerID: followerID,
+		FolloweeID: id,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	if n == 0 {
+		respondWithError(w, 404, "Follow request not found")
+		return
+	}
+
+	err = enqueueFollowed(r.Context(), tx, followerID, id)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 204, nil)
+}
+
+func (cfg *apiConfig) rejectFollowRequest(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	if !cfg.checkCanWrite(w, r, id) {
+		return
+	}
+
+	followerID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	n, err := cfg.db.RejectFollowRequest(r.Context(), database.RejectFollowRequestParams{
+		FollowerID: followerID,
+		FolloweeID: id,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	if n == 0 {
+		respondWithError(w, 404, "Follow request not found")
+		return
+	}
+
+	respondWithJSON(w, 204, nil)
+}
+
+func (cfg *apiConfig) putProtected(w http.ResponseWriter, r *http.Request) {
+	type protectedParam struct {
+		Protected bool `json:"protected"`
+	}
+
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	if !cfg.checkCanWrite(w, r, id) {
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := protectedParam{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Invalid request")
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context())
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	err = tx.UpdateUserProtected(r.Context(), database.UpdateUserProtectedParams{
+		Protected: params.Protected,
+		ID:        id,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	// Anyone still waiting is let in once the account goes public again.
+	if !params.Protected {
+		followerIDs, err := tx.AcceptAllFollowRequests(r.Context(), id)
+		if err != nil {
+			respondWithError(w, 500, err.Error())
+			return
+		}
+		for _, followerID := range followerIDs {
+			err = enqueueFollowed(r.Context(), tx, followerID, id)
+			if err != nil {
+				respondWithError(w, 500, err.Error())
+				return
+			}
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 200, params)
+}
+
+// enqueueFollowed records a user.followed event for the followed user.
+func enqueueFollowed(ctx context.Context, q database.Querier, followerID, followeeID uuid.UUID) error {
+	return enqueueEvent(ctx, q, "user.followed", followeeID, map[string]uuid.UUID{
+		"follower_id": followerID,
+		"followee_id": followeeID,
+	})
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func TestFollow(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+
+	path := "/api/users/" + bob.ID.String() + "/follow"
+	a.expect(401, "POST", path, "", nil)
+	a.expect(400, "POST", "/api/users/"+alice.ID.String()+"/follow", alice.Token, nil)
+	a.expect(404, "POST", "/api/users/"+uuid.NewString()+"/follow", alice.Token, nil)
+
+	follow := decode[database.Follow](t, a.expect(200, "POST", path, alice.Token, nil))
+	if follow.FollowerID != alice.ID || follow.FolloweeID != bob.ID || follow.Status != "accepted" {
+		t.Errorf("Want an accepted follow of bob, got %+v", follow)
+	}
+
+	a.expect(204, "DELETE", path, alice.Token, nil)
+	a.expect(401, "DELETE", path, "", nil)
+}
+
+func TestFollowRequests(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	carol := a.signup("carol")
+	c := a.chirp(bob, "followers only")
+
+	a.expect(400, "PUT", "/api/users/me/protected", bob.Token, []byte("not json"))
+	rec := a.expect(200, "PUT", "/api/users/me/protected", bob.Token, map[string]bool{"protected": true})
+	if res := decode[map[string]bool](t, rec); !res["protected"] {
+		t.Errorf("Want the account protected, got %v", res)
+	}
+	a.expect(404, "GET", "/api/chirps/"+c.ID.String(), alice.Token, nil)
+
+	path := "/api/users/" + bob.ID.String() + "/follow"
+	follow := decode[database.Follow](t, a.expect(200, "POST", path, alice.Token, nil))
+	if follow.Status != "pending" {
+		t.Errorf("Want a pending follow of a protected account, got %+v", follow)
+	}
+	a.expect(200, "POST", path, carol.Token, nil)
+
+	requests := decode[[]database.Follow](t, a.expect(200, "GET", "/api/follow-requests", bob.Token, nil))
+	if len(requests) != 2 {
+		t.Errorf("Want 2 follow requests, got %+v", requests)
+	}
+
+	a.expect(204, "POST", "/api/follow-requests/"+alice.ID.String()+"/accept", bob.Token, nil)
+	a.expect(204, "POST", "/api/follow-requests/"+carol.ID.String()+"/reject", bob.Token, nil)
+	a.expect(404, "POST", "/api/follow-requests/"+carol.ID.String()+"/accept", bob.Token, nil)
+	a.expect(404, "POST", "/api/follow-requests/"+carol.ID.String()+"/reject", bob.Token, nil)
+
+	a.expect(200, "GET", "/api/chirps/"+c.ID.String(), alice.Token, nil)
+	a.expect(404, "GET", "/api/chirps/"+c.ID.String(), carol.Token, nil)
+
+	requests = decode[[]database.Follow](t, a.expect(200, "GET", "/api/follow-requests", bob.Token, nil))
+	if len(requests) != 0 {
+		t.Errorf("Want no follow requests left, got %+v", requests)
+	}
+}
+
+// TestProtectedListings checks that a protected account's chirps are only
+// listed for the owner and accepted followers.
+func TestProtectedListings(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	carol := a.signup("carol")
+	a.chirp(bob, "followers only")
+	a.expect(200, "PUT", "/api/users/me/protected", bob.Token, map[string]bool{"protected": true})
+	a.expect(200, "POST", "/api/users/"+bob.ID.String()+"/follow", alice.Token, nil)
+	a.expect(200, "POST", "/api/users/"+bob.ID.String()+"/follow", carol.Token, nil)
+	a.expect(401, "GET", "/api/follow-requests", "", nil)
+	a.expect(404, "POST", "/api/follow-requests/"+alice.ID.String()+"/accept", carol.Token, nil)
+	a.expect(204, "POST", "/api/follow-requests/"+alice.ID.String()+"/accept", bob.Token, nil)
+
+	count := func(u testUser, path string) int {
+		t.Helper()
+		return len(decode[[]returnChirp](t, a.expect(200, "GET", path, u.Token, nil)))
+	}
+	byBob := "/api/chirps?author_id=" + bob.ID.String()
+	for _, path := range []string{"/api/chirps", byBob} {
+		if n := count(bob, path); n != 1 {
+			t.Errorf("%s: want bob to see his own chirp, got %d", path, n)
+		}
+		if n := count(alice, path); n != 1 {
+			t.Errorf("%s: want an accepted follower to see the chirp, got %d", path, n)
+		}
+		// carol's request is still pending.
+		if n := count(carol, path); n != 0 {
+			t.Errorf("%s: want the chirp hidden from a pending follower, got %d", path, n)
+		}
+		if n := len(decode[[]returnChirp](t, a.expect(200, "GET", path, "", nil))); n != 0 {
+			t.Errorf("%s: want the chirp hidden without a token, got %d", path, n)
+		}
+	}
+
+	a.expect(200, "PUT", "/api/users/me/protected", bob.Token, map[string]bool{"protected": false})
+	if n := count(carol, byBob); n != 1 {
+		t.Errorf("Want the chirp visible once bob is unprotected, got %d", n)
+	}
+}
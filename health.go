@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const readinessDBTimeout = 2 * time.Second
+
+// workerHealth tracks a background worker's runs, so readiness can tell
+// when one has got stuck.
+type workerHealth struct {
+	interval time.Duration
+	// maxRun is the longest a run can legitimately take, such as a batch
+	// of requests that each run to their timeout.
+	maxRun time.Duration
+
+	mu      sync.Mutex
+	started time.Time
+	running bool
+	lastRun time.Time
+	lastErr error
+}
+
+func (h *workerHealth) begin() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.running = true
+	h.started = time.Now()
+}
+
+func (h *workerHealth) finish(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.running = false
+	h.lastRun = time.Now()
+	h.lastErr = err
+}
+
+type workerCheck struct {
+	Status    string     `json:"status"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+}
+
+// check reports a worker as stuck when a run has taken longer than its
+// maxRun, or the next run is overdue by more than an interval, with a
+// minute's grace either way. Failed runs are reported but don't make the
+// worker unhealthy, since the checks of what they depend on cover that.
+func (h *workerHealth) check(now time.Time) workerCheck {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	res := workerCheck{Status: "ok"}
+	if !h.lastRun.IsZero() {
+		lastRun := h.lastRun
+		res.LastRunAt = &lastRun
+	}
+	if h.lastErr != nil {
+		res.LastError = h.lastErr.Error()
+	}
+
+	limit := h.interval + time.Minute
+	since := h.lastRun
+	if h.running {
+		limit = h.maxRun + time.Minute
+		since = h.started
+	}
+	if !since.IsZero() && now.Sub(since) > limit {
+		res.Status = "stuck"
+	}
+	return res
+}
+
+// livenessEndpoint only shows that the process is serving requests, so a
+// database outage doesn't get the server restarted.
+func livenessEndpoint(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Add("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(200)
+	w.Write([]byte("OK"))
+}
+
+// readinessEndpoint reports whether the server should get traffic, with
+// the result of each check. It answers 503 if any check fails.
+func (cfg *apiConfig) readinessEndpoint(w http.ResponseWriter, r *http.Request) {
+	type check struct {
+		Status    string                 `json:"status"`
+		Error     string                 `json:"error,omitempty"`
+		LatencyMS *float64               `json:"latency_ms,omitempty"`
+		Version   *int64                 `json:"version,omitempty"`
+		Want      *int64                 `json:"want,omitempty"`
+		Workers   map[string]workerCheck `json:"workers,omitempty"`
+	}
+	type readiness struct {
+		Status string           `json:"status"`
+		Checks map[string]check `json:"checks"`
+	}
+
+	res := readiness{Status: "ready", Checks: map[string]check{}}
+	fail := func(name string, c check, err string) {
+		c.Status = "failed"
+		c.Error = err
+		res.Checks[name] = c
+		res.Status = "not_ready"
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessDBTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := cfg.db.Ping(ctx)
+	latency := float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		fail("database", check{LatencyMS: &latency}, err.Error())
+	} else {
+		res.Checks["database"] = check{Status: "ok", LatencyMS: &latency}
+	}
+
+	// Stores other than Postgres, such as the tests' in-memory one, have
+	// no migrations to check.
+	if cfg.migrator != nil {
+		version, want, err := cfg.migrator.GetVersions(ctx)
+		switch {
+		case err != nil:
+			fail("migrations", check{}, err.Error())
+		case version < want:
+			fail("migrations", check{Version: &version, Want: &want}, "database schema is behind")
+		default:
+			res.Checks["migrations"] = check{Status: "ok", Version: &version, Want: &want}
+		}
+	}
+
+	workers := check{Status: "ok", Workers: map[string]workerCheck{}}
+	now := time.Now()
+	cfg.workersMu.Lock()
+	for name, h := range cfg.workers {
+		workers.Workers[name] = h.check(now)
+		if workers.Workers[name].Status != "ok" {
+			workers.Status = "failed"
+			workers.Error = "a worker is stuck"
+		}
+	}
+	cfg.workersMu.Unlock()
+	if workers.Status != "ok" {
+		res.Status = "not_ready"
+	}
+	res.Checks["workers"] = workers
+
+	if cfg.shuttingDown.Load() {
+		fail("shutdown", check{}, "server is shutting down")
+	} else {
+		res.Checks["shutdown"] = check{Status: "ok"}
+	}
+
+	code := 200
+	if res.Status != "ready" {
+		code = 503
+	}
+	respondWithJSON(w, code, res)
+}
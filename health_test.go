@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLiveness(t *testing.T) {
+	a := newTestAPI(t)
+
+	for _, path := range []string{"/api/livez", "/api/healthz"} {
+		rec := a.expect(200, "GET", path, "", nil)
+		if rec.Body.String() != "OK" {
+			t.Errorf("%s: want OK, got %q", path, rec.Body.String())
+		}
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	type readiness struct {
+		Status string `json:"status"`
+		Checks map[string]struct {
+			Status string `json:"status"`
+		} `json:"checks"`
+	}
+
+	a := newTestAPI(t)
+	h := &workerHealth{interval: time.Minute, maxRun: time.Minute}
+	a.cfg.workers["purge"] = h
+
+	res := decode[readiness](t, a.expect(200, "GET", "/api/readyz", "", nil))
+	if res.Status != "ready" || res.Checks["database"].Status != "ok" {
+		t.Errorf("Want ready, got %+v", res)
+	}
+
+	// A failed run doesn't make the worker stuck.
+	h.begin()
+	h.finish(errors.New("boom"))
+	a.expect(200, "GET", "/api/readyz", "", nil)
+
+	h.begin()
+	h.started = time.Now().Add(-time.Hour)
+	res = decode[readiness](t, a.expect(503, "GET", "/api/readyz", "", nil))
+	if res.Checks["workers"].Status != "failed" {
+		t.Errorf("Want a stuck worker to fail readiness, got %+v", res)
+	}
+	h.finish(nil)
+
+	a.cfg.shuttingDown.Store(true)
+	res = decode[readiness](t, a.expect(503, "GET", "/api/readyz", "", nil))
+	if res.Status != "not_ready" || res.Checks["shutdown"].Status != "failed" {
+		t.Errorf("Want not ready while shutting down, got %+v", res)
+	}
+}
+
+// TestWorkerHealthMaxRun checks that a run is judged by how long it can
+// take, not how often the worker runs.
+func TestWorkerHealthMaxRun(t *testing.T) {
+	now := time.Now()
+	h := &workerHealth{interval: 5 * time.Second, maxRun: 200 * time.Second}
+
+	h.begin()
+	h.started = now.Add(-150 * time.Second)
+	if got := h.check(now).Status; got != "ok" {
+		t.Errorf("Want a long run within maxRun ok, got %s", got)
+	}
+	h.started = now.Add(-5 * time.Minute)
+	if got := h.check(now).Status; got != "stuck" {
+		t.Errorf("Want a run past maxRun stuck, got %s", got)
+	}
+
+	h.finish(nil)
+	h.lastRun = now.Add(-2 * time.Minute)
+	if got := h.check(now).Status; got != "stuck" {
+		t.Errorf("Want an overdue run stuck, got %s", got)
+	}
+}
@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strconv"
+	"time"
+)
+
+// Entry is the part of an audit event that is covered by its hash. Each
+// entry's hash also covers the previous entry's hash, so changing, removing
+// or reordering any entry breaks every hash after it.
+type Entry struct {
+	CreatedAt  time.Time
+	Action     string
+	ActorID    string
+	TargetType string
+	TargetID   string
+	IP         string
+	UserAgent  string
+	RequestID  string
+	Metadata   []byte
+	PrevHash   string
+	Hash       string
+}
+
+// ComputeHash returns the hex SHA-256 of the entry's fields. Every field is
+// length-prefixed so no two different entries encode to the same bytes.
+func (e Entry) ComputeHash() string {
+	h := sha256.New()
+	writeField(h, []byte(e.PrevHash))
+	writeField(h, []byte(e.CreatedAt.UTC().Format(time.RFC3339Nano)))
+	writeField(h, []byte(e.Action))
+	writeField(h, []byte(e.ActorID))
+	writeField(h, []byte(e.TargetType))
+	writeField(h, []byte(e.TargetID))
+	writeField(h, []byte(e.IP))
+	writeField(h, []byte(e.UserAgent))
+	writeField(h, []byte(e.RequestID))
+	writeField(h, e.Metadata)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeField(h hash.Hash, b []byte) {
+	h.Write([]byte(strconv.Itoa(len(b))))
+	h.Write([]byte{':'})
+	h.Write(b)
+}
+
+// Verify checks that each entry's hash is correct and that it links to the
+// entry before it. The first entry has to link to prevHash: the hash of
+// the last entry before them, or the boundary the retention policy
+// recorded when it removed the oldest entries. It returns the index of the
+// first bad entry.
+func Verify(entries []Entry, prevHash string) (int, error) {
+	for i, e := range entries {
+		if e.PrevHash != prevHash {
+			return i, fmt.Errorf("entry %d does not link to the entry before it", i)
+		}
+		prevHash = e.Hash
+		if e.ComputeHash() != e.Hash {
+			return i, fmt.Errorf("entry %d has been modified", i)
+		}
+	}
+	return -1, nil
+}
@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"testing"
+	"time"
+)
+
+func makeChain(actions ...string) []Entry {
+	var entries []Entry
+	prev := ""
+	for i, action := range actions {
+		e := Entry{
+			CreatedAt: time.Date(2025, 1, 1, 0, 0, i, 0, time.UTC),
+			Action:    action,
+			ActorID:   "actor",
+			Metadata:  []byte(`{}`),
+			PrevHash:  prev,
+		}
+		e.Hash = e.ComputeHash()
+		prev = e.Hash
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestVerify(t *testing.T) {
+	entries := makeChain("auth.login", "auth.refresh", "auth.revoke")
+	i, err := Verify(entries, "")
+	if err != nil {
+		t.Errorf("Want valid chain, got error at %d: %v", i, err)
+	}
+}
+
+func TestVerifyModified(t *testing.T) {
+	entries := makeChain("auth.login", "auth.refresh", "auth.revoke")
+	entries[1].ActorID = "someone else"
+	i, err := Verify(entries, "")
+	if err == nil || i != 1 {
+		t.Errorf("Want error at 1, got %d: %v", i, err)
+	}
+}
+
+func TestVerifyRemoved(t *testing.T) {
+	entries := makeChain("auth.login", "auth.refresh", "auth.revoke")
+	entries = append(entries[:1], entries[2:]...)
+	i, err := Verify(entries, "")
+	if err == nil || i != 1 {
+		t.Errorf("Want error at 1, got %d: %v", i, err)
+	}
+}
+
+func TestVerifyAfterRetention(t *testing.T) {
+	entries := makeChain("auth.login", "auth.refresh", "auth.revoke")
+	i, err := Verify(entries[1:], entries[0].Hash)
+	if err != nil {
+		t.Errorf("Want valid chain, got error at %d: %v", i, err)
+	}
+}
+
+// TestVerifyTruncated checks that removing the oldest entries is caught
+// when the anchor is the chain's start rather than a recorded boundary.
+func TestVerifyTruncated(t *testing.T) {
+	entries := makeChain("auth.login", "auth.refresh", "auth.revoke")
+	i, err := Verify(entries[1:], "")
+	if err == nil || i != 0 {
+		t.Errorf("Want error at 0, got %d: %v", i, err)
+	}
+}
+
+func TestHashIsUnambiguous(t *testing.T) {
+	a := Entry{Action: "ab", ActorID: "c"}
+	b := Entry{Action: "a", ActorID: "bc"}
+	if a.ComputeHash() == b.ComputeHash() {
+		t.Errorf("Different entries hashed the same")
+	}
+}
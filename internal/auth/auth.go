@@ -1,10 +1,13 @@
 package auth
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -72,9 +75,75 @@ func MakeRefreshToken() (string, error) {
 }
 
 func GetAPIKey(headers http.Header) (string, error) {
-	res := strings.Split(headers.Get("Authorization"), " ")
-	if len(res) == 2 {
-		return res[1], nil
+	key, ok := strings.CutPrefix(headers.Get("Authorization"), "ApiKey ")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", errors.New("Invalid authorization string")
 	}
-	return "", errors.New("Invalid authorization string")
+	return key, nil
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of timestamp and body joined by a
+// dot, which is what webhook senders put in the signature header.
+func SignWebhook(timestamp string, body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidateWebhookSignature checks signature against every secret so a new
+// secret can be rolled out before the old one is retired. The timestamp is
+// Unix seconds and must be within tolerance of now to limit replays.
+func ValidateWebhookSignature(timestamp, signature string, body []byte, secrets []string, tolerance time.Duration) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("Invalid webhook timestamp")
+	}
+	age := time.Since(time.Unix(ts, 0))
+	if age > tolerance || age < -tolerance {
+		return errors.New("Webhook timestamp outside tolerance")
+	}
+
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return errors.New("Invalid webhook signature")
+	}
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		want, _ := hex.DecodeString(SignWebhook(timestamp, body, secret))
+		if hmac.Equal(got, want) {
+			return nil
+		}
+	}
+	return errors.New("Invalid webhook signature")
+}
+
+// SignURL returns path with an expiry and an HMAC signature appended as query
+// parameters, so it can be handed out as a download link without a token.
+func SignURL(path string, expiresAt time.Time, secret string) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	return path + "?expires=" + expires + "&signature=" + urlSignature(path, expires, secret)
+}
+
+func ValidateSignedURL(path, expires, signature, secret string) error {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return errors.New("Invalid expiry")
+	}
+	if time.Now().Unix() > exp {
+		return errors.New("URL expired")
+	}
+	if !hmac.Equal([]byte(signature), []byte(urlSignature(path, expires, secret))) {
+		return errors.New("Invalid signature")
+	}
+	return nil
+}
+
+func urlSignature(path, expires, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(path + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
 }
@@ -2,6 +2,8 @@ package auth
 
 import (
 	"net/http"
+	"net/url"
+	"strconv"
 	"testing"
 	"time"
 
@@ -62,3 +64,133 @@ func TestGetBearerToken(t *testing.T) {
 		t.Errorf("Want %v, got %v. Error: %v", "TOKEN_STRING", tok, err.Error())
 	}
 }
+
+func TestSignedURL(t *testing.T) {
+	signed := SignURL("/api/exports/1/download", time.Now().Add(time.Minute), "1234")
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("Error parsing signed URL: %v", err.Error())
+	}
+	q := u.Query()
+	err = ValidateSignedURL(u.Path, q.Get("expires"), q.Get("signature"), "1234")
+	if err != nil {
+		t.Errorf("Want valid signature, got error: %v", err.Error())
+	}
+}
+
+func TestSignedURLTampered(t *testing.T) {
+	signed := SignURL("/api/exports/1/download", time.Now().Add(time.Minute), "1234")
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("Error parsing signed URL: %v", err.Error())
+	}
+	q := u.Query()
+	err = ValidateSignedURL("/api/exports/2/download", q.Get("expires"), q.Get("signature"), "1234")
+	if err == nil {
+		t.Errorf("Want error for tampered path, got nil")
+	}
+	err = ValidateSignedURL(u.Path, q.Get("expires"), q.Get("signature"), "1235")
+	if err == nil {
+		t.Errorf("Want error for incorrect secret, got nil")
+	}
+}
+
+func TestSignedURLExpired(t *testing.T) {
+	signed := SignURL("/api/exports/1/download", time.Now().Add(-time.Minute), "1234")
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("Error parsing signed URL: %v", err.Error())
+	}
+	q := u.Query()
+	err = ValidateSignedURL(u.Path, q.Get("expires"), q.Get("signature"), "1234")
+	if err == nil || err.Error() != "URL expired" {
+		t.Errorf("Want URL expired, got %v", err)
+	}
+}
+
+func TestGetAPIKey(t *testing.T) {
+	h := http.Header{}
+	h.Add("Authorization", "ApiKey KEY_STRING")
+	key, err := GetAPIKey(h)
+	if key != "KEY_STRING" || err != nil {
+		t.Errorf("Want %v, got %v. Error: %v", "KEY_STRING", key, err)
+	}
+}
+
+func TestGetAPIKeyWrongScheme(t *testing.T) {
+	h := http.Header{}
+	h.Add("Authorization", "Bearer KEY_STRING")
+	key, err := GetAPIKey(h)
+	if err == nil {
+		t.Errorf("Want error, got %v", key)
+	}
+}
+
+func TestWebhookSignature(t *testing.T) {
+	body := []byte(`{"event":"user.upgraded"}`)
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	sig := SignWebhook(ts, body, "new")
+
+	err := ValidateWebhookSignature(ts, sig, body, []string{"old", "new"}, 5*time.Minute)
+	if err != nil {
+		t.Errorf("Want valid signature, got error: %v", err.Error())
+	}
+
+	err = ValidateWebhookSignature(ts, sig, []byte(`{"event":"user.downgraded"}`), []string{"old", "new"}, 5*time.Minute)
+	if err == nil {
+		t.Errorf("Want error for tampered body, got nil")
+	}
+
+	err = ValidateWebhookSignature(ts, sig, body, []string{"old"}, 5*time.Minute)
+	if err == nil {
+		t.Errorf("Want error for retired secret, got nil")
+	}
+}
+
+func TestWebhookSignatureStale(t *testing.T) {
+	body := []byte(`{"event":"user.upgraded"}`)
+	ts := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	sig := SignWebhook(ts, body, "1234")
+
+	err := ValidateWebhookSignature(ts, sig, body, []string{"1234"}, 5*time.Minute)
+	if err == nil || err.Error() != "Webhook timestamp outside tolerance" {
+		t.Errorf("Want timestamp error, got %v", err)
+	}
+}
+
+func TestGetAPIKeyMalformed(t *testing.T) {
+	for _, header := range []string{"", "ApiKey", "ApiKey ", "ApiKeyKEY_STRING", "Token ApiKey KEY_STRING"} {
+		h := http.Header{}
+		h.Set("Authorization", header)
+		key, err := GetAPIKey(h)
+		if err == nil {
+			t.Errorf("%q: want error, got %v", header, key)
+		}
+	}
+}
+
+func TestWebhookSignatureMalformed(t *testing.T) {
+	body := []byte(`{"event":"user.upgraded"}`)
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	sig := SignWebhook(ts, body, "1234")
+
+	tests := map[string]struct {
+		timestamp string
+		signature string
+		secrets   []string
+	}{
+		"bad timestamp":  {"yesterday", sig, []string{"1234"}},
+		"future":         {strconv.FormatInt(time.Now().Add(10*time.Minute).Unix(), 10), sig, []string{"1234"}},
+		"not hex":        {ts, "not-hex", []string{"1234"}},
+		"empty":          {ts, "", []string{"1234"}},
+		"no secrets":     {ts, sig, nil},
+		"empty secret":   {ts, SignWebhook(ts, body, ""), []string{""}},
+		"other endpoint": {ts, SignWebhook(ts, []byte(`{}`), "1234"), []string{"1234"}},
+	}
+	for name, tt := range tests {
+		err := ValidateWebhookSignature(tt.timestamp, tt.signature, body, tt.secrets, 5*time.Minute)
+		if err == nil {
+			t.Errorf("%s: want error, got nil", name)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package certreload
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeCert(t *testing.T, dir string, serial int64, modTime time.Time) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{certFile, keyFile} {
+		err = os.Chtimes(path, modTime, modTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return certFile, keyFile
+}
+
+func servedSerial(t *testing.T, r *Reloader) int64 {
+	t.Helper()
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.SerialNumber.Int64()
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().Add(-time.Hour)
+	certFile, keyFile := writeCert(t, dir, 1, start)
+
+	r, err := New(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if got := servedSerial(t, r); got != 1 {
+		t.Errorf("Want serial 1, got %d", got)
+	}
+
+	reloaded, err := r.Reload()
+	if err != nil || reloaded {
+		t.Errorf("Want no reload of unchanged files, got %v, %v", reloaded, err)
+	}
+
+	writeCert(t, dir, 2, start.Add(time.Minute))
+	reloaded, err = r.Reload()
+	if err != nil || !reloaded {
+		t.Errorf("Want reload of renewed files, got %v, %v", reloaded, err)
+	}
+	if got := servedSerial(t, r); got != 2 {
+		t.Errorf("Want serial 2, got %d", got)
+	}
+}
+
+func TestReloadKeepsCertOnError(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().Add(-time.Hour)
+	certFile, keyFile := writeCert(t, dir, 1, start)
+
+	r, err := New(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	err = os.WriteFile(certFile, []byte("half written"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.Reload()
+	if err == nil {
+		t.Errorf("Want error for invalid certificate, got nil")
+	}
+	if got := servedSerial(t, r); got != 1 {
+		t.Errorf("Want the old certificate to be kept, got serial %d", got)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.pem"), "missing.key")
+	if err == nil {
+		t.Errorf("Want error for missing files, got nil")
+	}
+}
@@ -0,0 +1,513 @@
+// Package config loads the server's settings from defaults, an optional
+// config file, the environment and command-line flags, in increasing order
+// of precedence.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joshckidd/chirpy/internal/logging"
+)
+
+const (
+	PlatformDev  = "dev"
+	PlatformProd = "prod"
+
+	// MinSecretLength is the shortest token secret accepted in production.
+	MinSecretLength = 32
+
+	redacted = "[redacted]"
+)
+
+type Config struct {
+	DBURL               string
+	Secret              string
+	PolkaWebhookSecrets []string
+	Platform            string
+	Addr                string
+	// MetricsAddr is where /metrics is served, kept off the public address
+	// so it can be left unexposed. With none, metrics aren't served.
+	MetricsAddr string
+
+	// ExportURLSecret signs data export download links. It is kept apart
+	// from Secret so a leaked link can't help forge tokens, and so either
+	// can be rotated alone. In dev a random one is used if it's unset.
+	ExportURLSecret string
+
+	FileServerRoot   string
+	ExportDir        string
+	EntitlementsFile string
+	// AuditRetention of zero keeps audit events forever.
+	AuditRetention time.Duration
+
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	// ShutdownDelay is how long the server keeps serving, while reporting
+	// not ready, after a shutdown signal. ShutdownTimeout is how long
+	// in-flight requests and workers then get to finish.
+	ShutdownDelay   time.Duration
+	ShutdownTimeout time.Duration
+
+	// TLS is served when both files are set. They are reloaded when they
+	// change on disk, so certificates can be renewed without a restart.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	LogLevels logging.Levels
+
+	// AutoMigrate applies pending migrations at startup. Without it the
+	// server refuses to start until they've been applied with chirpy
+	// migrate up.
+	AutoMigrate bool
+
+	// OTLPEndpoint is the OpenTelemetry collector traces are sent to. With
+	// none, trace context is still propagated but nothing is exported.
+	OTLPEndpoint string
+	ServiceName  string
+}
+
+// setting is one config value, with its file key, environment variable and
+// flag. Flags are only provided for settings that aren't secret, as flags
+// show up in process listings.
+type setting struct {
+	key    string
+	env    string
+	flag   string
+	usage  string
+	secret bool
+	// unquoted is set for settings written unquoted in the config file,
+	// such as numbers and booleans.
+	unquoted bool
+	get      func(*Config) string
+	set      func(*Config, string) error
+}
+
+// fields lists every setting once, so that loading and the dump can't
+// drift apart.
+var fields = []setting{
+	{
+		key: "db_url", env: "DB_URL", secret: true,
+		get: func(c *Config) string { return c.DBURL },
+		set: func(c *Config, v string) error { c.DBURL = v; return nil },
+	},
+	{
+		key: "secret", env: "SECRET", secret: true,
+		get: func(c *Config) string { return c.Secret },
+		set: func(c *Config, v string) error { c.Secret = v; return nil },
+	},
+	{
+		// Several comma-separated secrets may be active while one is
+		// rotated out.
+		key: "polka_webhook_secrets", env: "POLKA_WEBHOOK_SECRETS", secret: true,
+		get: func(c *Config) string { return strings.Join(c.PolkaWebhookSecrets, ",") },
+		set: func(c *Config, v string) error { c.PolkaWebhookSecrets = splitList(v); return nil },
+	},
+	{
+		key: "export_url_secret", env: "EXPORT_URL_SECRET", secret: true,
+		get: func(c *Config) string { return c.ExportURLSecret },
+		set: func(c *Config, v string) error { c.ExportURLSecret = v; return nil },
+	},
+	{
+		key: "platform", env: "PLATFORM", flag: "platform", usage: "dev or prod",
+		get: func(c *Config) string { return c.Platform },
+		set: func(c *Config, v string) error { c.Platform = v; return nil },
+	},
+	{
+		key: "addr", env: "ADDR", flag: "addr", usage: "address to listen on",
+		get: func(c *Config) string { return c.Addr },
+		set: func(c *Config, v string) error { c.Addr = v; return nil },
+	},
+	{
+		key: "metrics_addr", env: "METRICS_ADDR", flag: "metrics-addr", usage: "internal address to serve /metrics on",
+		get: func(c *Config) string { return c.MetricsAddr },
+		set: func(c *Config, v string) error { c.MetricsAddr = v; return nil },
+	},
+	{
+		key: "fileserver_root", env: "FILESERVER_ROOT", flag: "fileserver-root", usage: "directory served under /app/",
+		get: func(c *Config) string { return c.FileServerRoot },
+		set: func(c *Config, v string) error { c.FileServerRoot = v; return nil },
+	},
+	{
+		key: "export_dir", env: "EXPORT_DIR", flag: "export-dir", usage: "directory data exports are written to",
+		get: func(c *Config) string { return c.ExportDir },
+		set: func(c *Config, v string) error { c.ExportDir = v; return nil },
+	},
+	{
+		key: "entitlements_file", env: "ENTITLEMENTS_FILE", flag: "entitlements-file", usage: "JSON file of plan limits",
+		get: func(c *Config) string { return c.EntitlementsFile },
+		set: func(c *Config, v string) error { c.EntitlementsFile = v; return nil },
+	},
+	{
+		key: "audit_retention_days", env: "AUDIT_RETENTION_DAYS", flag: "audit-retention-days", usage: "days to keep audit events, 0 for ever", unquoted: true,
+		get: func(c *Config) string { return strconv.Itoa(int(c.AuditRetention / (24 * time.Hour))) },
+		set: func(c *Config, v string) error {
+			days, err := strconv.Atoi(v)
+			if err != nil || days < 0 {
+				return errors.New("must be a whole number of days")
+			}
+			c.AuditRetention = time.Duration(days) * 24 * time.Hour
+			return nil
+		},
+	},
+	durationField("read_timeout", "READ_TIMEOUT", "read-timeout", "time to read a whole request", func(c *Config) *time.Duration { return &c.ReadTimeout }),
+	durationField("read_header_timeout", "READ_HEADER_TIMEOUT", "read-header-timeout", "time to read request headers", func(c *Config) *time.Duration { return &c.ReadHeaderTimeout }),
+	durationField("write_timeout", "WRITE_TIMEOUT", "write-timeout", "time to write a response", func(c *Config) *time.Duration { return &c.WriteTimeout }),
+	durationField("idle_timeout", "IDLE_TIMEOUT", "idle-timeout", "time to keep idle connections open", func(c *Config) *time.Duration { return &c.IdleTimeout }),
+	durationField("shutdown_timeout", "SHUTDOWN_TIMEOUT", "shutdown-timeout", "time to drain requests on shutdown", func(c *Config) *time.Duration { return &c.ShutdownTimeout }),
+	{
+		key: "shutdown_delay", env: "SHUTDOWN_DELAY", flag: "shutdown-delay", usage: "time to report not ready before shutting down",
+		get: func(c *Config) string { return c.ShutdownDelay.String() },
+		set: func(c *Config, v string) error {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				return errors.New("must be a duration such as 5s")
+			}
+			c.ShutdownDelay = d
+			return nil
+		},
+	},
+	{
+		key: "max_header_bytes", env: "MAX_HEADER_BYTES", flag: "max-header-bytes", usage: "largest request headers accepted", unquoted: true,
+		get: func(c *Config) string { return strconv.Itoa(c.MaxHeaderBytes) },
+		set: func(c *Config, v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return errors.New("must be a positive number of bytes")
+			}
+			c.MaxHeaderBytes = n
+			return nil
+		},
+	},
+	{
+		key: "tls_cert_file", env: "TLS_CERT_FILE", flag: "tls-cert-file", usage: "PEM certificate chain to serve TLS with",
+		get: func(c *Config) string { return c.TLSCertFile },
+		set: func(c *Config, v string) error { c.TLSCertFile = v; return nil },
+	},
+	{
+		key: "tls_key_file", env: "TLS_KEY_FILE", flag: "tls-key-file", usage: "PEM private key for -tls-cert-file",
+		get: func(c *Config) string { return c.TLSKeyFile },
+		set: func(c *Config, v string) error { c.TLSKeyFile = v; return nil },
+	},
+	{
+		key: "log_level", env: "LOG_LEVEL", flag: "log-level", usage: "log levels, e.g. info,http=debug",
+		get: func(c *Config) string { return c.LogLevels.String() },
+		set: func(c *Config, v string) error {
+			levels, err := logging.ParseLevels(v)
+			if err != nil {
+				return err
+			}
+			c.LogLevels = levels
+			return nil
+		},
+	},
+	{
+		key: "auto_migrate", env: "AUTO_MIGRATE", flag: "auto-migrate", usage: "apply pending migrations at startup", unquoted: true,
+		get: func(c *Config) string { return strconv.FormatBool(c.AutoMigrate) },
+		set: func(c *Config, v string) error {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return errors.New("must be true or false")
+			}
+			c.AutoMigrate = b
+			return nil
+		},
+	},
+	{
+		key: "otlp_endpoint", env: "OTEL_EXPORTER_OTLP_ENDPOINT", flag: "otlp-endpoint", usage: "OpenTelemetry collector URL, e.g. http://localhost:4318",
+		get: func(c *Config) string { return c.OTLPEndpoint },
+		set: func(c *Config, v string) error {
+			u, err := url.Parse(v)
+			if v != "" && (err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "") {
+				return errors.New("must be an http or https URL")
+			}
+			c.OTLPEndpoint = v
+			return nil
+		},
+	},
+	{
+		key: "service_name", env: "OTEL_SERVICE_NAME", flag: "service-name", usage: "service name traces are reported under",
+		get: func(c *Config) string { return c.ServiceName },
+		set: func(c *Config, v string) error { c.ServiceName = v; return nil },
+	},
+}
+
+// durationField is a setting written like "30s" or "2m".
+func durationField(key, env, flag, usage string, field func(*Config) *time.Duration) setting {
+	return setting{
+		key: key, env: env, flag: flag, usage: usage,
+		get: func(c *Config) string { return field(c).String() },
+		set: func(c *Config, v string) error {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				return errors.New("must be a positive duration such as 30s")
+			}
+			*field(c) = d
+			return nil
+		},
+	}
+}
+
+func Default() Config {
+	return Config{
+		Platform:       PlatformProd,
+		Addr:           ":8080",
+		FileServerRoot: ".",
+		ExportDir:      filepath.Join(os.TempDir(), "chirpy-exports"),
+		AuditRetention: 365 * 24 * time.Hour,
+
+		ReadTimeout:       15 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		MaxHeaderBytes:    64 << 10,
+		ShutdownTimeout:   20 * time.Second,
+
+		LogLevels: logging.Levels{Default: slog.LevelInfo},
+
+		ServiceName: "chirpy",
+	}
+}
+
+// Load builds the config from args, which are the command-line arguments
+// without the program name, and getenv. The config file is named by the
+// -config flag or the CONFIG_FILE variable. It returns the arguments left
+// after the flags, and the error from Validate if the config isn't usable.
+// When arguments are left they name a subcommand, which only needs the
+// database, so only ValidateCommand is run.
+func Load(args []string, getenv func(string) string) (Config, []string, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("chirpy", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configFile := fs.String("config", getenv("CONFIG_FILE"), "config file")
+	flagValues := map[string]*string{}
+	for _, f := range fields {
+		if f.flag != "" {
+			flagValues[f.key] = fs.String(f.flag, "", f.usage)
+		}
+	}
+	err := fs.Parse(args)
+	if err != nil {
+		return Config{}, nil, err
+	}
+
+	if *configFile != "" {
+		err = cfg.loadFile(*configFile)
+		if err != nil {
+			return Config{}, nil, err
+		}
+	}
+
+	for _, f := range fields {
+		v := getenv(f.env)
+		// POLKA_KEY is the single secret used before rotation was supported.
+		if v == "" && f.env == "POLKA_WEBHOOK_SECRETS" {
+			v = getenv("POLKA_KEY")
+		}
+		if v == "" {
+			continue
+		}
+		err = f.set(&cfg, v)
+		if err != nil {
+			return Config{}, nil, fmt.Errorf("%s: %w", f.env, err)
+		}
+	}
+
+	fs.Visit(func(fl *flag.Flag) {
+		for _, f := range fields {
+			if f.flag == fl.Name && err == nil {
+				err = f.set(&cfg, *flagValues[f.key])
+				if err != nil {
+					err = fmt.Errorf("-%s: %w", f.flag, err)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return Config{}, nil, err
+	}
+
+	if fs.NArg() > 0 {
+		return cfg, fs.Args(), cfg.ValidateCommand()
+	}
+	return cfg, fs.Args(), cfg.Validate()
+}
+
+// loadFile reads a TOML config file of top-level settings.
+func (c *Config) loadFile(path string) error {
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var values map[string]any
+	md, err := toml.Decode(string(dat), &values)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	// Keys come back in file order, so the first bad setting is reported.
+	for _, k := range md.Keys() {
+		key := k.String()
+		err = c.setFileValue(key, values[key])
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func (c *Config) setFileValue(key string, v any) error {
+	for _, f := range fields {
+		if f.key == key {
+			value, err := fileValue(v)
+			if err == nil {
+				err = f.set(c, value)
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown setting %q", key)
+}
+
+// fileValue turns a decoded TOML value into the same form as its
+// environment variable, so arrays come back comma-separated.
+func fileValue(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return "", errors.New("arrays may only hold strings")
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", errors.New("expected a string, an integer, a boolean or an array of strings")
+	}
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Validate checks that the required settings are present and, outside of
+// dev, that none of them are insecure.
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.DBURL == "" {
+		errs = append(errs, errors.New("DB_URL is required"))
+	}
+	if c.Secret == "" {
+		errs = append(errs, errors.New("SECRET is required"))
+	}
+	if c.Addr == "" {
+		errs = append(errs, errors.New("ADDR is required"))
+	}
+	if c.ExportURLSecret != "" && c.ExportURLSecret == c.Secret {
+		errs = append(errs, errors.New("EXPORT_URL_SECRET must differ from SECRET"))
+	}
+	if c.MetricsAddr != "" && c.MetricsAddr == c.Addr {
+		errs = append(errs, errors.New("METRICS_ADDR must differ from ADDR"))
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs = append(errs, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
+
+	switch c.Platform {
+	case PlatformDev:
+	case PlatformProd:
+		if c.Secret != "" && len(c.Secret) < MinSecretLength {
+			errs = append(errs, fmt.Errorf("SECRET must be at least %d characters in production", MinSecretLength))
+		}
+		if len(c.PolkaWebhookSecrets) == 0 {
+			errs = append(errs, errors.New("POLKA_WEBHOOK_SECRETS is required in production"))
+		}
+		if len(c.ExportURLSecret) < MinSecretLength {
+			errs = append(errs, fmt.Errorf("EXPORT_URL_SECRET must be at least %d characters in production", MinSecretLength))
+		}
+		if strings.Contains(c.DBURL, "sslmode=disable") {
+			errs = append(errs, errors.New("DB_URL must not disable TLS in production"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("PLATFORM must be %q or %q, not %q", PlatformDev, PlatformProd, c.Platform))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateCommand checks the settings subcommands such as migrate and
+// set-role use, which is only the database.
+func (c Config) ValidateCommand() error {
+	if c.DBURL == "" {
+		return errors.New("DB_URL is required")
+	}
+	return nil
+}
+
+// Dump writes the effective config in the config file format with secrets
+// redacted, so it can be logged at startup. The password in a URL-style
+// DB_URL is redacted while the rest is kept, since the host and database
+// are useful when debugging.
+func (c Config) Dump(w io.Writer) error {
+	for _, f := range fields {
+		v := f.get(&c)
+		if f.secret && v != "" {
+			v = redacted
+			if f.key == "db_url" {
+				v = redactURL(c.DBURL)
+			}
+		}
+
+		var err error
+		if f.unquoted {
+			_, err = fmt.Fprintf(w, "%s = %s\n", f.key, v)
+		} else {
+			_, err = fmt.Fprintf(w, "%s = %q\n", f.key, v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" {
+		return redacted
+	}
+	return u.Redacted()
+}
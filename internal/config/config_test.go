@@ -0,0 +1,252 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testSecret          = "0123456789abcdef0123456789abcdef"
+	testExportURLSecret = "fedcba9876543210fedcba9876543210"
+)
+
+func envFrom(vars map[string]string) func(string) string {
+	return func(key string) string {
+		return vars[key]
+	}
+}
+
+func writeFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "chirpy.toml")
+	err := os.WriteFile(path, []byte(contents), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, `
+# Settings for staging
+addr = ":9000"
+export_dir = "/from/file" # overridden by the environment
+polka_webhook_secrets = ["old", "new"]
+audit_retention_days = 30
+write_timeout = "1m"
+max_header_bytes = 4096
+log_level = "warn,http=debug"
+auto_migrate = true
+`)
+	env := envFrom(map[string]string{
+		"DB_URL":     "postgres://chirpy:hunter2@db/chirpy",
+		"SECRET":     testSecret,
+		"EXPORT_DIR": "/from/env",
+		"ADDR":       ":9001",
+	})
+
+	cfg, args, err := Load([]string{"-config", path, "-addr", ":9002", "set-role", "a@b.c", "admin"}, env)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	if cfg.Addr != ":9002" {
+		t.Errorf("Want flag to win, got addr %q", cfg.Addr)
+	}
+	if cfg.ExportDir != "/from/env" {
+		t.Errorf("Want environment to beat the file, got export_dir %q", cfg.ExportDir)
+	}
+	if strings.Join(cfg.PolkaWebhookSecrets, ",") != "old,new" {
+		t.Errorf("Want secrets from the file, got %v", cfg.PolkaWebhookSecrets)
+	}
+	if cfg.AuditRetention != 30*24*time.Hour {
+		t.Errorf("Want 30 days retention, got %v", cfg.AuditRetention)
+	}
+	if cfg.WriteTimeout != time.Minute || cfg.MaxHeaderBytes != 4096 {
+		t.Errorf("Want server limits from the file, got %v and %d", cfg.WriteTimeout, cfg.MaxHeaderBytes)
+	}
+	if cfg.LogLevels.For("http") != slog.LevelDebug || cfg.LogLevels.For("worker") != slog.LevelWarn {
+		t.Errorf("Want log levels from the file, got %v", cfg.LogLevels)
+	}
+	if !cfg.AutoMigrate {
+		t.Errorf("Want auto_migrate from the file")
+	}
+	if cfg.ReadTimeout != Default().ReadTimeout {
+		t.Errorf("Want default read timeout, got %v", cfg.ReadTimeout)
+	}
+	if cfg.FileServerRoot != "." {
+		t.Errorf("Want default fileserver root, got %q", cfg.FileServerRoot)
+	}
+	if strings.Join(args, " ") != "set-role a@b.c admin" {
+		t.Errorf("Want remaining args, got %v", args)
+	}
+}
+
+// TestLoadCommand checks that subcommands such as migrate run with only
+// the database configured.
+func TestLoadCommand(t *testing.T) {
+	env := envFrom(map[string]string{"DB_URL": "postgres://db/chirpy"})
+
+	_, args, err := Load([]string{"migrate", "up"}, env)
+	if err != nil || len(args) != 2 {
+		t.Errorf("Want migrate to need only DB_URL, got %v and %v", args, err)
+	}
+	_, _, err = Load(nil, env)
+	if err == nil {
+		t.Errorf("Want serving to need the full config")
+	}
+	_, _, err = Load([]string{"migrate", "up"}, envFrom(nil))
+	if err == nil {
+		t.Errorf("Want migrate to need DB_URL")
+	}
+}
+
+func TestLoadLegacyPolkaKey(t *testing.T) {
+	cfg, _, err := Load(nil, envFrom(map[string]string{
+		"DB_URL":            "postgres://db/chirpy",
+		"SECRET":            testSecret,
+		"EXPORT_URL_SECRET": testExportURLSecret,
+		"POLKA_KEY":         "legacy",
+	}))
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if len(cfg.PolkaWebhookSecrets) != 1 || cfg.PolkaWebhookSecrets[0] != "legacy" {
+		t.Errorf("Want POLKA_KEY to be used, got %v", cfg.PolkaWebhookSecrets)
+	}
+}
+
+// TestLoadFileSyntax checks TOML syntax beyond one quoted value per line.
+func TestLoadFileSyntax(t *testing.T) {
+	path := writeFile(t, `
+export_dir = 'C:\chirpy\exports'
+polka_webhook_secrets = [
+  "old", # rotated out next week
+  "new",
+]
+fileserver_root = """/srv/chirpy"""
+`)
+
+	cfg, _, err := Load([]string{"-config", path}, envFrom(map[string]string{
+		"DB_URL":            "postgres://db/chirpy",
+		"SECRET":            testSecret,
+		"EXPORT_URL_SECRET": testExportURLSecret,
+	}))
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if cfg.ExportDir != `C:\chirpy\exports` {
+		t.Errorf("Want a literal string, got %q", cfg.ExportDir)
+	}
+	if strings.Join(cfg.PolkaWebhookSecrets, ",") != "old,new" {
+		t.Errorf("Want a multi-line array, got %v", cfg.PolkaWebhookSecrets)
+	}
+	if cfg.FileServerRoot != "/srv/chirpy" {
+		t.Errorf("Want a multi-line string, got %q", cfg.FileServerRoot)
+	}
+}
+
+func TestLoadBadFile(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":    `listen = ":8080"`,
+		"bare word":      `addr = localhost`,
+		"no equals":      `addr`,
+		"mixed array":    `polka_webhook_secrets = ["a", 1]`,
+		"bad duration":   `audit_retention_days = -1`,
+		"bare timeout":   `read_timeout = "30"`,
+		"zero timeout":   `idle_timeout = "0s"`,
+		"negative delay": `shutdown_delay = "-5s"`,
+		"bad log level":  `log_level = "http=loud"`,
+		"bad otlp url":   `otlp_endpoint = "localhost:4318"`,
+		"table":          "[server]\naddr = \":8080\"",
+		"float":          `max_header_bytes = 4096.5`,
+		"duplicate key":  "addr = \":1\"\naddr = \":2\"",
+	}
+	for name, contents := range tests {
+		_, _, err := Load([]string{"-config", writeFile(t, contents)}, envFrom(nil))
+		if err == nil {
+			t.Errorf("%s: want error, got nil", name)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secure := Default()
+	secure.DBURL = "postgres://db/chirpy?sslmode=require"
+	secure.Secret = testSecret
+	secure.PolkaWebhookSecrets = []string{"polka"}
+	secure.ExportURLSecret = testExportURLSecret
+
+	tests := map[string]struct {
+		modify  func(*Config)
+		wantErr bool
+	}{
+		"secure":               {func(c *Config) {}, false},
+		"missing db url":       {func(c *Config) { c.DBURL = "" }, true},
+		"missing secret":       {func(c *Config) { c.Secret = "" }, true},
+		"short secret":         {func(c *Config) { c.Secret = "short" }, true},
+		"no polka":             {func(c *Config) { c.PolkaWebhookSecrets = nil }, true},
+		"no export secret":     {func(c *Config) { c.ExportURLSecret = "" }, true},
+		"export secret reused": {func(c *Config) { c.ExportURLSecret = c.Secret }, true},
+		"tls disabled":         {func(c *Config) { c.DBURL = "postgres://db/chirpy?sslmode=disable" }, true},
+		"unknown platform":     {func(c *Config) { c.Platform = "staging" }, true},
+		"tls":                  {func(c *Config) { c.TLSCertFile, c.TLSKeyFile = "cert.pem", "key.pem" }, false},
+		"tls without key":      {func(c *Config) { c.TLSCertFile = "cert.pem" }, true},
+		"metrics addr":         {func(c *Config) { c.MetricsAddr = "127.0.0.1:9090" }, false},
+		"metrics on addr":      {func(c *Config) { c.MetricsAddr = c.Addr }, true},
+		"insecure in dev": {func(c *Config) {
+			c.Platform = PlatformDev
+			c.Secret = "short"
+			c.PolkaWebhookSecrets = nil
+			c.ExportURLSecret = ""
+			c.DBURL = "postgres://db/chirpy?sslmode=disable"
+		}, false},
+		"missing secret in dev": {func(c *Config) {
+			c.Platform = PlatformDev
+			c.Secret = ""
+		}, true},
+	}
+	for name, tt := range tests {
+		cfg := secure
+		tt.modify(&cfg)
+		err := cfg.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: want error %v, got %v", name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestDumpRedactsSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.DBURL = "postgres://chirpy:hunter2@db/chirpy"
+	cfg.Secret = testSecret
+	cfg.PolkaWebhookSecrets = []string{"polka-secret"}
+	cfg.ExportURLSecret = testExportURLSecret
+
+	var sb strings.Builder
+	err := cfg.Dump(&sb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump := sb.String()
+
+	for _, secret := range []string{"hunter2", testSecret, "polka-secret", testExportURLSecret} {
+		if strings.Contains(dump, secret) {
+			t.Errorf("Dump contains secret %q:\n%s", secret, dump)
+		}
+	}
+	if !strings.Contains(dump, "db/chirpy") {
+		t.Errorf("Want database host in dump, got:\n%s", dump)
+	}
+
+	// The dump is itself a valid config file.
+	path := writeFile(t, dump)
+	_, _, err = Load([]string{"-config", path}, envFrom(nil))
+	if err != nil && strings.Contains(err.Error(), path) {
+		t.Errorf("Dump doesn't parse: %v", err)
+	}
+}
@@ -11,6 +11,20 @@ import (
 	"github.com/google/uuid"
 )
 
+const countChirpsInLastHour = `-- name: CountChirpsInLastHour :one
+SELECT COUNT(*)
+FROM chirps
+WHERE user_id = $1
+    AND created_at > NOW() - interval '1 hour'
+`
+
+func (q *Queries) CountChirpsInLastHour(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countChirpsInLastHour, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createChirp = `-- name: CreateChirp :one
 INSERT INTO chirps (id, created_at, updated_at, body, user_id)
 VALUES (
@@ -54,11 +68,48 @@ func (q *Queries) DeleteChirp(ctx context.Context, id uuid.UUID) error {
 const getAllChirps = `-- name: GetAllChirps :many
 SELECT id, created_at, updated_at, body, user_id 
 FROM chirps 
+WHERE NOT EXISTS (
+        SELECT 1
+        FROM blocks
+        WHERE (blocker_id = $1 AND blocked_id = chirps.user_id)
+            OR (blocker_id = chirps.user_id AND blocked_id = $1)
+    )
+    AND NOT EXISTS (
+        SELECT 1
+        FROM mutes
+        WHERE muter_id = $1 AND muted_id = chirps.user_id
+    )
+    AND (
+        chirps.user_id = $1
+        OR NOT EXISTS (
+            SELECT 1
+            FROM users
+            WHERE users.id = chirps.user_id
+                AND users.protected
+        )
+        OR EXISTS (
+            SELECT 1
+            FROM follows
+            WHERE follower_id = $1
+                AND followee_id = chirps.user_id
+                AND status = 'accepted'
+        )
+    )
+    AND (
+        chirps.user_id = $1
+        OR NOT EXISTS (
+            SELECT 1
+            FROM users
+            WHERE users.id = chirps.user_id
+                AND users.suspension IN ('suspended', 'shadow_limited')
+                AND (users.suspended_until IS NULL OR users.suspended_until > NOW())
+        )
+    )
 ORDER BY created_at
 `
 
-func (q *Queries) GetAllChirps(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getAllChirps)
+func (q *Queries) GetAllChirps(ctx context.Context, viewerID uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getAllChirps, viewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -109,11 +160,48 @@ const getChirpsForUser = `-- name: GetChirpsForUser :many
 SELECT id, created_at, updated_at, body, user_id 
 FROM chirps 
 WHERE user_id = $1
+    AND NOT EXISTS (
+        SELECT 1
+        FROM blocks
+        WHERE (blocker_id = $2 AND blocked_id = chirps.user_id)
+            OR (blocker_id = chirps.user_id AND blocked_id = $2)
+    )
+    AND (
+        chirps.user_id = $2
+        OR NOT EXISTS (
+            SELECT 1
+            FROM users
+            WHERE users.id = chirps.user_id
+                AND users.protected
+        )
+        OR EXISTS (
+            SELECT 1
+            FROM follows
+            WHERE follower_id = $2
+                AND followee_id = chirps.user_id
+                AND status = 'accepted'
+        )
+    )
+    AND (
+        chirps.user_id = $2
+        OR NOT EXISTS (
+            SELECT 1
+            FROM users
+            WHERE users.id = chirps.user_id
+                AND users.suspension IN ('suspended', 'shadow_limited')
+                AND (users.suspended_until IS NULL OR users.suspended_until > NOW())
+        )
+    )
 ORDER BY created_at
 `
 
-func (q *Queries) GetChirpsForUser(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirpsForUser, userID)
+type GetChirpsForUserParams struct {
+	UserID   uuid.UUID `json:"user_id"`
+	ViewerID uuid.UUID `json:"viewer_id"`
+}
+
+func (q *Queries) GetChirpsForUser(ctx context.Context, arg GetChirpsForUserParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsForUser, arg.UserID, arg.ViewerID)
 	if err != nil {
 		return nil, err
 	}
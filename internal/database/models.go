@@ -6,11 +6,45 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type AccountDeletion struct {
+	UserID      uuid.UUID `json:"user_id"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+	DeletedAt   time.Time `json:"deleted_at"`
+}
+
+type AuditEvent struct {
+	ID         int64           `json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Action     string          `json:"action"`
+	ActorID    uuid.NullUUID   `json:"actor_id"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Ip         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+	RequestID  string          `json:"request_id"`
+	Metadata   json.RawMessage `json:"metadata"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+}
+
+type Block struct {
+	BlockerID uuid.UUID `json:"blocker_id"`
+	BlockedID uuid.UUID `json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Bookmark struct {
+	UserID    uuid.UUID `json:"user_id"`
+	ChirpID   uuid.UUID `json:"chirp_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type Chirp struct {
 	ID        uuid.UUID `json:"id"`
 	CreatedAt time.Time `json:"created_at"`
@@ -19,6 +53,72 @@ type Chirp struct {
 	UserID    uuid.UUID `json:"user_id"`
 }
 
+type DataExport struct {
+	ID        uuid.UUID      `json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	UserID    uuid.UUID      `json:"user_id"`
+	Status    string         `json:"status"`
+	FilePath  sql.NullString `json:"file_path"`
+	ExpiresAt sql.NullTime   `json:"expires_at"`
+}
+
+type EntitlementOverride struct {
+	UserID    uuid.UUID       `json:"user_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Overrides json.RawMessage `json:"overrides"`
+}
+
+type Follow struct {
+	FollowerID uuid.UUID `json:"follower_id"`
+	FolloweeID uuid.UUID `json:"followee_id"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type ModerationAction struct {
+	ID           uuid.UUID     `json:"id"`
+	CreatedAt    time.Time     `json:"created_at"`
+	ReportID     uuid.NullUUID `json:"report_id"`
+	ModeratorID  uuid.NullUUID `json:"moderator_id"`
+	Action       string        `json:"action"`
+	Reason       string        `json:"reason"`
+	TargetUserID uuid.UUID     `json:"target_user_id"`
+}
+
+type Mute struct {
+	MuterID   uuid.UUID `json:"muter_id"`
+	MutedID   uuid.UUID `json:"muted_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Notification struct {
+	ID        uuid.UUID    `json:"id"`
+	CreatedAt time.Time    `json:"created_at"`
+	UserID    uuid.UUID    `json:"user_id"`
+	Kind      string       `json:"kind"`
+	Body      string       `json:"body"`
+	ReadAt    sql.NullTime `json:"read_at"`
+}
+
+type OutboxEvent struct {
+	ID           uuid.UUID       `json:"id"`
+	CreatedAt    time.Time       `json:"created_at"`
+	EventType    string          `json:"event_type"`
+	UserID       uuid.NullUUID   `json:"user_id"`
+	Payload      json.RawMessage `json:"payload"`
+	DispatchedAt sql.NullTime    `json:"dispatched_at"`
+	Traceparent  string          `json:"traceparent"`
+}
+
+type PinnedChirp struct {
+	UserID    uuid.UUID `json:"user_id"`
+	ChirpID   uuid.UUID `json:"chirp_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type RefreshToken struct {
 	Token     string       `json:"token"`
 	CreatedAt time.Time    `json:"created_at"`
@@ -28,11 +128,76 @@ type RefreshToken struct {
 	RevokedAt sql.NullTime `json:"revoked_at"`
 }
 
+type Report struct {
+	ID            uuid.UUID     `json:"id"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	ReporterID    uuid.NullUUID `json:"reporter_id"`
+	TargetUserID  uuid.UUID     `json:"target_user_id"`
+	TargetChirpID uuid.NullUUID `json:"target_chirp_id"`
+	Reason        string        `json:"reason"`
+	Details       string        `json:"details"`
+	State         string        `json:"state"`
+}
+
+type Subscription struct {
+	UserID           uuid.UUID    `json:"user_id"`
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+	Plan             string       `json:"plan"`
+	Status           string       `json:"status"`
+	CurrentPeriodEnd time.Time    `json:"current_period_end"`
+	GracePeriodEnd   sql.NullTime `json:"grace_period_end"`
+}
+
 type User struct {
-	ID             uuid.UUID    `json:"id"`
-	CreatedAt      time.Time    `json:"created_at"`
-	UpdatedAt      time.Time    `json:"updated_at"`
-	Email          string       `json:"email"`
-	HashedPassword string       `json:"hashed_password"`
-	IsChirpyRed    sql.NullBool `json:"is_chirpy_red"`
+	ID                  uuid.UUID      `json:"id"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	Email               string         `json:"email"`
+	HashedPassword      string         `json:"hashed_password"`
+	Protected           bool           `json:"protected"`
+	DeletionScheduledAt sql.NullTime   `json:"deletion_scheduled_at"`
+	Role                string         `json:"role"`
+	Suspension          sql.NullString `json:"suspension"`
+	SuspendedUntil      sql.NullTime   `json:"suspended_until"`
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID      `json:"id"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	EndpointID     uuid.UUID      `json:"endpoint_id"`
+	EventID        uuid.UUID      `json:"event_id"`
+	Status         string         `json:"status"`
+	Attempts       int32          `json:"attempts"`
+	NextAttemptAt  time.Time      `json:"next_attempt_at"`
+	LastStatusCode sql.NullInt32  `json:"last_status_code"`
+	LastError      sql.NullString `json:"last_error"`
+	DeliveredAt    sql.NullTime   `json:"delivered_at"`
+}
+
+type WebhookEndpoint struct {
+	ID        uuid.UUID     `json:"id"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	UserID    uuid.NullUUID `json:"user_id"`
+	Url       string        `json:"url"`
+	Secret    string        `json:"secret"`
+	Events    []string      `json:"events"`
+	Active    bool          `json:"active"`
+}
+
+type WebhookEvent struct {
+	ID          uuid.UUID       `json:"id"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Provider    string          `json:"provider"`
+	EventID     string          `json:"event_id"`
+	EventType   string          `json:"event_type"`
+	Status      string          `json:"status"`
+	Attempts    int32           `json:"attempts"`
+	Payload     json.RawMessage `json:"payload"`
+	LastError   sql.NullString  `json:"last_error"`
+	ProcessedAt sql.NullTime    `json:"processed_at"`
 }
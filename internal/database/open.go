@@ -0,0 +1,75 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Dialect is the kind of database a DB_URL points at.
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+// sqlitePragmas are added to every SQLite DSN. Foreign keys are off by
+// default in SQLite, and BEGIN IMMEDIATE makes a transaction take the write
+// lock up front rather than failing when it first writes.
+var sqlitePragmas = url.Values{
+	"_fk":           {"1"},
+	"_busy_timeout": {"5000"},
+	"_txlock":       {"immediate"},
+	"_journal_mode": {"WAL"},
+}
+
+// Open opens the database dbURL names: postgres:// and postgresql:// URLs
+// open Postgres, and sqlite:path/to/file.db opens a SQLite file, with any
+// query parameters passed to the driver. The drivers have to be registered
+// by the caller.
+func Open(dbURL string) (*sql.DB, Dialect, error) {
+	scheme, rest, ok := strings.Cut(dbURL, ":")
+	if !ok {
+		return nil, "", errors.New("DB_URL has no scheme")
+	}
+
+	switch scheme {
+	case "postgres", "postgresql":
+		db, err := sql.Open("postgres", dbURL)
+		return db, Postgres, err
+	case "sqlite":
+		dsn, err := sqliteDSN(rest)
+		if err != nil {
+			return nil, "", err
+		}
+		db, err := sql.Open("sqlite3", dsn)
+		return db, SQLite, err
+	default:
+		return nil, "", fmt.Errorf("DB_URL scheme %q is not postgres or sqlite", scheme)
+	}
+}
+
+// sqliteDSN turns the part of a sqlite: URL after the scheme into a
+// go-sqlite3 DSN. sqlite:///abs/path.db and sqlite:rel/path.db both work.
+func sqliteDSN(rest string) (string, error) {
+	if r, ok := strings.CutPrefix(rest, "//"); ok {
+		rest = r
+	}
+	path, rawQuery, _ := strings.Cut(rest, "?")
+	if path == "" {
+		return "", errors.New("sqlite DB_URL has no file path")
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", fmt.Errorf("sqlite DB_URL: %w", err)
+	}
+	for k, v := range sqlitePragmas {
+		if !query.Has(k) {
+			query[k] = v
+		}
+	}
+	return "file:" + path + "?" + query.Encode(), nil
+}
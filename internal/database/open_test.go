@@ -0,0 +1,31 @@
+package database
+
+import "testing"
+
+func TestSQLiteDSN(t *testing.T) {
+	const pragmas = "_busy_timeout=5000&_fk=1&_journal_mode=WAL&_txlock=immediate"
+	tests := map[string]string{
+		"/var/lib/chirpy.db":          "file:/var/lib/chirpy.db?" + pragmas,
+		"///var/lib/chirpy.db":        "file:/var/lib/chirpy.db?" + pragmas,
+		"chirpy.db":                   "file:chirpy.db?" + pragmas,
+		"chirpy.db?mode=ro":           "file:chirpy.db?" + pragmas + "&mode=ro",
+		"chirpy.db?_busy_timeout=100": "file:chirpy.db?_busy_timeout=100&_fk=1&_journal_mode=WAL&_txlock=immediate",
+	}
+	for rest, want := range tests {
+		dsn, err := sqliteDSN(rest)
+		if err != nil {
+			t.Errorf("%s: sqliteDSN returned error: %v", rest, err)
+		} else if dsn != want {
+			t.Errorf("%s: want %s, got %s", rest, want, dsn)
+		}
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	for _, dbURL := range []string{"", "mysql://localhost/chirpy", "sqlite:", "sqlite://?_fk=1"} {
+		_, _, err := Open(dbURL)
+		if err == nil {
+			t.Errorf("%q: want error, got nil", dbURL)
+		}
+	}
+}
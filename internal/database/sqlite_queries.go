@@ -0,0 +1,967 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database/sqlite"
+)
+
+// Deadlines the Postgres queries compute with intervals.
+const (
+	refreshTokenLifetime = 60 * 24 * time.Hour
+	deletionGracePeriod  = 30 * 24 * time.Hour
+	dataExportLifetime   = 7 * 24 * time.Hour
+	deliveryLease        = 5 * time.Minute
+	dataExportLease      = 15 * time.Minute
+)
+
+var _ Querier = (*sqliteQueries)(nil)
+
+func (s *sqliteQueries) AcceptAllFollowRequests(ctx context.Context, followeeID uuid.UUID) ([]uuid.UUID, error) {
+	followerIDs, err := s.q.GetPendingFollowerIDs(ctx, followeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	var accepted []uuid.UUID
+	for _, followerID := range followerIDs {
+		n, err := s.q.AcceptFollowRequest(ctx, sqlite.AcceptFollowRequestParams{
+			Now:        now,
+			FollowerID: followerID,
+			FolloweeID: followeeID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if n > 0 {
+			accepted = append(accepted, followerID)
+		}
+	}
+	return accepted, nil
+}
+
+func (s *sqliteQueries) AcceptFollowRequest(ctx context.Context, arg AcceptFollowRequestParams) (int64, error) {
+	return s.q.AcceptFollowRequest(ctx, sqlite.AcceptFollowRequestParams{
+		Now:        s.now(),
+		FollowerID: arg.FollowerID,
+		FolloweeID: arg.FolloweeID,
+	})
+}
+
+func (s *sqliteQueries) ActivateSubscription(ctx context.Context, arg ActivateSubscriptionParams) error {
+	now := s.now()
+	periodEnd := now.AddDate(0, 1, 0)
+	if arg.CurrentPeriodEnd.Valid {
+		periodEnd = utc(arg.CurrentPeriodEnd.Time)
+	}
+	return s.q.ActivateSubscription(ctx, sqlite.ActivateSubscriptionParams{
+		UserID:           arg.UserID,
+		Now:              now,
+		Plan:             arg.Plan,
+		CurrentPeriodEnd: periodEnd,
+	})
+}
+
+func (s *sqliteQueries) AllowAuditPrune(ctx context.Context) error {
+	return s.q.AllowAuditPrune(ctx)
+}
+
+func (s *sqliteQueries) CanViewUser(ctx context.Context, arg CanViewUserParams) (bool, error) {
+	return s.q.CanViewUser(ctx, sqlite.CanViewUserParams{ViewerID: arg.ViewerID, Now: s.nullNow(), UserID: arg.UserID})
+}
+
+func (s *sqliteQueries) CancelSubscription(ctx context.Context, userID uuid.UUID) error {
+	return s.q.CancelSubscription(ctx, sqlite.CancelSubscriptionParams{Now: s.now(), UserID: userID})
+}
+
+func (s *sqliteQueries) CancelUserDeletion(ctx context.Context, id uuid.UUID) error {
+	return s.q.CancelUserDeletion(ctx, sqlite.CancelUserDeletionParams{Now: s.now(), ID: id})
+}
+
+// ClaimAbandonedDataExports claims the exports one at a time with an
+// update that only succeeds if they're still abandoned, so an export
+// another worker claimed in between is skipped.
+func (s *sqliteQueries) ClaimAbandonedDataExports(ctx context.Context, limit int32) ([]DataExport, error) {
+	now := s.now()
+	claimedBefore := now.Add(-dataExportLease)
+	rows, err := s.q.GetAbandonedDataExports(ctx, sqlite.GetAbandonedDataExportsParams{
+		ClaimedBefore: claimedBefore,
+		Limit:         int64(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var claimed []DataExport
+	for _, e := range rows {
+		n, err := s.q.ClaimDataExport(ctx, sqlite.ClaimDataExportParams{Now: now, ID: e.ID, ClaimedBefore: claimedBefore})
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			continue
+		}
+		e.UpdatedAt = now
+		claimed = append(claimed, DataExport(e))
+	}
+	return claimed, nil
+}
+
+// ClaimDueWebhookDeliveries claims the deliveries like
+// ClaimAbandonedDataExports does.
+func (s *sqliteQueries) ClaimDueWebhookDeliveries(ctx context.Context, limit int32) ([]WebhookDelivery, error) {
+	now := s.now()
+	leaseUntil := now.Add(deliveryLease)
+	rows, err := s.q.GetDueWebhookDeliveries(ctx, sqlite.GetDueWebhookDeliveriesParams{Now: now, Limit: int64(limit)})
+	if err != nil {
+		return nil, err
+	}
+
+	var claimed []WebhookDelivery
+	for _, d := range rows {
+		n, err := s.q.ClaimWebhookDelivery(ctx, sqlite.ClaimWebhookDeliveryParams{Now: now, LeaseUntil: leaseUntil, ID: d.ID})
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			continue
+		}
+		d.UpdatedAt = now
+		d.NextAttemptAt = leaseUntil
+		claimed = append(claimed, webhookDeliveryFromSQLite(d))
+	}
+	return claimed, nil
+}
+
+func (s *sqliteQueries) CloseReport(ctx context.Context, arg CloseReportParams) (int64, error) {
+	return s.q.CloseReport(ctx, sqlite.CloseReportParams{Now: s.now(), State: arg.State, ID: arg.ID})
+}
+
+func (s *sqliteQueries) CompleteDataExport(ctx context.Context, arg CompleteDataExportParams) error {
+	now := s.now()
+	return s.q.CompleteDataExport(ctx, sqlite.CompleteDataExportParams{
+		Now:       now,
+		FilePath:  arg.FilePath,
+		ExpiresAt: sql.NullTime{Time: now.Add(dataExportLifetime), Valid: true},
+		ID:        arg.ID,
+	})
+}
+
+func (s *sqliteQueries) CountChirpsInLastHour(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.q.CountChirpsInLastHour(ctx, sqlite.CountChirpsInLastHourParams{UserID: userID, Since: s.now().Add(-time.Hour)})
+}
+
+func (s *sqliteQueries) CreateAccountDeletion(ctx context.Context, arg CreateAccountDeletionParams) error {
+	return s.q.CreateAccountDeletion(ctx, sqlite.CreateAccountDeletionParams{
+		UserID:      arg.UserID,
+		ScheduledAt: utc(arg.ScheduledAt),
+		Now:         s.now(),
+	})
+}
+
+func (s *sqliteQueries) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error) {
+	arg.CreatedAt = utc(arg.CreatedAt)
+	id, err := s.q.CreateAuditEvent(ctx, sqlite.CreateAuditEventParams(arg))
+	if err != nil {
+		return AuditEvent{}, err
+	}
+	e, err := s.q.GetAuditEvent(ctx, id)
+	return AuditEvent(e), err
+}
+
+func (s *sqliteQueries) CreateBlock(ctx context.Context, arg CreateBlockParams) error {
+	return s.q.CreateBlock(ctx, sqlite.CreateBlockParams{BlockerID: arg.BlockerID, BlockedID: arg.BlockedID, Now: s.now()})
+}
+
+func (s *sqliteQueries) CreateBookmark(ctx context.Context, arg CreateBookmarkParams) error {
+	return s.q.CreateBookmark(ctx, sqlite.CreateBookmarkParams{UserID: arg.UserID, ChirpID: arg.ChirpID, Now: s.now()})
+}
+
+func (s *sqliteQueries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
+	id := uuid.New()
+	err := s.q.CreateChirp(ctx, sqlite.CreateChirpParams{
+		ID:     id,
+		Now:    s.now(),
+		Body:   arg.Body,
+		UserID: arg.UserID,
+	})
+	if err != nil {
+		return Chirp{}, err
+	}
+	return s.GetChirp(ctx, id)
+}
+
+func (s *sqliteQueries) CreateDataExport(ctx context.Context, userID uuid.UUID) (DataExport, error) {
+	id := uuid.New()
+	n, err := s.q.CreateDataExport(ctx, sqlite.CreateDataExportParams{ID: id, Now: s.now(), UserID: userID})
+	if err != nil {
+		return DataExport{}, err
+	}
+	// Like the Postgres query, a user with a pending export gets no row.
+	if n == 0 {
+		return DataExport{}, sql.ErrNoRows
+	}
+	return s.GetDataExport(ctx, id)
+}
+
+func (s *sqliteQueries) CreateFollow(ctx context.Context, arg CreateFollowParams) (int64, error) {
+	return s.q.CreateFollow(ctx, sqlite.CreateFollowParams{
+		FollowerID: arg.FollowerID,
+		FolloweeID: arg.FolloweeID,
+		Status:     arg.Status,
+		Now:        s.now(),
+	})
+}
+
+func (s *sqliteQueries) CreateModerationAction(ctx context.Context, arg CreateModerationActionParams) (ModerationAction, error) {
+	id := uuid.New()
+	err := s.q.CreateModerationAction(ctx, sqlite.CreateModerationActionParams{
+		ID:           id,
+		Now:          s.now(),
+		ReportID:     arg.ReportID,
+		TargetUserID: arg.TargetUserID,
+		ModeratorID:  arg.ModeratorID,
+		Action:       arg.Action,
+		Reason:       arg.Reason,
+	})
+	if err != nil {
+		return ModerationAction{}, err
+	}
+	a, err := s.q.GetModerationAction(ctx, id)
+	return ModerationAction(a), err
+}
+
+func (s *sqliteQueries) CreateMute(ctx context.Context, arg CreateMuteParams) error {
+	return s.q.CreateMute(ctx, sqlite.CreateMuteParams{MuterID: arg.MuterID, MutedID: arg.MutedID, Now: s.now()})
+}
+
+func (s *sqliteQueries) CreateNotification(ctx context.Context, arg CreateNotificationParams) error {
+	return s.q.CreateNotification(ctx, sqlite.CreateNotificationParams{
+		ID:     uuid.New(),
+		Now:    s.now(),
+		UserID: arg.UserID,
+		Kind:   arg.Kind,
+		Body:   arg.Body,
+	})
+}
+
+func (s *sqliteQueries) CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (OutboxEvent, error) {
+	id := uuid.New()
+	err := s.q.CreateOutboxEvent(ctx, sqlite.CreateOutboxEventParams{
+		ID:          id,
+		Now:         s.now(),
+		EventType:   arg.EventType,
+		UserID:      arg.UserID,
+		Payload:     arg.Payload,
+		Traceparent: arg.Traceparent,
+	})
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+	return s.GetOutboxEvent(ctx, id)
+}
+
+func (s *sqliteQueries) CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error) {
+	now := s.now()
+	err := s.q.CreateRefreshToken(ctx, sqlite.CreateRefreshTokenParams{
+		Token:     arg.Token,
+		Now:       now,
+		UserID:    arg.UserID,
+		ExpiresAt: now.Add(refreshTokenLifetime),
+	})
+	if err != nil {
+		return RefreshToken{}, err
+	}
+	t, err := s.q.GetRefreshToken(ctx, arg.Token)
+	return RefreshToken(t), err
+}
+
+func (s *sqliteQueries) CreateReport(ctx context.Context, arg CreateReportParams) (Report, error) {
+	id := uuid.New()
+	err := s.q.CreateReport(ctx, sqlite.CreateReportParams{
+		ID:            id,
+		Now:           s.now(),
+		ReporterID:    arg.ReporterID,
+		TargetUserID:  arg.TargetUserID,
+		TargetChirpID: arg.TargetChirpID,
+		Reason:        arg.Reason,
+		Details:       arg.Details,
+	})
+	if err != nil {
+		return Report{}, err
+	}
+	return s.GetReport(ctx, id)
+}
+
+func (s *sqliteQueries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error) {
+	id := uuid.New()
+	err := s.q.CreateUser(ctx, sqlite.CreateUserParams{
+		ID:             id,
+		Now:            s.now(),
+		Email:          arg.Email,
+		HashedPassword: arg.HashedPassword,
+	})
+	if err != nil {
+		return CreateUserRow{}, err
+	}
+	u, err := s.q.GetUser(ctx, id)
+	return CreateUserRow{ID: u.ID, CreatedAt: u.CreatedAt, UpdatedAt: u.UpdatedAt, Email: u.Email}, err
+}
+
+func (s *sqliteQueries) CreateWebhookDeliveriesForEvent(ctx context.Context, arg CreateWebhookDeliveriesForEventParams) error {
+	return s.q.CreateWebhookDeliveriesForEvent(ctx, sqlite.CreateWebhookDeliveriesForEventParams{
+		Now:       s.now(),
+		EventID:   arg.EventID,
+		EventType: arg.EventType,
+		UserID:    arg.UserID,
+	})
+}
+
+func (s *sqliteQueries) CreateWebhookEndpoint(ctx context.Context, arg CreateWebhookEndpointParams) (WebhookEndpoint, error) {
+	events := arg.Events
+	if events == nil {
+		events = []string{}
+	}
+	dat, err := json.Marshal(events)
+	if err != nil {
+		return WebhookEndpoint{}, err
+	}
+	id := uuid.New()
+	err = s.q.CreateWebhookEndpoint(ctx, sqlite.CreateWebhookEndpointParams{
+		ID:     id,
+		Now:    s.now(),
+		UserID: arg.UserID,
+		Url:    arg.Url,
+		Secret: arg.Secret,
+		Events: dat,
+	})
+	if err != nil {
+		return WebhookEndpoint{}, err
+	}
+	return s.GetWebhookEndpoint(ctx, id)
+}
+
+func (s *sqliteQueries) CreateWebhookEvent(ctx context.Context, arg CreateWebhookEventParams) (WebhookEvent, error) {
+	id := uuid.New()
+	n, err := s.q.CreateWebhookEvent(ctx, sqlite.CreateWebhookEventParams{
+		ID:        id,
+		Now:       s.now(),
+		Provider:  arg.Provider,
+		EventID:   arg.EventID,
+		EventType: arg.EventType,
+		Payload:   arg.Payload,
+	})
+	if err != nil {
+		return WebhookEvent{}, err
+	}
+	// Like the Postgres query, a duplicate returns no row.
+	if n == 0 {
+		return WebhookEvent{}, sql.ErrNoRows
+	}
+	return s.GetWebhookEvent(ctx, id)
+}
+
+func (s *sqliteQueries) DeleteAuditEventsBefore(ctx context.Context, createdAt time.Time) (int64, error) {
+	return s.q.DeleteAuditEventsBefore(ctx, utc(createdAt))
+}
+
+func (s *sqliteQueries) DeleteBlock(ctx context.Context, arg DeleteBlockParams) error {
+	return s.q.DeleteBlock(ctx, sqlite.DeleteBlockParams(arg))
+}
+
+func (s *sqliteQueries) DeleteBookmark(ctx context.Context, arg DeleteBookmarkParams) error {
+	return s.q.DeleteBookmark(ctx, sqlite.DeleteBookmarkParams(arg))
+}
+
+func (s *sqliteQueries) DeleteChirp(ctx context.Context, id uuid.UUID) error {
+	return s.q.DeleteChirp(ctx, id)
+}
+
+func (s *sqliteQueries) DeleteEntitlementOverride(ctx context.Context, userID uuid.UUID) error {
+	return s.q.DeleteEntitlementOverride(ctx, userID)
+}
+
+func (s *sqliteQueries) DeleteExpiredDataExports(ctx context.Context) ([]sql.NullString, error) {
+	now := s.nullNow()
+	rows, err := s.q.GetExpiredDataExports(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []sql.NullString
+	for _, e := range rows {
+		n, err := s.q.DeleteExpiredDataExport(ctx, sqlite.DeleteExpiredDataExportParams{ID: e.ID, Now: now})
+		if err != nil {
+			return nil, err
+		}
+		if n > 0 {
+			files = append(files, e.FilePath)
+		}
+	}
+	return files, nil
+}
+
+func (s *sqliteQueries) DeleteFollow(ctx context.Context, arg DeleteFollowParams) error {
+	return s.q.DeleteFollow(ctx, sqlite.DeleteFollowParams(arg))
+}
+
+func (s *sqliteQueries) DeleteFollowsBetween(ctx context.Context, arg DeleteFollowsBetweenParams) error {
+	return s.q.DeleteFollowsBetween(ctx, sqlite.DeleteFollowsBetweenParams(arg))
+}
+
+func (s *sqliteQueries) DeleteMute(ctx context.Context, arg DeleteMuteParams) error {
+	return s.q.DeleteMute(ctx, sqlite.DeleteMuteParams(arg))
+}
+
+func (s *sqliteQueries) DeleteUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	return s.q.DeleteUser(ctx, sqlite.DeleteUserParams{ID: id, Now: s.nullNow()})
+}
+
+func (s *sqliteQueries) DeleteWebhookEndpoint(ctx context.Context, id uuid.UUID) error {
+	return s.q.DeleteWebhookEndpoint(ctx, id)
+}
+
+func (s *sqliteQueries) ExpireSubscription(ctx context.Context, userID uuid.UUID) error {
+	return s.q.ExpireSubscription(ctx, sqlite.ExpireSubscriptionParams{Now: s.now(), UserID: userID})
+}
+
+func (s *sqliteQueries) ExpireSubscriptions(ctx context.Context) (int64, error) {
+	return s.q.ExpireSubscriptions(ctx, s.now())
+}
+
+func (s *sqliteQueries) FailDataExport(ctx context.Context, id uuid.UUID) error {
+	return s.q.FailDataExport(ctx, sqlite.FailDataExportParams{Now: s.now(), ID: id})
+}
+
+func (s *sqliteQueries) GetActiveSuspension(ctx context.Context, id uuid.UUID) (sql.NullString, error) {
+	return s.q.GetActiveSuspension(ctx, sqlite.GetActiveSuspensionParams{Now: s.nullNow(), ID: id})
+}
+
+func (s *sqliteQueries) GetAllChirps(ctx context.Context, viewerID uuid.UUID) ([]Chirp, error) {
+	rows, err := s.q.GetAllChirps(ctx, sqlite.GetAllChirpsParams{ViewerID: viewerID, Now: s.nullNow()})
+	return mapRows(rows, chirpFromSQLite), err
+}
+
+func (s *sqliteQueries) GetAuditEvents(ctx context.Context, arg GetAuditEventsParams) ([]AuditEvent, error) {
+	rows, err := s.q.GetAuditEvents(ctx, sqlite.GetAuditEventsParams{
+		ActorID:  arg.ActorID,
+		Action:   arg.Action,
+		TargetID: arg.TargetID,
+		Since:    nullUTC(arg.Since),
+		Until:    nullUTC(arg.Until),
+		Off:      int64(arg.Off),
+		Lim:      int64(arg.Lim),
+	})
+	return mapRows(rows, auditEventFromSQLite), err
+}
+
+func (s *sqliteQueries) GetAuditEventsAfter(ctx context.Context, arg GetAuditEventsAfterParams) ([]AuditEvent, error) {
+	rows, err := s.q.GetAuditEventsAfter(ctx, sqlite.GetAuditEventsAfterParams{ID: arg.ID, Limit: int64(arg.Limit)})
+	return mapRows(rows, auditEventFromSQLite), err
+}
+
+func (s *sqliteQueries) GetBlocks(ctx context.Context, arg GetBlocksParams) ([]Block, error) {
+	rows, err := s.q.GetBlocks(ctx, sqlite.GetBlocksParams{
+		BlockerID: arg.BlockerID,
+		Limit:     int64(arg.Limit),
+		Offset:    int64(arg.Offset),
+	})
+	return mapRows(rows, func(b sqlite.Block) Block { return Block(b) }), err
+}
+
+func (s *sqliteQueries) GetBookmarkedChirpIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	return s.q.GetBookmarkedChirpIDs(ctx, userID)
+}
+
+func (s *sqliteQueries) GetBookmarkedChirps(ctx context.Context, arg GetBookmarkedChirpsParams) ([]Chirp, error) {
+	rows, err := s.q.GetBookmarkedChirps(ctx, sqlite.GetBookmarkedChirpsParams{
+		UserID: arg.UserID,
+		Now:    s.nullNow(),
+		Offset: int64(arg.Offset),
+		Limit:  int64(arg.Limit),
+	})
+	return mapRows(rows, chirpFromSQLite), err
+}
+
+func (s *sqliteQueries) GetBookmarksForExport(ctx context.Context, arg GetBookmarksForExportParams) ([]Bookmark, error) {
+	rows, err := s.q.GetBookmarksForExport(ctx, sqlite.GetBookmarksForExportParams{
+		UserID:         arg.UserID,
+		AfterCreatedAt: utc(arg.AfterCreatedAt),
+		AfterChirpID:   arg.AfterChirpID,
+		BatchSize:      int64(arg.BatchSize),
+	})
+	return mapRows(rows, func(b sqlite.Bookmark) Bookmark { return Bookmark(b) }), err
+}
+
+func (s *sqliteQueries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	c, err := s.q.GetChirp(ctx, id)
+	return Chirp(c), err
+}
+
+func (s *sqliteQueries) GetChirpsForExport(ctx context.Context, arg GetChirpsForExportParams) ([]Chirp, error) {
+	rows, err := s.q.GetChirpsForExport(ctx, sqlite.GetChirpsForExportParams{
+		UserID:         arg.UserID,
+		AfterCreatedAt: utc(arg.AfterCreatedAt),
+		AfterID:        arg.AfterID,
+		BatchSize:      int64(arg.BatchSize),
+	})
+	return mapRows(rows, chirpFromSQLite), err
+}
+
+func (s *sqliteQueries) GetChirpsForUser(ctx context.Context, arg GetChirpsForUserParams) ([]Chirp, error) {
+	rows, err := s.q.GetChirpsForUser(ctx, sqlite.GetChirpsForUserParams{
+		UserID:   arg.UserID,
+		ViewerID: arg.ViewerID,
+		Now:      s.nullNow(),
+	})
+	return mapRows(rows, chirpFromSQLite), err
+}
+
+func (s *sqliteQueries) GetDataExport(ctx context.Context, id uuid.UUID) (DataExport, error) {
+	e, err := s.q.GetDataExport(ctx, id)
+	return DataExport(e), err
+}
+
+func (s *sqliteQueries) GetDataExportFilesForUser(ctx context.Context, userID uuid.UUID) ([]sql.NullString, error) {
+	return s.q.GetDataExportFilesForUser(ctx, userID)
+}
+
+func (s *sqliteQueries) GetEntitlementOverride(ctx context.Context, userID uuid.UUID) (json.RawMessage, error) {
+	return s.q.GetEntitlementOverride(ctx, userID)
+}
+
+func (s *sqliteQueries) GetFirstAuditPrevHash(ctx context.Context) (string, error) {
+	return s.q.GetFirstAuditPrevHash(ctx)
+}
+
+func (s *sqliteQueries) GetFollow(ctx context.Context, arg GetFollowParams) (Follow, error) {
+	f, err := s.q.GetFollow(ctx, sqlite.GetFollowParams(arg))
+	return Follow(f), err
+}
+
+func (s *sqliteQueries) GetFollowRequests(ctx context.Context, arg GetFollowRequestsParams) ([]Follow, error) {
+	rows, err := s.q.GetFollowRequests(ctx, sqlite.GetFollowRequestsParams{
+		FolloweeID: arg.FolloweeID,
+		Limit:      int64(arg.Limit),
+		Offset:     int64(arg.Offset),
+	})
+	return mapRows(rows, followFromSQLite), err
+}
+
+func (s *sqliteQueries) GetFollowsForExport(ctx context.Context, followerID uuid.UUID) ([]Follow, error) {
+	rows, err := s.q.GetFollowsForExport(ctx, followerID)
+	return mapRows(rows, followFromSQLite), err
+}
+
+func (s *sqliteQueries) GetGlobalWebhookEndpoints(ctx context.Context) ([]WebhookEndpoint, error) {
+	rows, err := s.q.GetGlobalWebhookEndpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return webhookEndpointsFromSQLite(rows)
+}
+
+func (s *sqliteQueries) GetLastAuditHash(ctx context.Context) (string, error) {
+	return s.q.GetLastAuditHash(ctx)
+}
+
+func (s *sqliteQueries) GetLatestAuditPrune(ctx context.Context) (AuditEvent, error) {
+	e, err := s.q.GetLatestAuditPrune(ctx)
+	return AuditEvent(e), err
+}
+
+func (s *sqliteQueries) GetModerationActionsForReport(ctx context.Context, reportID uuid.NullUUID) ([]ModerationAction, error) {
+	rows, err := s.q.GetModerationActionsForReport(ctx, reportID)
+	return mapRows(rows, func(a sqlite.ModerationAction) ModerationAction { return ModerationAction(a) }), err
+}
+
+func (s *sqliteQueries) GetMutes(ctx context.Context, arg GetMutesParams) ([]Mute, error) {
+	rows, err := s.q.GetMutes(ctx, sqlite.GetMutesParams{
+		MuterID: arg.MuterID,
+		Limit:   int64(arg.Limit),
+		Offset:  int64(arg.Offset),
+	})
+	return mapRows(rows, func(m sqlite.Mute) Mute { return Mute(m) }), err
+}
+
+func (s *sqliteQueries) GetNotificationsForUser(ctx context.Context, arg GetNotificationsForUserParams) ([]Notification, error) {
+	rows, err := s.q.GetNotificationsForUser(ctx, sqlite.GetNotificationsForUserParams{
+		UserID: arg.UserID,
+		Limit:  int64(arg.Limit),
+		Offset: int64(arg.Offset),
+	})
+	return mapRows(rows, func(n sqlite.Notification) Notification { return Notification(n) }), err
+}
+
+func (s *sqliteQueries) GetOutboxEvent(ctx context.Context, id uuid.UUID) (OutboxEvent, error) {
+	e, err := s.q.GetOutboxEvent(ctx, id)
+	return OutboxEvent(e), err
+}
+
+func (s *sqliteQueries) GetPinnedChirpIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	return s.q.GetPinnedChirpIDs(ctx, userID)
+}
+
+func (s *sqliteQueries) GetRefreshTokensForExport(ctx context.Context, userID uuid.UUID) ([]GetRefreshTokensForExportRow, error) {
+	rows, err := s.q.GetRefreshTokensForExport(ctx, userID)
+	return mapRows(rows, func(t sqlite.GetRefreshTokensForExportRow) GetRefreshTokensForExportRow {
+		return GetRefreshTokensForExportRow(t)
+	}), err
+}
+
+func (s *sqliteQueries) GetReport(ctx context.Context, id uuid.UUID) (Report, error) {
+	r, err := s.q.GetReport(ctx, id)
+	return Report(r), err
+}
+
+func (s *sqliteQueries) GetReports(ctx context.Context, arg GetReportsParams) ([]Report, error) {
+	rows, err := s.q.GetReports(ctx, sqlite.GetReportsParams{Limit: int64(arg.Limit), Offset: int64(arg.Offset)})
+	return mapRows(rows, reportFromSQLite), err
+}
+
+func (s *sqliteQueries) GetReportsByState(ctx context.Context, arg GetReportsByStateParams) ([]Report, error) {
+	rows, err := s.q.GetReportsByState(ctx, sqlite.GetReportsByStateParams{
+		State:  arg.State,
+		Limit:  int64(arg.Limit),
+		Offset: int64(arg.Offset),
+	})
+	return mapRows(rows, reportFromSQLite), err
+}
+
+func (s *sqliteQueries) GetSubscription(ctx context.Context, userID uuid.UUID) (Subscription, error) {
+	sub, err := s.q.GetSubscription(ctx, userID)
+	return Subscription(sub), err
+}
+
+func (s *sqliteQueries) GetUndispatchedOutboxEvents(ctx context.Context, limit int32) ([]OutboxEvent, error) {
+	rows, err := s.q.GetUndispatchedOutboxEvents(ctx, int64(limit))
+	return mapRows(rows, func(e sqlite.OutboxEvent) OutboxEvent { return OutboxEvent(e) }), err
+}
+
+func (s *sqliteQueries) GetUser(ctx context.Context, id uuid.UUID) (User, error) {
+	u, err := s.q.GetUser(ctx, id)
+	return User(u), err
+}
+
+func (s *sqliteQueries) GetUserFromRefreshToken(ctx context.Context, token string) (uuid.UUID, error) {
+	return s.q.GetUserFromRefreshToken(ctx, sqlite.GetUserFromRefreshTokenParams{Now: s.now(), Token: token})
+}
+
+func (s *sqliteQueries) GetUserRole(ctx context.Context, id uuid.UUID) (string, error) {
+	return s.q.GetUserRole(ctx, id)
+}
+
+func (s *sqliteQueries) GetUserWithEmail(ctx context.Context, email string) (User, error) {
+	u, err := s.q.GetUserWithEmail(ctx, email)
+	return User(u), err
+}
+
+func (s *sqliteQueries) GetUsersDueForDeletion(ctx context.Context) ([]GetUsersDueForDeletionRow, error) {
+	rows, err := s.q.GetUsersDueForDeletion(ctx, s.nullNow())
+	return mapRows(rows, func(u sqlite.GetUsersDueForDeletionRow) GetUsersDueForDeletionRow {
+		return GetUsersDueForDeletionRow(u)
+	}), err
+}
+
+func (s *sqliteQueries) GetWebhookDeliveriesForEndpoint(ctx context.Context, arg GetWebhookDeliveriesForEndpointParams) ([]WebhookDelivery, error) {
+	rows, err := s.q.GetWebhookDeliveriesForEndpoint(ctx, sqlite.GetWebhookDeliveriesForEndpointParams{
+		EndpointID: arg.EndpointID,
+		Limit:      int64(arg.Limit),
+		Offset:     int64(arg.Offset),
+	})
+	return mapRows(rows, webhookDeliveryFromSQLite), err
+}
+
+func (s *sqliteQueries) GetWebhookEndpoint(ctx context.Context, id uuid.UUID) (WebhookEndpoint, error) {
+	e, err := s.q.GetWebhookEndpoint(ctx, id)
+	if err != nil {
+		return WebhookEndpoint{}, err
+	}
+	return webhookEndpointFromSQLite(e)
+}
+
+func (s *sqliteQueries) GetWebhookEndpointsForUser(ctx context.Context, userID uuid.NullUUID) ([]WebhookEndpoint, error) {
+	rows, err := s.q.GetWebhookEndpointsForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return webhookEndpointsFromSQLite(rows)
+}
+
+func (s *sqliteQueries) GetWebhookEvent(ctx context.Context, id uuid.UUID) (WebhookEvent, error) {
+	e, err := s.q.GetWebhookEvent(ctx, id)
+	return webhookEventFromSQLite(e), err
+}
+
+func (s *sqliteQueries) GetWebhookEventByEventID(ctx context.Context, arg GetWebhookEventByEventIDParams) (WebhookEvent, error) {
+	e, err := s.q.GetWebhookEventByEventID(ctx, sqlite.GetWebhookEventByEventIDParams(arg))
+	return webhookEventFromSQLite(e), err
+}
+
+func (s *sqliteQueries) GetWebhookEvents(ctx context.Context, arg GetWebhookEventsParams) ([]WebhookEvent, error) {
+	rows, err := s.q.GetWebhookEvents(ctx, sqlite.GetWebhookEventsParams{Limit: int64(arg.Limit), Offset: int64(arg.Offset)})
+	return mapRows(rows, webhookEventFromSQLite), err
+}
+
+func (s *sqliteQueries) GetWebhookEventsByStatus(ctx context.Context, arg GetWebhookEventsByStatusParams) ([]WebhookEvent, error) {
+	rows, err := s.q.GetWebhookEventsByStatus(ctx, sqlite.GetWebhookEventsByStatusParams{
+		Status: arg.Status,
+		Limit:  int64(arg.Limit),
+		Offset: int64(arg.Offset),
+	})
+	return mapRows(rows, webhookEventFromSQLite), err
+}
+
+func (s *sqliteQueries) IsBlocked(ctx context.Context, arg IsBlockedParams) (bool, error) {
+	return s.q.IsBlocked(ctx, sqlite.IsBlockedParams(arg))
+}
+
+func (s *sqliteQueries) IsChirpyRed(ctx context.Context, userID uuid.UUID) (bool, error) {
+	return s.q.IsChirpyRed(ctx, userID)
+}
+
+func (s *sqliteQueries) LiftSuspension(ctx context.Context, id uuid.UUID) (int64, error) {
+	return s.q.LiftSuspension(ctx, sqlite.LiftSuspensionParams{Now: s.now(), ID: id})
+}
+
+// LockAuditChain does nothing: transactions are opened with
+// BEGIN IMMEDIATE, so a second writer already waits for the first.
+func (s *sqliteQueries) LockAuditChain(ctx context.Context) error {
+	return nil
+}
+
+func (s *sqliteQueries) MarkNotificationsRead(ctx context.Context, userID uuid.UUID) error {
+	return s.q.MarkNotificationsRead(ctx, sqlite.MarkNotificationsReadParams{Now: s.nullNow(), UserID: userID})
+}
+
+func (s *sqliteQueries) MarkOutboxEventDispatched(ctx context.Context, id uuid.UUID) error {
+	return s.q.MarkOutboxEventDispatched(ctx, sqlite.MarkOutboxEventDispatchedParams{Now: s.nullNow(), ID: id})
+}
+
+func (s *sqliteQueries) MarkSubscriptionPastDue(ctx context.Context, userID uuid.UUID) error {
+	return s.q.MarkSubscriptionPastDue(ctx, sqlite.MarkSubscriptionPastDueParams{Now: s.now(), UserID: userID})
+}
+
+func (s *sqliteQueries) MarkWebhookDeliveryFailed(ctx context.Context, arg MarkWebhookDeliveryFailedParams) error {
+	now := s.now()
+	backoff := time.Duration(arg.BackoffSeconds * float64(time.Second))
+	return s.q.MarkWebhookDeliveryFailed(ctx, sqlite.MarkWebhookDeliveryFailedParams{
+		Now:            now,
+		Status:         arg.Status,
+		LastStatusCode: nullInt64(arg.LastStatusCode),
+		LastError:      arg.LastError,
+		NextAttemptAt:  utc(now.Add(backoff)),
+		ID:             arg.ID,
+	})
+}
+
+func (s *sqliteQueries) MarkWebhookDeliverySucceeded(ctx context.Context, arg MarkWebhookDeliverySucceededParams) error {
+	return s.q.MarkWebhookDeliverySucceeded(ctx, sqlite.MarkWebhookDeliverySucceededParams{
+		Now:            s.now(),
+		LastStatusCode: nullInt64(arg.LastStatusCode),
+		ID:             arg.ID,
+	})
+}
+
+func (s *sqliteQueries) MarkWebhookEventFailed(ctx context.Context, arg MarkWebhookEventFailedParams) error {
+	return s.q.MarkWebhookEventFailed(ctx, sqlite.MarkWebhookEventFailedParams{
+		Now:       s.now(),
+		LastError: arg.LastError,
+		ID:        arg.ID,
+	})
+}
+
+func (s *sqliteQueries) MarkWebhookEventProcessed(ctx context.Context, arg MarkWebhookEventProcessedParams) error {
+	return s.q.MarkWebhookEventProcessed(ctx, sqlite.MarkWebhookEventProcessedParams{
+		Now:    s.now(),
+		Status: arg.Status,
+		ID:     arg.ID,
+	})
+}
+
+func (s *sqliteQueries) PinChirp(ctx context.Context, arg PinChirpParams) error {
+	return s.q.PinChirp(ctx, sqlite.PinChirpParams{UserID: arg.UserID, ChirpID: arg.ChirpID, Now: s.now()})
+}
+
+func (s *sqliteQueries) RejectFollowRequest(ctx context.Context, arg RejectFollowRequestParams) (int64, error) {
+	return s.q.RejectFollowRequest(ctx, sqlite.RejectFollowRequestParams(arg))
+}
+
+func (s *sqliteQueries) ResetUsers(ctx context.Context) error {
+	return s.q.ResetUsers(ctx)
+}
+
+func (s *sqliteQueries) RevokeAllRefreshTokensForUser(ctx context.Context, userID uuid.UUID) error {
+	return s.q.RevokeAllRefreshTokensForUser(ctx, sqlite.RevokeAllRefreshTokensForUserParams{Now: s.now(), UserID: userID})
+}
+
+func (s *sqliteQueries) RevokeRefreshToken(ctx context.Context, token string) error {
+	return s.q.RevokeRefreshToken(ctx, sqlite.RevokeRefreshTokenParams{Now: s.now(), Token: token})
+}
+
+func (s *sqliteQueries) SetAuditChainHead(ctx context.Context, headHash string) error {
+	return s.q.SetAuditChainHead(ctx, headHash)
+}
+
+func (s *sqliteQueries) ScheduleUserDeletion(ctx context.Context, id uuid.UUID) (sql.NullTime, error) {
+	now := s.now()
+	n, err := s.q.ScheduleUserDeletion(ctx, sqlite.ScheduleUserDeletionParams{
+		Now:                 now,
+		DeletionScheduledAt: sql.NullTime{Time: now.Add(deletionGracePeriod), Valid: true},
+		ID:                  id,
+	})
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+	if n == 0 {
+		return sql.NullTime{}, sql.ErrNoRows
+	}
+	u, err := s.q.GetUser(ctx, id)
+	return u.DeletionScheduledAt, err
+}
+
+func (s *sqliteQueries) SuspendUser(ctx context.Context, arg SuspendUserParams) error {
+	now := s.now()
+	var until sql.NullTime
+	if arg.DurationHours.Valid {
+		until = sql.NullTime{Time: now.Add(time.Duration(arg.DurationHours.Int32) * time.Hour), Valid: true}
+	}
+	return s.q.SuspendUser(ctx, sqlite.SuspendUserParams{
+		Now:            now,
+		Suspension:     arg.Suspension,
+		SuspendedUntil: until,
+		ID:             arg.ID,
+	})
+}
+
+func (s *sqliteQueries) UnpinChirp(ctx context.Context, arg UnpinChirpParams) error {
+	return s.q.UnpinChirp(ctx, sqlite.UnpinChirpParams(arg))
+}
+
+func (s *sqliteQueries) UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateUserRow, error) {
+	n, err := s.q.UpdateUser(ctx, sqlite.UpdateUserParams{
+		Now:            s.now(),
+		Email:          arg.Email,
+		HashedPassword: arg.HashedPassword,
+		ID:             arg.ID,
+	})
+	if err != nil {
+		return UpdateUserRow{}, err
+	}
+	if n == 0 {
+		return UpdateUserRow{}, sql.ErrNoRows
+	}
+	u, err := s.q.GetUser(ctx, arg.ID)
+	return UpdateUserRow{ID: u.ID, CreatedAt: u.CreatedAt, UpdatedAt: u.UpdatedAt, Email: u.Email}, err
+}
+
+func (s *sqliteQueries) UpdateUserProtected(ctx context.Context, arg UpdateUserProtectedParams) error {
+	return s.q.UpdateUserProtected(ctx, sqlite.UpdateUserProtectedParams{Now: s.now(), Protected: arg.Protected, ID: arg.ID})
+}
+
+func (s *sqliteQueries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (int64, error) {
+	return s.q.UpdateUserRole(ctx, sqlite.UpdateUserRoleParams{Now: s.now(), Role: arg.Role, ID: arg.ID})
+}
+
+func (s *sqliteQueries) UpdateUserRoleByEmail(ctx context.Context, arg UpdateUserRoleByEmailParams) (int64, error) {
+	return s.q.UpdateUserRoleByEmail(ctx, sqlite.UpdateUserRoleByEmailParams{Now: s.now(), Role: arg.Role, Email: arg.Email})
+}
+
+func (s *sqliteQueries) UpsertEntitlementOverride(ctx context.Context, arg UpsertEntitlementOverrideParams) error {
+	return s.q.UpsertEntitlementOverride(ctx, sqlite.UpsertEntitlementOverrideParams{
+		UserID:    arg.UserID,
+		Now:       s.now(),
+		Overrides: arg.Overrides,
+	})
+}
+
+func chirpFromSQLite(c sqlite.Chirp) Chirp {
+	return Chirp(c)
+}
+
+func followFromSQLite(f sqlite.Follow) Follow {
+	return Follow(f)
+}
+
+func reportFromSQLite(r sqlite.Report) Report {
+	return Report(r)
+}
+
+func auditEventFromSQLite(e sqlite.AuditEvent) AuditEvent {
+	return AuditEvent(e)
+}
+
+// SQLite integers are all 64-bit, where the Postgres columns are INTEGER.
+
+func webhookEventFromSQLite(e sqlite.WebhookEvent) WebhookEvent {
+	return WebhookEvent{
+		ID:          e.ID,
+		CreatedAt:   e.CreatedAt,
+		UpdatedAt:   e.UpdatedAt,
+		Provider:    e.Provider,
+		EventID:     e.EventID,
+		EventType:   e.EventType,
+		Status:      e.Status,
+		Attempts:    int32(e.Attempts),
+		Payload:     e.Payload,
+		LastError:   e.LastError,
+		ProcessedAt: e.ProcessedAt,
+	}
+}
+
+func webhookDeliveryFromSQLite(d sqlite.WebhookDelivery) WebhookDelivery {
+	return WebhookDelivery{
+		ID:             d.ID,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+		EndpointID:     d.EndpointID,
+		EventID:        d.EventID,
+		Status:         d.Status,
+		Attempts:       int32(d.Attempts),
+		NextAttemptAt:  d.NextAttemptAt,
+		LastStatusCode: sql.NullInt32{Int32: int32(d.LastStatusCode.Int64), Valid: d.LastStatusCode.Valid},
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+	}
+}
+
+func nullInt64(n sql.NullInt32) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(n.Int32), Valid: n.Valid}
+}
+
+// webhookEndpointFromSQLite decodes events, which SQLite keeps as a JSON
+// array rather than a TEXT[].
+func webhookEndpointFromSQLite(e sqlite.WebhookEndpoint) (WebhookEndpoint, error) {
+	var events []string
+	err := json.Unmarshal(e.Events, &events)
+	if err != nil {
+		return WebhookEndpoint{}, err
+	}
+	return WebhookEndpoint{
+		ID:        e.ID,
+		CreatedAt: e.CreatedAt,
+		UpdatedAt: e.UpdatedAt,
+		UserID:    e.UserID,
+		Url:       e.Url,
+		Secret:    e.Secret,
+		Events:    events,
+		Active:    e.Active,
+	}, nil
+}
+
+func webhookEndpointsFromSQLite(rows []sqlite.WebhookEndpoint) ([]WebhookEndpoint, error) {
+	var endpoints []WebhookEndpoint
+	for _, row := range rows {
+		e, err := webhookEndpointFromSQLite(row)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, e)
+	}
+	return endpoints, nil
+}
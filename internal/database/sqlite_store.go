@@ -0,0 +1,115 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/joshckidd/chirpy/internal/database/sqlite"
+)
+
+// SQLiteStore runs the queries in sql/sqlite/queries against a SQLite
+// database. SQLite has no NOW(), intervals or UUID generation, so the store
+// passes in the time, the deadlines computed from it and new IDs, and
+// converts the results to the Postgres models the handlers use. The
+// queries don't use RETURNING, which older SQLite builds lack, so writes
+// that return rows read them back afterwards.
+type SQLiteStore struct {
+	*sqliteQueries
+	db   *sql.DB
+	wrap func(DBTX) DBTX
+}
+
+// NewSQLiteStore returns a store for db, which should have been opened with
+// Open so that foreign keys are enforced. wrap is as for NewPostgresStore.
+func NewSQLiteStore(db *sql.DB, wrap func(DBTX) DBTX) *SQLiteStore {
+	if wrap == nil {
+		wrap = func(d DBTX) DBTX { return d }
+	}
+	clock := &sqliteClock{now: time.Now}
+	return &SQLiteStore{
+		sqliteQueries: &sqliteQueries{q: sqlite.New(wrap(db)), clock: clock},
+		db:            db,
+		wrap:          wrap,
+	}
+}
+
+// SetClock makes the store use now instead of time.Now, so tests can move
+// time forward.
+func (s *SQLiteStore) SetClock(now func() time.Time) {
+	s.clock.now = now
+}
+
+func (s *SQLiteStore) BeginTx(ctx context.Context) (Tx, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteTx{
+		sqliteQueries: &sqliteQueries{q: sqlite.New(s.wrap(tx)), clock: s.clock},
+		tx:            tx,
+	}, nil
+}
+
+func (s *SQLiteStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+type sqliteTx struct {
+	*sqliteQueries
+	tx *sql.Tx
+}
+
+func (t *sqliteTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *sqliteTx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+type sqliteClock struct {
+	now func() time.Time
+}
+
+// sqliteQueries adapts the generated SQLite queries to Querier.
+type sqliteQueries struct {
+	q     *sqlite.Queries
+	clock *sqliteClock
+}
+
+// now is the time to store, in UTC so that the text SQLite keeps sorts in
+// time order, and to the microsecond like Postgres timestamps.
+func (s *sqliteQueries) now() time.Time {
+	return s.clock.now().UTC().Truncate(time.Microsecond)
+}
+
+// nullNow is now for queries that compare it with nullable columns.
+func (s *sqliteQueries) nullNow() sql.NullTime {
+	return sql.NullTime{Time: s.now(), Valid: true}
+}
+
+// utc converts a time from the caller the way now does.
+func utc(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Microsecond)
+}
+
+func nullUTC(t sql.NullTime) sql.NullTime {
+	if !t.Valid {
+		return t
+	}
+	return sql.NullTime{Time: utc(t.Time), Valid: true}
+}
+
+// mapRows converts each row with conv, keeping a nil slice nil as the
+// Postgres queries return it.
+func mapRows[S, T any](rows []S, conv func(S) T) []T {
+	if rows == nil {
+		return nil
+	}
+	out := make([]T, len(rows))
+	for i, r := range rows {
+		out[i] = conv(r)
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Store is everything the handlers need from the database: the queries,
+// transactions and a health check. PostgresStore and SQLiteStore are the
+// real ones; tests also use the in-memory store in internal/memstore.
+type Store interface {
+	Querier
+	BeginTx(ctx context.Context) (Tx, error)
+	Ping(ctx context.Context) error
+}
+
+// Tx runs queries in a transaction. Rollback after Commit does nothing, so
+// it can be deferred.
+type Tx interface {
+	Querier
+	Commit() error
+	Rollback() error
+}
+
+// PostgresStore runs the queries against a Postgres database.
+type PostgresStore struct {
+	*Queries
+	db   *sql.DB
+	wrap func(DBTX) DBTX
+}
+
+// NewPostgresStore returns a store for db. wrap, if not nil, is applied to
+// the connection and to each transaction before queries run on them, which
+// is how queries get traced.
+func NewPostgresStore(db *sql.DB, wrap func(DBTX) DBTX) *PostgresStore {
+	if wrap == nil {
+		wrap = func(d DBTX) DBTX { return d }
+	}
+	return &PostgresStore{Queries: New(wrap(db)), db: db, wrap: wrap}
+}
+
+func (s *PostgresStore) BeginTx(ctx context.Context) (Tx, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &postgresTx{Queries: New(s.wrap(tx)), tx: tx}, nil
+}
+
+func (s *PostgresStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+type postgresTx struct {
+	*Queries
+	tx *sql.Tx
+}
+
+func (t *postgresTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *postgresTx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// NewStore returns the store for a database opened with Open.
+func NewStore(db *sql.DB, dialect Dialect, wrap func(DBTX) DBTX) Store {
+	if dialect == SQLite {
+		return NewSQLiteStore(db, wrap)
+	}
+	return NewPostgresStore(db, wrap)
+}
@@ -13,6 +13,37 @@ import (
 	"github.com/google/uuid"
 )
 
+const cancelUserDeletion = `-- name: CancelUserDeletion :exec
+UPDATE users
+SET (updated_at, deletion_scheduled_at) = (NOW(), NULL)
+WHERE id = $1
+    AND deletion_scheduled_at IS NOT NULL
+`
+
+func (q *Queries) CancelUserDeletion(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, cancelUserDeletion, id)
+	return err
+}
+
+const createAccountDeletion = `-- name: CreateAccountDeletion :exec
+INSERT INTO account_deletions (user_id, scheduled_at, deleted_at)
+VALUES (
+    $1
+    ,$2
+    ,NOW()
+)
+`
+
+type CreateAccountDeletionParams struct {
+	UserID      uuid.UUID `json:"user_id"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
+
+func (q *Queries) CreateAccountDeletion(ctx context.Context, arg CreateAccountDeletionParams) error {
+	_, err := q.db.ExecContext(ctx, createAccountDeletion, arg.UserID, arg.ScheduledAt)
+	return err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (id, created_at, updated_at, email, hashed_password)
 VALUES (
@@ -22,7 +53,7 @@ VALUES (
     ,$1
     ,$2
 )
-RETURNING id, created_at, updated_at, email, is_chirpy_red
+RETURNING id, created_at, updated_at, email
 `
 
 type CreateUserParams struct {
@@ -31,11 +62,10 @@ type CreateUserParams struct {
 }
 
 type CreateUserRow struct {
-	ID          uuid.UUID    `json:"id"`
-	CreatedAt   time.Time    `json:"created_at"`
-	UpdatedAt   time.Time    `json:"updated_at"`
-	Email       string       `json:"email"`
-	IsChirpyRed sql.NullBool `json:"is_chirpy_red"`
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Email     string    `json:"email"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error) {
@@ -46,13 +76,112 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateU
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Email,
-		&i.IsChirpyRed,
 	)
 	return i, err
 }
 
+const deleteUser = `-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1
+    AND deletion_scheduled_at <= NOW()
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getActiveSuspension = `-- name: GetActiveSuspension :one
+SELECT CASE
+    WHEN suspended_until IS NULL OR suspended_until > NOW() THEN suspension
+END AS suspension
+FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetActiveSuspension(ctx context.Context, id uuid.UUID) (sql.NullString, error) {
+	row := q.db.QueryRowContext(ctx, getActiveSuspension, id)
+	var suspension sql.NullString
+	err := row.Scan(&suspension)
+	return suspension, err
+}
+
+const getUser = `-- name: GetUser :one
+SELECT id, created_at, updated_at, email, hashed_password, protected, deletion_scheduled_at, role, suspension, suspended_until
+FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUser(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.Protected,
+		&i.DeletionScheduledAt,
+		&i.Role,
+		&i.Suspension,
+		&i.SuspendedUntil,
+	)
+	return i, err
+}
+
+const getUserRole = `-- name: GetUserRole :one
+SELECT role
+FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserRole(ctx context.Context, id uuid.UUID) (string, error) {
+	row := q.db.QueryRowContext(ctx, getUserRole, id)
+	var role string
+	err := row.Scan(&role)
+	return role, err
+}
+
+const getUsersDueForDeletion = `-- name: GetUsersDueForDeletion :many
+SELECT id, deletion_scheduled_at
+FROM users
+WHERE deletion_scheduled_at <= NOW()
+`
+
+type GetUsersDueForDeletionRow struct {
+	ID                  uuid.UUID    `json:"id"`
+	DeletionScheduledAt sql.NullTime `json:"deletion_scheduled_at"`
+}
+
+func (q *Queries) GetUsersDueForDeletion(ctx context.Context) ([]GetUsersDueForDeletionRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersDueForDeletion)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUsersDueForDeletionRow
+	for rows.Next() {
+		var i GetUsersDueForDeletionRow
+		if err := rows.Scan(&i.ID, &i.DeletionScheduledAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserWithEmail = `-- name: GetUserWithEmail :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red
+SELECT id, created_at, updated_at, email, hashed_password, protected, deletion_scheduled_at, role, suspension, suspended_until
 FROM users
 WHERE email = $1
 `
@@ -66,11 +195,30 @@ func (q *Queries) GetUserWithEmail(ctx context.Context, email string) (User, err
 		&i.UpdatedAt,
 		&i.Email,
 		&i.HashedPassword,
-		&i.IsChirpyRed,
+		&i.Protected,
+		&i.DeletionScheduledAt,
+		&i.Role,
+		&i.Suspension,
+		&i.SuspendedUntil,
 	)
 	return i, err
 }
 
+const liftSuspension = `-- name: LiftSuspension :execrows
+UPDATE users
+SET (updated_at, suspension, suspended_until) = (NOW(), NULL, NULL)
+WHERE id = $1
+    AND suspension IS NOT NULL
+`
+
+func (q *Queries) LiftSuspension(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, liftSuspension, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const resetUsers = `-- name: ResetUsers :exec
 DELETE FROM users
 `
@@ -80,11 +228,46 @@ func (q *Queries) ResetUsers(ctx context.Context) error {
 	return err
 }
 
+const scheduleUserDeletion = `-- name: ScheduleUserDeletion :one
+UPDATE users
+SET (updated_at, deletion_scheduled_at) = (NOW(), NOW() + interval '30 days')
+WHERE id = $1
+RETURNING deletion_scheduled_at
+`
+
+func (q *Queries) ScheduleUserDeletion(ctx context.Context, id uuid.UUID) (sql.NullTime, error) {
+	row := q.db.QueryRowContext(ctx, scheduleUserDeletion, id)
+	var deletion_scheduled_at sql.NullTime
+	err := row.Scan(&deletion_scheduled_at)
+	return deletion_scheduled_at, err
+}
+
+const suspendUser = `-- name: SuspendUser :exec
+UPDATE users
+SET (updated_at, suspension, suspended_until) = (
+    NOW()
+    ,$1
+    ,NOW() + make_interval(hours => $2::int)
+)
+WHERE id = $3
+`
+
+type SuspendUserParams struct {
+	Suspension    sql.NullString `json:"suspension"`
+	DurationHours sql.NullInt32  `json:"duration_hours"`
+	ID            uuid.UUID      `json:"id"`
+}
+
+func (q *Queries) SuspendUser(ctx context.Context, arg SuspendUserParams) error {
+	_, err := q.db.ExecContext(ctx, suspendUser, arg.Suspension, arg.DurationHours, arg.ID)
+	return err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users 
 SET (updated_at, email, hashed_password) = (NOW(), $1 ,$2)
 WHERE id = $3
-RETURNING id, created_at, updated_at, email, is_chirpy_red
+RETURNING id, created_at, updated_at, email
 `
 
 type UpdateUserParams struct {
@@ -94,11 +277,10 @@ type UpdateUserParams struct {
 }
 
 type UpdateUserRow struct {
-	ID          uuid.UUID    `json:"id"`
-	CreatedAt   time.Time    `json:"created_at"`
-	UpdatedAt   time.Time    `json:"updated_at"`
-	Email       string       `json:"email"`
-	IsChirpyRed sql.NullBool `json:"is_chirpy_red"`
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Email     string    `json:"email"`
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateUserRow, error) {
@@ -109,18 +291,60 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateU
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Email,
-		&i.IsChirpyRed,
 	)
 	return i, err
 }
 
-const updateUserRed = `-- name: UpdateUserRed :exec
+const updateUserProtected = `-- name: UpdateUserProtected :exec
 UPDATE users 
-SET is_chirpy_red = true
-WHERE id = $1
+SET (updated_at, protected) = (NOW(), $1)
+WHERE id = $2
 `
 
-func (q *Queries) UpdateUserRed(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, updateUserRed, id)
+type UpdateUserProtectedParams struct {
+	Protected bool      `json:"protected"`
+	ID        uuid.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateUserProtected(ctx context.Context, arg UpdateUserProtectedParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserProtected, arg.Protected, arg.ID)
 	return err
 }
+
+const updateUserRole = `-- name: UpdateUserRole :execrows
+UPDATE users
+SET (updated_at, role) = (NOW(), $1)
+WHERE id = $2
+`
+
+type UpdateUserRoleParams struct {
+	Role string    `json:"role"`
+	ID   uuid.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUserRole, arg.Role, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateUserRoleByEmail = `-- name: UpdateUserRoleByEmail :execrows
+UPDATE users
+SET (updated_at, role) = (NOW(), $1)
+WHERE email = $2
+`
+
+type UpdateUserRoleByEmailParams struct {
+	Role  string `json:"role"`
+	Email string `json:"email"`
+}
+
+func (q *Queries) UpdateUserRoleByEmail(ctx context.Context, arg UpdateUserRoleByEmailParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUserRoleByEmail, arg.Role, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
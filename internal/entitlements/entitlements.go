@@ -0,0 +1,108 @@
+package entitlements
+
+import (
+	"encoding/json"
+	"os"
+)
+
+const (
+	PlanFree = "free"
+	PlanRed  = "chirpy_red"
+)
+
+// Limits are the feature limits for a plan. A zero ChirpsPerHour means no
+// rate limit.
+type Limits struct {
+	MaxChirpLength   int  `json:"max_chirp_length"`
+	MaxMediaPerChirp int  `json:"max_media_per_chirp"`
+	CanEditChirps    bool `json:"can_edit_chirps"`
+	MaxPinnedChirps  int  `json:"max_pinned_chirps"`
+	ChirpsPerHour    int  `json:"chirps_per_hour"`
+}
+
+// Override replaces individual limits for a single user. Nil fields keep
+// the value from the user's plan.
+type Override struct {
+	MaxChirpLength   *int  `json:"max_chirp_length,omitempty"`
+	MaxMediaPerChirp *int  `json:"max_media_per_chirp,omitempty"`
+	CanEditChirps    *bool `json:"can_edit_chirps,omitempty"`
+	MaxPinnedChirps  *int  `json:"max_pinned_chirps,omitempty"`
+	ChirpsPerHour    *int  `json:"chirps_per_hour,omitempty"`
+}
+
+type Plans map[string]Limits
+
+func DefaultPlans() Plans {
+	return Plans{
+		PlanFree: {
+			MaxChirpLength:   140,
+			MaxMediaPerChirp: 1,
+			CanEditChirps:    false,
+			MaxPinnedChirps:  1,
+			ChirpsPerHour:    30,
+		},
+		PlanRed: {
+			MaxChirpLength:   500,
+			MaxMediaPerChirp: 4,
+			CanEditChirps:    true,
+			MaxPinnedChirps:  3,
+			ChirpsPerHour:    300,
+		},
+	}
+}
+
+// LoadPlans reads plan limits from a JSON file keyed by plan name. Limits
+// left out of the file keep their default values, and plans the defaults
+// don't know about start from the free plan.
+func LoadPlans(path string) (Plans, error) {
+	plans := DefaultPlans()
+
+	dat, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fromFile := map[string]json.RawMessage{}
+	err = json.Unmarshal(dat, &fromFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, raw := range fromFile {
+		limits := plans.For(name)
+		err = json.Unmarshal(raw, &limits)
+		if err != nil {
+			return nil, err
+		}
+		plans[name] = limits
+	}
+	return plans, nil
+}
+
+// For returns the limits for plan, falling back to the free plan for plans
+// that aren't configured.
+func (p Plans) For(plan string) Limits {
+	if limits, ok := p[plan]; ok {
+		return limits
+	}
+	return p[PlanFree]
+}
+
+func (l Limits) Apply(o Override) Limits {
+	if o.MaxChirpLength != nil {
+		l.MaxChirpLength = *o.MaxChirpLength
+	}
+	if o.MaxMediaPerChirp != nil {
+		l.MaxMediaPerChirp = *o.MaxMediaPerChirp
+	}
+	if o.CanEditChirps != nil {
+		l.CanEditChirps = *o.CanEditChirps
+	}
+	if o.MaxPinnedChirps != nil {
+		l.MaxPinnedChirps = *o.MaxPinnedChirps
+	}
+	if o.ChirpsPerHour != nil {
+		l.ChirpsPerHour = *o.ChirpsPerHour
+	}
+	return l
+}
@@ -0,0 +1,90 @@
+package entitlements
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnknownPlanFallsBackToFree(t *testing.T) {
+	plans := DefaultPlans()
+	res := plans.For("unknown")
+	if res != plans[PlanFree] {
+		t.Errorf("Want %v, got %v", plans[PlanFree], res)
+	}
+}
+
+func TestApplyOverride(t *testing.T) {
+	length := 280
+	res := DefaultPlans().For(PlanFree).Apply(Override{MaxChirpLength: &length})
+	if res.MaxChirpLength != 280 {
+		t.Errorf("Want %v, got %v", 280, res.MaxChirpLength)
+	}
+	if res.MaxPinnedChirps != 1 {
+		t.Errorf("Want %v, got %v", 1, res.MaxPinnedChirps)
+	}
+}
+
+func TestLoadPlans(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plans.json")
+	err := os.WriteFile(path, []byte(`{"free": {"max_chirp_length": 200, "max_pinned_chirps": 2}}`), 0o600)
+	if err != nil {
+		t.Fatalf("Error writing plans: %v", err.Error())
+	}
+
+	plans, err := LoadPlans(path)
+	if err != nil {
+		t.Fatalf("Error loading plans: %v", err.Error())
+	}
+	if plans.For(PlanFree).MaxChirpLength != 200 {
+		t.Errorf("Want %v, got %v", 200, plans.For(PlanFree).MaxChirpLength)
+	}
+	if plans.For(PlanFree).ChirpsPerHour != 30 {
+		t.Errorf("Want %v, got %v", 30, plans.For(PlanFree).ChirpsPerHour)
+	}
+	if plans.For(PlanRed) != DefaultPlans()[PlanRed] {
+		t.Errorf("Want default Red plan, got %v", plans.For(PlanRed))
+	}
+}
+
+func TestLoadPlansNewPlan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plans.json")
+	err := os.WriteFile(path, []byte(`{"chirpy_gold": {"max_chirp_length": 1000}}`), 0o600)
+	if err != nil {
+		t.Fatalf("Error writing plans: %v", err.Error())
+	}
+
+	plans, err := LoadPlans(path)
+	if err != nil {
+		t.Fatalf("Error loading plans: %v", err.Error())
+	}
+	want := DefaultPlans()[PlanFree]
+	want.MaxChirpLength = 1000
+	if plans.For("chirpy_gold") != want {
+		t.Errorf("Want %v, got %v", want, plans.For("chirpy_gold"))
+	}
+}
+
+func TestLoadPlansBadFile(t *testing.T) {
+	dir := t.TempDir()
+	for name, contents := range map[string]string{
+		"not json":   `max_chirp_length = 200`,
+		"wrong type": `{"free": {"max_chirp_length": "long"}}`,
+		"not a plan": `{"free": 200}`,
+	} {
+		path := filepath.Join(dir, "plans.json")
+		err := os.WriteFile(path, []byte(contents), 0o600)
+		if err != nil {
+			t.Fatalf("Error writing plans: %v", err.Error())
+		}
+		_, err = LoadPlans(path)
+		if err == nil {
+			t.Errorf("%s: want error, got nil", name)
+		}
+	}
+
+	_, err := LoadPlans(filepath.Join(dir, "missing.json"))
+	if err == nil {
+		t.Errorf("Want error for a missing file, got nil")
+	}
+}
@@ -0,0 +1,336 @@
+package integration
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/auth"
+)
+
+const (
+	testSecret      = "integration-test-secret-0123456789"
+	testPolkaSecret = "integration-polka-secret"
+)
+
+var buildChirpy struct {
+	sync.Once
+	err error
+}
+
+// chirpyBinary builds the server once for all the tests that run it.
+func chirpyBinary(t *testing.T) string {
+	t.Helper()
+
+	bin := filepath.Join(binDir, "chirpy")
+	buildChirpy.Do(func() {
+		out, err := exec.Command("go", "build", "-o", bin, "github.com/joshckidd/chirpy").CombinedOutput()
+		if err != nil {
+			buildChirpy.err = fmt.Errorf("%w: %s", err, out)
+		}
+	})
+	if buildChirpy.err != nil {
+		t.Fatalf("Error building chirpy: %v", buildChirpy.err)
+	}
+	return bin
+}
+
+// server is a chirpy process serving a migrated schema of its own.
+type server struct {
+	t   *testing.T
+	url string
+	db  *sql.DB
+	env []string
+}
+
+// startServer migrates a new schema and runs chirpy against it until the
+// test ends. The server's output is logged if the test fails.
+func startServer(t *testing.T) *server {
+	t.Helper()
+
+	db, dbURL := migratedDB(t)
+	port, err := freePort()
+	if err != nil {
+		t.Fatalf("Error finding a free port: %v", err)
+	}
+	dir := t.TempDir()
+	s := &server{
+		t:   t,
+		url: fmt.Sprintf("http://127.0.0.1:%d", port),
+		db:  db,
+		env: []string{
+			"PATH=" + os.Getenv("PATH"),
+			"DB_URL=" + dbURL,
+			"SECRET=" + testSecret,
+			"POLKA_WEBHOOK_SECRETS=" + testPolkaSecret,
+			"PLATFORM=dev",
+			"ADDR=" + fmt.Sprintf("127.0.0.1:%d", port),
+			"FILESERVER_ROOT=" + dir,
+			"EXPORT_DIR=" + dir,
+			"SHUTDOWN_DELAY=0s",
+		},
+	}
+
+	var out bytes.Buffer
+	cmd := exec.Command(chirpyBinary(t))
+	// Run in an empty directory so that a .env file can't change the
+	// settings.
+	cmd.Dir = dir
+	cmd.Env = s.env
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err = cmd.Start()
+	if err != nil {
+		t.Fatalf("Error starting chirpy: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Signal(os.Interrupt)
+		cmd.Wait()
+		if t.Failed() {
+			t.Logf("chirpy output:\n%s", out.String())
+		}
+	})
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		res, err := http.Get(s.url + "/api/readyz")
+		if err == nil {
+			res.Body.Close()
+			if res.StatusCode == 200 {
+				return s
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("chirpy wasn't ready after 10s: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// do sends a request with body encoded as JSON unless it is nil or already
+// a []byte, authenticated with token if it isn't empty.
+func (s *server) do(method, path, token string, body any, header http.Header) (int, []byte) {
+	s.t.Helper()
+
+	var r io.Reader
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		r = bytes.NewReader(b)
+	default:
+		dat, err := json.Marshal(b)
+		if err != nil {
+			s.t.Fatalf("Error encoding request body: %v", err)
+		}
+		r = bytes.NewReader(dat)
+	}
+
+	req, err := http.NewRequest(method, s.url+path, r)
+	if err != nil {
+		s.t.Fatalf("Error creating request: %v", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		s.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer res.Body.Close()
+	dat, err := io.ReadAll(res.Body)
+	if err != nil {
+		s.t.Fatalf("%s %s: error reading response: %v", method, path, err)
+	}
+	return res.StatusCode, dat
+}
+
+// expect sends a request like do and fails the test unless it gets code.
+func (s *server) expect(code int, method, path, token string, body any) []byte {
+	s.t.Helper()
+
+	got, dat := s.do(method, path, token, body, nil)
+	if got != code {
+		s.t.Fatalf("%s %s: want %d, got %d: %s", method, path, code, got, dat)
+	}
+	return dat
+}
+
+type testUser struct {
+	ID           uuid.UUID `json:"id"`
+	Email        string    `json:"email"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	IsChirpyRed  bool      `json:"is_chirpy_red"`
+}
+
+func decode[T any](t *testing.T, dat []byte) T {
+	t.Helper()
+
+	var v T
+	err := json.Unmarshal(dat, &v)
+	if err != nil {
+		t.Fatalf("Error decoding %q: %v", dat, err)
+	}
+	return v
+}
+
+func (s *server) login(email string) testUser {
+	s.t.Helper()
+
+	dat := s.expect(200, "POST", "/api/login", "", map[string]string{"email": email, "password": "hunter2"})
+	return decode[testUser](s.t, dat)
+}
+
+// signup creates a user through the API and logs them in.
+func (s *server) signup(name string) testUser {
+	s.t.Helper()
+
+	email := name + "@example.com"
+	s.expect(201, "POST", "/api/users", "", map[string]string{"email": email, "password": "hunter2"})
+	return s.login(email)
+}
+
+// count runs a COUNT query in the server's schema.
+func (s *server) count(query string, args ...any) int {
+	s.t.Helper()
+	return count(s.t, s.db, query, args...)
+}
+
+// polka sends body to the Polka webhook, signed with secret.
+func (s *server) polka(body any, secret string) int {
+	s.t.Helper()
+
+	dat, err := json.Marshal(body)
+	if err != nil {
+		s.t.Fatalf("Error encoding webhook: %v", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	header := http.Header{}
+	header.Set("X-Polka-Timestamp", timestamp)
+	header.Set("X-Polka-Signature", auth.SignWebhook(timestamp, dat, secret))
+	code, _ := s.do("POST", "/api/polka/webhooks", "", dat, header)
+	return code
+}
+
+func TestAPIRefreshTokenExpiry(t *testing.T) {
+	s := startServer(t)
+	u := s.signup("user")
+
+	dat := s.expect(200, "POST", "/api/refresh", u.RefreshToken, nil)
+	if id, err := auth.ValidateJWT(decode[testUser](t, dat).Token, testSecret); err != nil || id != u.ID {
+		t.Errorf("Want a token for %v, got %v, %v", u.ID, id, err)
+	}
+
+	_, err := s.db.Exec("UPDATE refresh_tokens SET expires_at = NOW() - interval '1 second' WHERE token = $1", u.RefreshToken)
+	if err != nil {
+		t.Fatalf("Error expiring token: %v", err)
+	}
+	s.expect(401, "POST", "/api/refresh", u.RefreshToken, nil)
+
+	// Logging in again issues a new token that works.
+	u = s.login(u.Email)
+	s.expect(200, "POST", "/api/refresh", u.RefreshToken, nil)
+	s.expect(204, "POST", "/api/revoke", u.RefreshToken, nil)
+	s.expect(401, "POST", "/api/refresh", u.RefreshToken, nil)
+}
+
+func TestAPICascadeDeletes(t *testing.T) {
+	s := startServer(t)
+	admin := s.signup("admin")
+	alice := s.signup("alice")
+	bob := s.signup("bob")
+
+	// The first admin is made with the CLI, as the API needs one already.
+	cmd := exec.Command(chirpyBinary(t), "set-role", admin.Email, "admin")
+	cmd.Dir = t.TempDir()
+	cmd.Env = s.env
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("set-role failed: %v: %s", err, out)
+	}
+
+	first := decode[struct{ ID uuid.UUID }](t, s.expect(201, "POST", "/api/chirps", alice.Token, map[string]string{"body": "first"}))
+	second := decode[struct{ ID uuid.UUID }](t, s.expect(201, "POST", "/api/chirps", alice.Token, map[string]string{"body": "second"}))
+	s.expect(201, "POST", "/api/chirps", bob.Token, map[string]string{"body": "reply"})
+	s.expect(204, "POST", "/api/chirps/"+first.ID.String()+"/bookmark", bob.Token, nil)
+	s.expect(204, "POST", "/api/chirps/"+second.ID.String()+"/bookmark", bob.Token, nil)
+
+	// Deleting a chirp deletes its bookmarks.
+	s.expect(204, "DELETE", "/api/chirps/"+first.ID.String(), alice.Token, nil)
+	if n := s.count("SELECT COUNT(*) FROM bookmarks WHERE chirp_id = $1", first.ID); n != 0 {
+		t.Errorf("Want the deleted chirp's bookmarks gone, got %d", n)
+	}
+	if n := s.count("SELECT COUNT(*) FROM bookmarks"); n != 1 {
+		t.Errorf("Want the other bookmark kept, got %d", n)
+	}
+
+	// Resetting deletes every user, and with them everything they own.
+	s.expect(200, "POST", "/admin/reset", admin.Token, nil)
+	for _, table := range []string{"users", "chirps", "refresh_tokens", "bookmarks"} {
+		if n := s.count("SELECT COUNT(*) FROM " + table); n != 0 {
+			t.Errorf("Want %s empty after a reset, got %d rows", table, n)
+		}
+	}
+	s.expect(401, "POST", "/api/refresh", alice.RefreshToken, nil)
+}
+
+func TestAPIPolkaWebhook(t *testing.T) {
+	s := startServer(t)
+	u := s.signup("user")
+
+	event := map[string]any{
+		"id":    "evt_1",
+		"event": "user.upgraded",
+		"data":  map[string]any{"user_id": u.ID},
+	}
+	if code := s.polka(event, "wrong-secret"); code != 401 {
+		t.Errorf("Want 401 for a bad signature, got %d", code)
+	}
+	if code := s.polka(event, testPolkaSecret); code != 204 {
+		t.Fatalf("Want 204, got %d", code)
+	}
+	if !s.login(u.Email).IsChirpyRed {
+		t.Errorf("Want the user upgraded to Chirpy Red")
+	}
+
+	// Redelivery is acknowledged but recorded and applied only once.
+	if code := s.polka(event, testPolkaSecret); code != 204 {
+		t.Errorf("Want 204 for a duplicate event, got %d", code)
+	}
+	if n := s.count("SELECT COUNT(*) FROM webhook_events WHERE event_id = 'evt_1' AND status = 'processed'"); n != 1 {
+		t.Errorf("Want one processed event, got %d", n)
+	}
+	if n := s.count("SELECT COUNT(*) FROM subscriptions WHERE user_id = $1 AND status = 'active'", u.ID); n != 1 {
+		t.Errorf("Want an active subscription, got %d", n)
+	}
+	if n := s.count("SELECT COUNT(*) FROM outbox_events WHERE event_type = 'user.upgraded'"); n != 1 {
+		t.Errorf("Want one user.upgraded outbox event, got %d", n)
+	}
+
+	event = map[string]any{
+		"id":    "evt_2",
+		"event": "user.downgraded",
+		"data":  map[string]any{"user_id": u.ID},
+	}
+	if code := s.polka(event, testPolkaSecret); code != 204 {
+		t.Fatalf("Want 204, got %d", code)
+	}
+	if s.login(u.Email).IsChirpyRed {
+		t.Errorf("Want the user downgraded")
+	}
+}
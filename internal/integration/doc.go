@@ -0,0 +1,5 @@
+// Package integration tests the SQL in sql/schema and sql/queries, and the
+// server built on it, against a real Postgres. Its tests start an ephemeral
+// server with initdb and pg_ctl, or in a Docker container, or use the one
+// TEST_DB_URL points at, and are skipped when none is available.
+package integration
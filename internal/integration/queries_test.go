@@ -0,0 +1,184 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func createUser(t *testing.T, q *database.Queries, email string) uuid.UUID {
+	t.Helper()
+
+	u, err := q.CreateUser(context.Background(), database.CreateUserParams{Email: email, HashedPassword: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser returned error: %v", err)
+	}
+	return u.ID
+}
+
+func count(t *testing.T, db *sql.DB, query string, args ...any) int {
+	t.Helper()
+
+	var n int
+	err := db.QueryRow(query, args...).Scan(&n)
+	if err != nil {
+		t.Fatalf("Error running %q: %v", query, err)
+	}
+	return n
+}
+
+func TestRefreshTokenExpiry(t *testing.T) {
+	db, _ := migratedDB(t)
+	q := database.New(db)
+	ctx := context.Background()
+	userID := createUser(t, q, "a@example.com")
+
+	token, err := q.CreateRefreshToken(ctx, database.CreateRefreshTokenParams{Token: "live", UserID: userID})
+	if err != nil {
+		t.Fatalf("CreateRefreshToken returned error: %v", err)
+	}
+	if d := token.ExpiresAt.Sub(token.CreatedAt).Hours(); d != 60*24 {
+		t.Errorf("Want the token to last 60 days, got %v hours", d)
+	}
+	if id, err := q.GetUserFromRefreshToken(ctx, "live"); err != nil || id != userID {
+		t.Errorf("Want %v for a live token, got %v, %v", userID, id, err)
+	}
+
+	_, err = q.CreateRefreshToken(ctx, database.CreateRefreshTokenParams{Token: "expired", UserID: userID})
+	if err != nil {
+		t.Fatalf("CreateRefreshToken returned error: %v", err)
+	}
+	_, err = db.Exec("UPDATE refresh_tokens SET expires_at = NOW() - interval '1 second' WHERE token = 'expired'")
+	if err != nil {
+		t.Fatalf("Error expiring token: %v", err)
+	}
+	if _, err := q.GetUserFromRefreshToken(ctx, "expired"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Want no rows for an expired token, got %v", err)
+	}
+
+	err = q.RevokeRefreshToken(ctx, "live")
+	if err != nil {
+		t.Fatalf("RevokeRefreshToken returned error: %v", err)
+	}
+	if _, err := q.GetUserFromRefreshToken(ctx, "live"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Want no rows for a revoked token, got %v", err)
+	}
+}
+
+func TestDeleteUserCascades(t *testing.T) {
+	db, _ := migratedDB(t)
+	q := database.New(db)
+	ctx := context.Background()
+	alice := createUser(t, q, "alice@example.com")
+	bob := createUser(t, q, "bob@example.com")
+
+	chirp, err := q.CreateChirp(ctx, database.CreateChirpParams{Body: "hello", UserID: alice})
+	if err != nil {
+		t.Fatalf("CreateChirp returned error: %v", err)
+	}
+	bobChirp, err := q.CreateChirp(ctx, database.CreateChirpParams{Body: "hi", UserID: bob})
+	if err != nil {
+		t.Fatalf("CreateChirp returned error: %v", err)
+	}
+	for _, err := range []error{
+		q.CreateBookmark(ctx, database.CreateBookmarkParams{UserID: bob, ChirpID: chirp.ID}),
+		q.CreateBookmark(ctx, database.CreateBookmarkParams{UserID: alice, ChirpID: bobChirp.ID}),
+		q.CreateBlock(ctx, database.CreateBlockParams{BlockerID: bob, BlockedID: alice}),
+		q.ActivateSubscription(ctx, database.ActivateSubscriptionParams{UserID: alice, Plan: "chirpy_red"}),
+		q.UpsertEntitlementOverride(ctx, database.UpsertEntitlementOverrideParams{UserID: alice, Overrides: json.RawMessage(`{}`)}),
+	} {
+		if err != nil {
+			t.Fatalf("Error creating rows: %v", err)
+		}
+	}
+	_, err = q.CreateRefreshToken(ctx, database.CreateRefreshTokenParams{Token: "token", UserID: alice})
+	if err != nil {
+		t.Fatalf("CreateRefreshToken returned error: %v", err)
+	}
+	_, err = q.CreateFollow(ctx, database.CreateFollowParams{FollowerID: alice, FolloweeID: bob, Status: "accepted"})
+	if err != nil {
+		t.Fatalf("CreateFollow returned error: %v", err)
+	}
+	report, err := q.CreateReport(ctx, database.CreateReportParams{
+		ReporterID:   uuid.NullUUID{UUID: alice, Valid: true},
+		TargetUserID: bob,
+		Reason:       "spam",
+		Details:      "",
+	})
+	if err != nil {
+		t.Fatalf("CreateReport returned error: %v", err)
+	}
+
+	// A user is only deleted once their deletion is due.
+	_, err = q.ScheduleUserDeletion(ctx, alice)
+	if err != nil {
+		t.Fatalf("ScheduleUserDeletion returned error: %v", err)
+	}
+	if n, err := q.DeleteUser(ctx, alice); err != nil || n != 0 {
+		t.Fatalf("Want no deletion before it is due, got %d, %v", n, err)
+	}
+	_, err = db.Exec("UPDATE users SET deletion_scheduled_at = NOW() - interval '1 second' WHERE id = $1", alice)
+	if err != nil {
+		t.Fatalf("Error making deletion due: %v", err)
+	}
+	if n, err := q.DeleteUser(ctx, alice); err != nil || n != 1 {
+		t.Fatalf("Want alice deleted, got %d, %v", n, err)
+	}
+
+	for _, table := range []struct{ name, query string }{
+		{"chirps", "SELECT COUNT(*) FROM chirps WHERE user_id = $1"},
+		{"refresh_tokens", "SELECT COUNT(*) FROM refresh_tokens WHERE user_id = $1"},
+		{"bookmarks", "SELECT COUNT(*) FROM bookmarks WHERE user_id = $1 OR chirp_id IN (SELECT id FROM chirps WHERE user_id = $1)"},
+		{"blocks", "SELECT COUNT(*) FROM blocks WHERE blocked_id = $1"},
+		{"follows", "SELECT COUNT(*) FROM follows WHERE follower_id = $1"},
+		{"subscriptions", "SELECT COUNT(*) FROM subscriptions WHERE user_id = $1"},
+		{"entitlement_overrides", "SELECT COUNT(*) FROM entitlement_overrides WHERE user_id = $1"},
+	} {
+		if n := count(t, db, table.query, alice); n != 0 {
+			t.Errorf("Want alice's %s deleted, got %d left", table.name, n)
+		}
+	}
+	if n := count(t, db, "SELECT COUNT(*) FROM bookmarks"); n != 0 {
+		t.Errorf("Want bookmarks of alice's chirp deleted, got %d left", n)
+	}
+
+	// Reports outlive the reporter, as they are about someone else.
+	got, err := q.GetReport(ctx, report.ID)
+	if err != nil {
+		t.Fatalf("GetReport returned error: %v", err)
+	}
+	if got.ReporterID.Valid {
+		t.Errorf("Want the reporter cleared, got %v", got.ReporterID)
+	}
+	if _, err := q.GetChirp(ctx, bobChirp.ID); err != nil {
+		t.Errorf("Want bob's chirp kept, got %v", err)
+	}
+}
+
+// TestWebhookEventDeduplication checks that a redelivered event is not
+// recorded twice, which is how Polka retries are made harmless.
+func TestWebhookEventDeduplication(t *testing.T) {
+	db, _ := migratedDB(t)
+	q := database.New(db)
+	ctx := context.Background()
+
+	params := database.CreateWebhookEventParams{
+		Provider:  "polka",
+		EventID:   "evt_1",
+		EventType: "user.upgraded",
+		Payload:   json.RawMessage(`{}`),
+	}
+	_, err := q.CreateWebhookEvent(ctx, params)
+	if err != nil {
+		t.Fatalf("CreateWebhookEvent returned error: %v", err)
+	}
+	_, err = q.CreateWebhookEvent(ctx, params)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Want no rows recording the same event twice, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+// Package logging sets up JSON logging with log/slog, with a level that
+// can be set for each part of the server separately.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// Levels is the minimum level logged by each named logger, and by every
+// logger not named.
+type Levels struct {
+	Default slog.Level
+	Named   map[string]slog.Level
+}
+
+// ParseLevels reads a comma-separated list of levels such as
+// "info,http=debug,worker=warn", where the entry without a name sets the
+// default. Levels are debug, info, warn or error.
+func ParseLevels(spec string) (Levels, error) {
+	levels := Levels{Default: slog.LevelInfo, Named: map[string]slog.Level{}}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, levelText, named := strings.Cut(entry, "=")
+		if !named {
+			levelText = name
+		}
+
+		var level slog.Level
+		err := level.UnmarshalText([]byte(strings.TrimSpace(levelText)))
+		if err != nil {
+			return Levels{}, fmt.Errorf("unknown log level %q", levelText)
+		}
+
+		if named {
+			levels.Named[strings.TrimSpace(name)] = level
+		} else {
+			levels.Default = level
+		}
+	}
+	return levels, nil
+}
+
+func (l Levels) For(name string) slog.Level {
+	if level, ok := l.Named[name]; ok {
+		return level
+	}
+	return l.Default
+}
+
+// String returns the levels in the form ParseLevels reads.
+func (l Levels) String() string {
+	entries := []string{strings.ToLower(l.Default.String())}
+	var names []string
+	for name := range l.Named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		entries = append(entries, name+"="+strings.ToLower(l.Named[name].String()))
+	}
+	return strings.Join(entries, ",")
+}
+
+// Loggers hands out named loggers that write JSON lines to the same
+// writer.
+type Loggers struct {
+	handler slog.Handler
+	levels  Levels
+}
+
+func New(w io.Writer, levels Levels) *Loggers {
+	// Levels are filtered per logger, so the shared handler passes
+	// everything through.
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.Level(-100)})
+	return &Loggers{handler: handler, levels: levels}
+}
+
+// Logger returns the logger for name, which is added to every record as
+// the "logger" attribute.
+func (l *Loggers) Logger(name string) *slog.Logger {
+	return slog.New(levelHandler{
+		level:   l.levels.For(name),
+		Handler: l.handler.WithAttrs([]slog.Attr{slog.String("logger", name)}),
+	})
+}
+
+type levelHandler struct {
+	level slog.Level
+	slog.Handler
+}
+
+func (h levelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return levelHandler{level: h.level, Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h levelHandler) WithGroup(name string) slog.Handler {
+	return levelHandler{level: h.level, Handler: h.Handler.WithGroup(name)}
+}
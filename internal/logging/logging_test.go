@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels("warn, http=debug,worker=ERROR")
+	if err != nil {
+		t.Fatalf("ParseLevels returned error: %v", err)
+	}
+
+	tests := map[string]slog.Level{
+		"http":   slog.LevelDebug,
+		"worker": slog.LevelError,
+		"audit":  slog.LevelWarn,
+	}
+	for name, want := range tests {
+		if got := levels.For(name); got != want {
+			t.Errorf("%s: want %v, got %v", name, want, got)
+		}
+	}
+
+	if got := levels.String(); got != "warn,http=debug,worker=error" {
+		t.Errorf("Want levels to round trip, got %q", got)
+	}
+}
+
+func TestParseLevelsDefault(t *testing.T) {
+	levels, err := ParseLevels("")
+	if err != nil {
+		t.Fatalf("ParseLevels returned error: %v", err)
+	}
+	if levels.For("http") != slog.LevelInfo {
+		t.Errorf("Want info by default, got %v", levels.For("http"))
+	}
+}
+
+func TestParseLevelsInvalid(t *testing.T) {
+	for _, spec := range []string{"loud", "http=verbose"} {
+		_, err := ParseLevels(spec)
+		if err == nil {
+			t.Errorf("%q: want error, got nil", spec)
+		}
+	}
+}
+
+func TestLoggerLevels(t *testing.T) {
+	levels, err := ParseLevels("info,http=debug,worker=error")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	loggers := New(&buf, levels)
+	loggers.Logger("http").Debug("request", "status", 200)
+	loggers.Logger("worker").Warn("slow run")
+	loggers.Logger("audit").With("action", "auth.login").Info("recorded")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Want 2 lines, got %d:\n%s", len(lines), buf.String())
+	}
+
+	var first map[string]any
+	err = json.Unmarshal([]byte(lines[0]), &first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first["logger"] != "http" || first["msg"] != "request" || first["status"] != float64(200) {
+		t.Errorf("Unexpected record: %v", first)
+	}
+	if !strings.Contains(lines[1], `"logger":"audit"`) || !strings.Contains(lines[1], `"action":"auth.login"`) {
+		t.Errorf("Unexpected record: %s", lines[1])
+	}
+}
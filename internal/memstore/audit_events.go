@@ -0,0 +1,140 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/joshckidd/chirpy/internal/database"
+	"github.com/lib/pq"
+)
+
+// LockAuditChain does nothing: transactions already run one at a time.
+func (q queries) LockAuditChain(ctx context.Context) error {
+	_, _, done := q.stmt()
+	defer done()
+
+	return nil
+}
+
+func (q queries) GetLastAuditHash(ctx context.Context) (string, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	return st.auditHead, nil
+}
+
+func (q queries) SetAuditChainHead(ctx context.Context, headHash string) error {
+	st, _, done := q.stmt()
+	defer done()
+
+	st.auditHead = headHash
+	return nil
+}
+
+// AllowAuditPrune lets DeleteAuditEventsBefore run until the transaction
+// ends.
+func (q queries) AllowAuditPrune(ctx context.Context) error {
+	st, _, done := q.stmt()
+	defer done()
+
+	st.auditPrune = true
+	return nil
+}
+
+func (q queries) GetFirstAuditPrevHash(ctx context.Context) (string, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	events := st.auditEvents.where(all)
+	if len(events) == 0 {
+		return "", sql.ErrNoRows
+	}
+	return events[0].PrevHash, nil
+}
+
+func (q queries) GetLatestAuditPrune(ctx context.Context) (database.AuditEvent, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	events := st.auditEvents.where(func(e database.AuditEvent) bool { return e.Action == "audit.pruned" })
+	if len(events) == 0 {
+		return database.AuditEvent{}, sql.ErrNoRows
+	}
+	return events[len(events)-1], nil
+}
+
+func (q queries) CreateAuditEvent(ctx context.Context, arg database.CreateAuditEventParams) (database.AuditEvent, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	*st.auditSeq++
+	e := database.AuditEvent{
+		ID:         *st.auditSeq,
+		CreatedAt:  timestamp(arg.CreatedAt),
+		Action:     arg.Action,
+		ActorID:    arg.ActorID,
+		TargetType: arg.TargetType,
+		TargetID:   arg.TargetID,
+		Ip:         arg.Ip,
+		UserAgent:  arg.UserAgent,
+		RequestID:  arg.RequestID,
+		Metadata:   slices.Clone(arg.Metadata),
+		PrevHash:   arg.PrevHash,
+		Hash:       arg.Hash,
+	}
+	st.auditEvents.put(e.ID, e)
+	return e, nil
+}
+
+func (q queries) GetAuditEvents(ctx context.Context, arg database.GetAuditEventsParams) ([]database.AuditEvent, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	since := timestamp(arg.Since.Time)
+	until := timestamp(arg.Until.Time)
+	events := st.auditEvents.where(func(e database.AuditEvent) bool {
+		return (!arg.ActorID.Valid || e.ActorID == arg.ActorID) &&
+			(!arg.Action.Valid || e.Action == arg.Action.String) &&
+			(!arg.TargetID.Valid || e.TargetID == arg.TargetID.String) &&
+			(!arg.Since.Valid || !e.CreatedAt.Before(since)) &&
+			(!arg.Until.Valid || e.CreatedAt.Before(until))
+	})
+	slices.Reverse(events)
+	return page(events, arg.Lim, arg.Off), nil
+}
+
+func (q queries) GetAuditEventsAfter(ctx context.Context, arg database.GetAuditEventsAfterParams) ([]database.AuditEvent, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	events := st.auditEvents.where(func(e database.AuditEvent) bool { return e.ID > arg.ID })
+	return page(events, arg.Limit, 0), nil
+}
+
+func (q queries) DeleteAuditEventsBefore(ctx context.Context, createdAt time.Time) (int64, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	if !st.auditPrune {
+		return 0, &pq.Error{
+			Code:    "P0001",
+			Message: "audit_events can only be deleted by the retention policy",
+			Table:   "audit_events",
+		}
+	}
+
+	// Only the oldest events up to the first one that is kept are deleted,
+	// so the chain is never left with a gap in the middle.
+	before := timestamp(createdAt)
+	events := st.auditEvents.where(all)
+	cut := slices.IndexFunc(events, func(e database.AuditEvent) bool { return !e.CreatedAt.Before(before) })
+	if cut < 0 {
+		cut = len(events)
+	}
+	for _, e := range events[:cut] {
+		st.auditEvents.delete(e.ID)
+	}
+	return int64(cut), nil
+}
@@ -0,0 +1,89 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func (q queries) CreateBlock(ctx context.Context, arg database.CreateBlockParams) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	key := pair{arg.BlockerID, arg.BlockedID}
+	if st.blocks.has(key) {
+		return nil
+	}
+	if !st.users.has(arg.BlockerID) {
+		return foreignKeyViolation("blocks", "blocks_blocker_id_fkey")
+	}
+	if !st.users.has(arg.BlockedID) {
+		return foreignKeyViolation("blocks", "blocks_blocked_id_fkey")
+	}
+	st.blocks.put(key, database.Block{BlockerID: arg.BlockerID, BlockedID: arg.BlockedID, CreatedAt: now})
+	return nil
+}
+
+func (q queries) DeleteBlock(ctx context.Context, arg database.DeleteBlockParams) error {
+	st, _, done := q.stmt()
+	defer done()
+
+	st.blocks.delete(pair{arg.BlockerID, arg.BlockedID})
+	return nil
+}
+
+func (q queries) GetBlocks(ctx context.Context, arg database.GetBlocksParams) ([]database.Block, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	blocks := st.blocks.where(func(b database.Block) bool { return b.BlockerID == arg.BlockerID })
+	slices.SortStableFunc(blocks, func(a, b database.Block) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return page(blocks, arg.Limit, arg.Offset), nil
+}
+
+func (q queries) IsBlocked(ctx context.Context, arg database.IsBlockedParams) (bool, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	return st.blocks.has(pair{arg.UserID, arg.OtherUserID}) || st.blocks.has(pair{arg.OtherUserID, arg.UserID}), nil
+}
+
+func (q queries) CreateMute(ctx context.Context, arg database.CreateMuteParams) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	key := pair{arg.MuterID, arg.MutedID}
+	if st.mutes.has(key) {
+		return nil
+	}
+	if !st.users.has(arg.MuterID) {
+		return foreignKeyViolation("mutes", "mutes_muter_id_fkey")
+	}
+	if !st.users.has(arg.MutedID) {
+		return foreignKeyViolation("mutes", "mutes_muted_id_fkey")
+	}
+	st.mutes.put(key, database.Mute{MuterID: arg.MuterID, MutedID: arg.MutedID, CreatedAt: now})
+	return nil
+}
+
+func (q queries) DeleteMute(ctx context.Context, arg database.DeleteMuteParams) error {
+	st, _, done := q.stmt()
+	defer done()
+
+	st.mutes.delete(pair{arg.MuterID, arg.MutedID})
+	return nil
+}
+
+func (q queries) GetMutes(ctx context.Context, arg database.GetMutesParams) ([]database.Mute, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	mutes := st.mutes.where(func(m database.Mute) bool { return m.MuterID == arg.MuterID })
+	slices.SortStableFunc(mutes, func(a, b database.Mute) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return page(mutes, arg.Limit, arg.Offset), nil
+}
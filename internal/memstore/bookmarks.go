@@ -0,0 +1,65 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func (q queries) CreateBookmark(ctx context.Context, arg database.CreateBookmarkParams) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	key := pair{arg.UserID, arg.ChirpID}
+	if st.bookmarks.has(key) {
+		return nil
+	}
+	if !st.users.has(arg.UserID) {
+		return foreignKeyViolation("bookmarks", "bookmarks_user_id_fkey")
+	}
+	if !st.chirps.has(arg.ChirpID) {
+		return foreignKeyViolation("bookmarks", "bookmarks_chirp_id_fkey")
+	}
+	st.bookmarks.put(key, database.Bookmark{UserID: arg.UserID, ChirpID: arg.ChirpID, CreatedAt: now})
+	return nil
+}
+
+func (q queries) DeleteBookmark(ctx context.Context, arg database.DeleteBookmarkParams) error {
+	st, _, done := q.stmt()
+	defer done()
+
+	st.bookmarks.delete(pair{arg.UserID, arg.ChirpID})
+	return nil
+}
+
+func (q queries) GetBookmarkedChirpIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	var ids []uuid.UUID
+	for _, b := range st.bookmarks.where(func(b database.Bookmark) bool { return b.UserID == userID }) {
+		ids = append(ids, b.ChirpID)
+	}
+	return ids, nil
+}
+
+func (q queries) GetBookmarkedChirps(ctx context.Context, arg database.GetBookmarkedChirpsParams) ([]database.Chirp, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	bookmarks := st.bookmarks.where(func(b database.Bookmark) bool { return b.UserID == arg.UserID })
+	slices.SortStableFunc(bookmarks, func(a, b database.Bookmark) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	var chirps []database.Chirp
+	for _, b := range bookmarks {
+		c, ok := st.chirps.get(b.ChirpID)
+		if ok && st.canSeeChirp(arg.UserID, c, now) {
+			chirps = append(chirps, c)
+		}
+	}
+	return page(chirps, arg.Limit, arg.Offset), nil
+}
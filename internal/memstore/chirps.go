@@ -0,0 +1,114 @@
+package memstore
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func (q queries) CreateChirp(ctx context.Context, arg database.CreateChirpParams) (database.Chirp, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	if !st.users.has(arg.UserID) {
+		return database.Chirp{}, foreignKeyViolation("chirps", "chirps_user_id_fkey")
+	}
+	c := database.Chirp{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Body:      arg.Body,
+		UserID:    arg.UserID,
+	}
+	st.chirps.put(c.ID, c)
+	return c, nil
+}
+
+func (q queries) GetAllChirps(ctx context.Context, viewerID uuid.UUID) ([]database.Chirp, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	chirps := st.chirps.where(func(c database.Chirp) bool {
+		return st.canSeeChirp(viewerID, c, now) && !st.mutes.has(pair{viewerID, c.UserID})
+	})
+	sortByCreatedAt(chirps)
+	return chirps, nil
+}
+
+func (q queries) GetChirp(ctx context.Context, id uuid.UUID) (database.Chirp, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	c, ok := st.chirps.get(id)
+	if !ok {
+		return database.Chirp{}, sql.ErrNoRows
+	}
+	return c, nil
+}
+
+func (q queries) DeleteChirp(ctx context.Context, id uuid.UUID) error {
+	st, _, done := q.stmt()
+	defer done()
+
+	st.deleteChirps(func(c database.Chirp) bool { return c.ID == id })
+	return nil
+}
+
+func (q queries) GetChirpsForUser(ctx context.Context, arg database.GetChirpsForUserParams) ([]database.Chirp, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	chirps := st.chirps.where(func(c database.Chirp) bool {
+		return c.UserID == arg.UserID && st.canSeeChirp(arg.ViewerID, c, now)
+	})
+	sortByCreatedAt(chirps)
+	return chirps, nil
+}
+
+func (q queries) CountChirpsInLastHour(ctx context.Context, userID uuid.UUID) (int64, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	since := now.Add(-time.Hour)
+	chirps := st.chirps.where(func(c database.Chirp) bool {
+		return c.UserID == userID && c.CreatedAt.After(since)
+	})
+	return int64(len(chirps)), nil
+}
+
+// canSeeChirp is the visibility check the chirp listing queries share:
+// nobody sees chirps across a block, protected users' chirps are only for
+// accepted followers, and suspended or shadow limited users' chirps are
+// only for themselves.
+func (s *state) canSeeChirp(viewerID uuid.UUID, c database.Chirp, now time.Time) bool {
+	if s.blocks.has(pair{viewerID, c.UserID}) || s.blocks.has(pair{c.UserID, viewerID}) {
+		return false
+	}
+	if c.UserID == viewerID {
+		return true
+	}
+	author, ok := s.users.get(c.UserID)
+	if !ok {
+		return true
+	}
+	if author.Protected && !s.isFollowing(viewerID, c.UserID) {
+		return false
+	}
+	return !hiddenBySuspension(author, now)
+}
+
+func sortByCreatedAt(chirps []database.Chirp) {
+	slices.SortStableFunc(chirps, func(a, b database.Chirp) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+}
+
+// compareIDs orders UUIDs the way Postgres does, byte by byte.
+func compareIDs(a, b uuid.UUID) int {
+	return bytes.Compare(a[:], b[:])
+}
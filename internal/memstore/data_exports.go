@@ -0,0 +1,192 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+// exportLease is how long a pending export is left before another worker
+// may claim it.
+const exportLease = 15 * time.Minute
+
+// CreateDataExport returns sql.ErrNoRows for a user who already has a
+// pending export, as ON CONFLICT DO NOTHING returns no row.
+func (q queries) CreateDataExport(ctx context.Context, userID uuid.UUID) (database.DataExport, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	if !st.users.has(userID) {
+		return database.DataExport{}, foreignKeyViolation("data_exports", "data_exports_user_id_fkey")
+	}
+	pending := st.dataExports.where(func(e database.DataExport) bool {
+		return e.UserID == userID && e.Status == "pending"
+	})
+	if len(pending) > 0 {
+		return database.DataExport{}, sql.ErrNoRows
+	}
+	e := database.DataExport{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserID:    userID,
+		Status:    "pending",
+	}
+	st.dataExports.put(e.ID, e)
+	return e, nil
+}
+
+func (q queries) GetDataExport(ctx context.Context, id uuid.UUID) (database.DataExport, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	e, ok := st.dataExports.get(id)
+	if !ok {
+		return database.DataExport{}, sql.ErrNoRows
+	}
+	return e, nil
+}
+
+func (q queries) CompleteDataExport(ctx context.Context, arg database.CompleteDataExportParams) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	st.dataExports.update(func(e database.DataExport) bool { return e.ID == arg.ID }, func(e database.DataExport) database.DataExport {
+		e.UpdatedAt = now
+		e.Status = "ready"
+		e.FilePath = arg.FilePath
+		e.ExpiresAt = sql.NullTime{Time: now.AddDate(0, 0, 7), Valid: true}
+		return e
+	})
+	return nil
+}
+
+func (q queries) FailDataExport(ctx context.Context, id uuid.UUID) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	st.dataExports.update(func(e database.DataExport) bool { return e.ID == id }, func(e database.DataExport) database.DataExport {
+		e.UpdatedAt = now
+		e.Status = "failed"
+		return e
+	})
+	return nil
+}
+
+// ClaimAbandonedDataExports claims pending exports that no one has touched
+// for exportLease, such as those whose server restarted while building
+// them.
+func (q queries) ClaimAbandonedDataExports(ctx context.Context, limit int32) ([]database.DataExport, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	abandoned := st.dataExports.where(func(e database.DataExport) bool {
+		return e.Status == "pending" && !e.UpdatedAt.After(now.Add(-exportLease))
+	})
+	slices.SortStableFunc(abandoned, func(a, b database.DataExport) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	abandoned = page(abandoned, limit, 0)
+
+	claimed := make([]database.DataExport, 0, len(abandoned))
+	for _, e := range abandoned {
+		e.UpdatedAt = now
+		st.dataExports.put(e.ID, e)
+		claimed = append(claimed, e)
+	}
+	return claimed, nil
+}
+
+func (q queries) GetDataExportFilesForUser(ctx context.Context, userID uuid.UUID) ([]sql.NullString, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	var paths []sql.NullString
+	for _, e := range st.dataExports.where(func(e database.DataExport) bool { return e.UserID == userID && e.FilePath.Valid }) {
+		paths = append(paths, e.FilePath)
+	}
+	return paths, nil
+}
+
+func (q queries) DeleteExpiredDataExports(ctx context.Context) ([]sql.NullString, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	var paths []sql.NullString
+	for _, e := range st.dataExports.deleteWhere(func(e database.DataExport) bool { return e.ExpiresAt.Valid && !e.ExpiresAt.Time.After(now) }) {
+		paths = append(paths, e.FilePath)
+	}
+	return paths, nil
+}
+
+func (q queries) GetChirpsForExport(ctx context.Context, arg database.GetChirpsForExportParams) ([]database.Chirp, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	after := timestamp(arg.AfterCreatedAt)
+	chirps := st.chirps.where(func(c database.Chirp) bool {
+		return c.UserID == arg.UserID &&
+			(c.CreatedAt.After(after) || (c.CreatedAt.Equal(after) && compareIDs(c.ID, arg.AfterID) > 0))
+	})
+	slices.SortFunc(chirps, func(a, b database.Chirp) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return compareIDs(a.ID, b.ID)
+	})
+	return page(chirps, arg.BatchSize, 0), nil
+}
+
+func (q queries) GetBookmarksForExport(ctx context.Context, arg database.GetBookmarksForExportParams) ([]database.Bookmark, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	after := timestamp(arg.AfterCreatedAt)
+	bookmarks := st.bookmarks.where(func(b database.Bookmark) bool {
+		return b.UserID == arg.UserID &&
+			(b.CreatedAt.After(after) || (b.CreatedAt.Equal(after) && compareIDs(b.ChirpID, arg.AfterChirpID) > 0))
+	})
+	slices.SortFunc(bookmarks, func(a, b database.Bookmark) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return compareIDs(a.ChirpID, b.ChirpID)
+	})
+	return page(bookmarks, arg.BatchSize, 0), nil
+}
+
+func (q queries) GetFollowsForExport(ctx context.Context, followerID uuid.UUID) ([]database.Follow, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	follows := st.follows.where(func(f database.Follow) bool {
+		return f.FollowerID == followerID || f.FolloweeID == followerID
+	})
+	sortFollows(follows)
+	return follows, nil
+}
+
+func (q queries) GetRefreshTokensForExport(ctx context.Context, userID uuid.UUID) ([]database.GetRefreshTokensForExportRow, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	tokens := st.refreshTokens.where(func(t database.RefreshToken) bool { return t.UserID == userID })
+	slices.SortStableFunc(tokens, func(a, b database.RefreshToken) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	var rows []database.GetRefreshTokensForExportRow
+	for _, t := range tokens {
+		rows = append(rows, database.GetRefreshTokensForExportRow{
+			CreatedAt: t.CreatedAt,
+			UpdatedAt: t.UpdatedAt,
+			ExpiresAt: t.ExpiresAt,
+			RevokedAt: t.RevokedAt,
+		})
+	}
+	return rows, nil
+}
@@ -0,0 +1,47 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func (q queries) UpsertEntitlementOverride(ctx context.Context, arg database.UpsertEntitlementOverrideParams) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	o, ok := st.entitlementOverrides.get(arg.UserID)
+	if !ok {
+		if !st.users.has(arg.UserID) {
+			return foreignKeyViolation("entitlement_overrides", "entitlement_overrides_user_id_fkey")
+		}
+		o = database.EntitlementOverride{UserID: arg.UserID, CreatedAt: now}
+	}
+	o.UpdatedAt = now
+	o.Overrides = slices.Clone(arg.Overrides)
+	st.entitlementOverrides.put(o.UserID, o)
+	return nil
+}
+
+func (q queries) GetEntitlementOverride(ctx context.Context, userID uuid.UUID) (json.RawMessage, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	o, ok := st.entitlementOverrides.get(userID)
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return o.Overrides, nil
+}
+
+func (q queries) DeleteEntitlementOverride(ctx context.Context, userID uuid.UUID) error {
+	st, _, done := q.stmt()
+	defer done()
+
+	st.entitlementOverrides.delete(userID)
+	return nil
+}
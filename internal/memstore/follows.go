@@ -0,0 +1,151 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func (q queries) CreateFollow(ctx context.Context, arg database.CreateFollowParams) (int64, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	key := pair{arg.FollowerID, arg.FolloweeID}
+	if !oneOf(arg.Status, "pending", "accepted") {
+		return 0, checkViolation("follows", "follows_status_check")
+	}
+	if st.follows.has(key) {
+		return 0, nil
+	}
+	if !st.users.has(arg.FollowerID) {
+		return 0, foreignKeyViolation("follows", "follows_follower_id_fkey")
+	}
+	if !st.users.has(arg.FolloweeID) {
+		return 0, foreignKeyViolation("follows", "follows_followee_id_fkey")
+	}
+	st.follows.put(key, database.Follow{
+		FollowerID: arg.FollowerID,
+		FolloweeID: arg.FolloweeID,
+		Status:     arg.Status,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	return 1, nil
+}
+
+func (q queries) GetFollow(ctx context.Context, arg database.GetFollowParams) (database.Follow, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	f, ok := st.follows.get(pair{arg.FollowerID, arg.FolloweeID})
+	if !ok {
+		return database.Follow{}, sql.ErrNoRows
+	}
+	return f, nil
+}
+
+func (q queries) DeleteFollow(ctx context.Context, arg database.DeleteFollowParams) error {
+	st, _, done := q.stmt()
+	defer done()
+
+	st.follows.delete(pair{arg.FollowerID, arg.FolloweeID})
+	return nil
+}
+
+func (q queries) DeleteFollowsBetween(ctx context.Context, arg database.DeleteFollowsBetweenParams) error {
+	st, _, done := q.stmt()
+	defer done()
+
+	st.follows.delete(pair{arg.UserID, arg.OtherUserID})
+	st.follows.delete(pair{arg.OtherUserID, arg.UserID})
+	return nil
+}
+
+func (q queries) GetFollowRequests(ctx context.Context, arg database.GetFollowRequestsParams) ([]database.Follow, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	follows := st.follows.where(func(f database.Follow) bool {
+		return f.FolloweeID == arg.FolloweeID && f.Status == "pending"
+	})
+	sortFollows(follows)
+	return page(follows, arg.Limit, arg.Offset), nil
+}
+
+func (q queries) AcceptFollowRequest(ctx context.Context, arg database.AcceptFollowRequestParams) (int64, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	f, ok := st.follows.get(pair{arg.FollowerID, arg.FolloweeID})
+	if !ok || f.Status != "pending" {
+		return 0, nil
+	}
+	f.UpdatedAt = now
+	f.Status = "accepted"
+	st.follows.put(pair{f.FollowerID, f.FolloweeID}, f)
+	return 1, nil
+}
+
+func (q queries) RejectFollowRequest(ctx context.Context, arg database.RejectFollowRequestParams) (int64, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	key := pair{arg.FollowerID, arg.FolloweeID}
+	f, ok := st.follows.get(key)
+	if !ok || f.Status != "pending" {
+		return 0, nil
+	}
+	st.follows.delete(key)
+	return 1, nil
+}
+
+func (q queries) AcceptAllFollowRequests(ctx context.Context, followeeID uuid.UUID) ([]uuid.UUID, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	updated := st.follows.update(func(f database.Follow) bool {
+		return f.FolloweeID == followeeID && f.Status == "pending"
+	}, func(f database.Follow) database.Follow {
+		f.UpdatedAt = now
+		f.Status = "accepted"
+		return f
+	})
+
+	var ids []uuid.UUID
+	for _, f := range updated {
+		ids = append(ids, f.FollowerID)
+	}
+	return ids, nil
+}
+
+func (q queries) CanViewUser(ctx context.Context, arg database.CanViewUserParams) (bool, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	u, ok := st.users.get(arg.UserID)
+	if !ok {
+		return false, sql.ErrNoRows
+	}
+	if u.ID == arg.ViewerID {
+		return true, nil
+	}
+	if u.Protected && !st.isFollowing(arg.ViewerID, u.ID) {
+		return false, nil
+	}
+	return !hiddenBySuspension(u, now), nil
+}
+
+// isFollowing is whether follower's follow of followee has been accepted.
+func (s *state) isFollowing(followerID, followeeID uuid.UUID) bool {
+	f, ok := s.follows.get(pair{followerID, followeeID})
+	return ok && f.Status == "accepted"
+}
+
+func sortFollows(follows []database.Follow) {
+	slices.SortStableFunc(follows, func(a, b database.Follow) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+}
@@ -0,0 +1,260 @@
+// Package memstore is an in-memory database.Store, so handlers can be
+// tested without Postgres. It keeps the behaviour the handlers rely on:
+// missing rows are sql.ErrNoRows, foreign keys, unique keys and CHECK
+// constraints fail with the *pq.Error Postgres would return, deletes
+// cascade as the schema says, and a transaction's changes only show once
+// it commits.
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+	"github.com/lib/pq"
+)
+
+// Store holds every table in memory. Transactions run one at a time, and
+// a statement outside a transaction waits for the running one to finish,
+// which is stricter than Postgres but never sees a different result.
+type Store struct {
+	queries
+	mu    sync.Mutex
+	state *state
+	clock func() time.Time
+}
+
+var _ database.Store = (*Store)(nil)
+
+func New() *Store {
+	s := &Store{state: newState(), clock: time.Now}
+	s.queries = queries{stmt: s.statement}
+	return s
+}
+
+// SetClock replaces the clock NOW() reads, so tests can move time forward
+// past expiry dates.
+func (s *Store) SetClock(clock func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = clock
+}
+
+// statement runs a statement outside a transaction, which sees and changes
+// the committed state directly.
+func (s *Store) statement() (*state, time.Time, func()) {
+	s.mu.Lock()
+	return s.state, timestamp(s.clock()), s.mu.Unlock
+}
+
+// BeginTx starts a transaction on a copy of the state. NOW() is the time
+// the transaction started for all of its statements, as in Postgres.
+func (s *Store) BeginTx(ctx context.Context) (database.Tx, error) {
+	s.mu.Lock()
+	t := &tx{store: s, state: s.state.clone(), now: timestamp(s.clock())}
+	t.queries = queries{stmt: t.statement}
+	return t, nil
+}
+
+func (s *Store) Ping(ctx context.Context) error {
+	return nil
+}
+
+type tx struct {
+	queries
+	store *Store
+	state *state
+	now   time.Time
+	done  bool
+}
+
+func (t *tx) statement() (*state, time.Time, func()) {
+	if t.done {
+		panic("memstore: statement run in a transaction that has already ended")
+	}
+	return t.state, t.now, func() {}
+}
+
+func (t *tx) Commit() error {
+	if t.done {
+		return fmt.Errorf("memstore: transaction has already been committed or rolled back")
+	}
+	t.done = true
+	t.state.auditPrune = false
+	t.store.state = t.state
+	t.store.mu.Unlock()
+	return nil
+}
+
+// Rollback discards the transaction's changes. Like sql.Tx's, it does
+// nothing once the transaction has ended, so it can be deferred.
+func (t *tx) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	t.store.mu.Unlock()
+	return nil
+}
+
+// queries implements database.Querier for both the store and its
+// transactions. stmt returns the state a statement runs against, its
+// NOW(), and a function to call when the statement is done.
+type queries struct {
+	stmt func() (*state, time.Time, func())
+}
+
+// timestamp converts t the way storing it in a TIMESTAMP column does:
+// the time zone is dropped and only microseconds are kept.
+func timestamp(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC).Truncate(time.Microsecond)
+}
+
+// pair is the key of the tables whose primary key is two user or chirp IDs.
+type pair [2]uuid.UUID
+
+// table is a map that remembers insertion order, which breaks ties when
+// rows are sorted so results don't change from run to run.
+type table[K comparable, V any] struct {
+	keys []K
+	rows map[K]V
+}
+
+func newTable[K comparable, V any]() *table[K, V] {
+	return &table[K, V]{rows: map[K]V{}}
+}
+
+func (t *table[K, V]) get(k K) (V, bool) {
+	v, ok := t.rows[k]
+	return v, ok
+}
+
+func (t *table[K, V]) has(k K) bool {
+	_, ok := t.rows[k]
+	return ok
+}
+
+// put inserts or replaces the row for k.
+func (t *table[K, V]) put(k K, v V) {
+	if _, ok := t.rows[k]; !ok {
+		t.keys = append(t.keys, k)
+	}
+	t.rows[k] = v
+}
+
+func (t *table[K, V]) delete(k K) bool {
+	if _, ok := t.rows[k]; !ok {
+		return false
+	}
+	delete(t.rows, k)
+	t.keys = slices.DeleteFunc(t.keys, func(key K) bool { return key == k })
+	return true
+}
+
+// deleteWhere deletes the rows matching fn and returns them.
+func (t *table[K, V]) deleteWhere(fn func(V) bool) []V {
+	var deleted []V
+	t.keys = slices.DeleteFunc(t.keys, func(k K) bool {
+		v := t.rows[k]
+		if !fn(v) {
+			return false
+		}
+		deleted = append(deleted, v)
+		delete(t.rows, k)
+		return true
+	})
+	return deleted
+}
+
+// update replaces each row matching match with fn's result, and returns
+// the new rows.
+func (t *table[K, V]) update(match func(V) bool, fn func(V) V) []V {
+	var updated []V
+	for _, k := range t.keys {
+		v := t.rows[k]
+		if !match(v) {
+			continue
+		}
+		v = fn(v)
+		t.rows[k] = v
+		updated = append(updated, v)
+	}
+	return updated
+}
+
+// where returns the rows matching fn in insertion order.
+func (t *table[K, V]) where(fn func(V) bool) []V {
+	var rows []V
+	for _, k := range t.keys {
+		if v := t.rows[k]; fn(v) {
+			rows = append(rows, v)
+		}
+	}
+	return rows
+}
+
+func (t *table[K, V]) clone() *table[K, V] {
+	c := &table[K, V]{keys: slices.Clone(t.keys), rows: make(map[K]V, len(t.rows))}
+	for k, v := range t.rows {
+		c.rows[k] = v
+	}
+	return c
+}
+
+func all[V any](V) bool {
+	return true
+}
+
+// page applies LIMIT and OFFSET.
+func page[V any](rows []V, limit, offset int32) []V {
+	if offset < 0 || limit < 0 {
+		return nil
+	}
+	if int(offset) >= len(rows) {
+		return []V{}
+	}
+	rows = rows[offset:]
+	if int(limit) < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+// The errors below are the ones lib/pq returns for the same violations, so
+// code checking for them behaves as it would against Postgres.
+
+func foreignKeyViolation(table, constraint string) error {
+	return &pq.Error{
+		Code:       "23503",
+		Message:    fmt.Sprintf("insert or update on table %q violates foreign key constraint %q", table, constraint),
+		Table:      table,
+		Constraint: constraint,
+	}
+}
+
+func uniqueViolation(table, constraint string) error {
+	return &pq.Error{
+		Code:       "23505",
+		Message:    fmt.Sprintf("duplicate key value violates unique constraint %q", constraint),
+		Table:      table,
+		Constraint: constraint,
+	}
+}
+
+func checkViolation(table, constraint string) error {
+	return &pq.Error{
+		Code:       "23514",
+		Message:    fmt.Sprintf("new row for relation %q violates check constraint %q", table, constraint),
+		Table:      table,
+		Constraint: constraint,
+	}
+}
+
+func oneOf(v string, allowed ...string) bool {
+	return slices.Contains(allowed, v)
+}
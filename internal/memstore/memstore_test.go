@@ -0,0 +1,272 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+	"github.com/lib/pq"
+)
+
+func createUser(t *testing.T, s *Store) uuid.UUID {
+	t.Helper()
+	u, err := s.CreateUser(context.Background(), database.CreateUserParams{Email: "a@example.com", HashedPassword: "x"})
+	if err != nil {
+		t.Fatalf("CreateUser returned error: %v", err)
+	}
+	return u.ID
+}
+
+func TestNotFound(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+
+	_, err := s.GetUser(ctx, uuid.New())
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUser: want sql.ErrNoRows, got %v", err)
+	}
+	_, err = s.GetChirp(ctx, uuid.New())
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetChirp: want sql.ErrNoRows, got %v", err)
+	}
+	_, err = s.GetFirstAuditPrevHash(ctx)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetFirstAuditPrevHash: want sql.ErrNoRows, got %v", err)
+	}
+}
+
+func TestForeignKeyViolation(t *testing.T) {
+	s := New()
+	_, err := s.CreateChirp(context.Background(), database.CreateChirpParams{Body: "hi", UserID: uuid.New()})
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "23503" {
+		t.Fatalf("Want a foreign key violation, got %v", err)
+	}
+	if pqErr.Constraint != "chirps_user_id_fkey" {
+		t.Errorf("Want constraint chirps_user_id_fkey, got %q", pqErr.Constraint)
+	}
+}
+
+func TestCheckViolation(t *testing.T) {
+	s := New()
+	id := createUser(t, s)
+
+	_, err := s.UpdateUserRole(context.Background(), database.UpdateUserRoleParams{Role: "owner", ID: id})
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "23514" {
+		t.Fatalf("Want a check violation, got %v", err)
+	}
+}
+
+func TestTransactionRollback(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+	id := createUser(t, s)
+
+	tx, err := s.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("BeginTx returned error: %v", err)
+	}
+	c, err := tx.CreateChirp(ctx, database.CreateChirpParams{Body: "hi", UserID: id})
+	if err != nil {
+		t.Fatalf("CreateChirp returned error: %v", err)
+	}
+	_, err = tx.GetChirp(ctx, c.ID)
+	if err != nil {
+		t.Errorf("Want the chirp inside the transaction, got %v", err)
+	}
+	err = tx.Rollback()
+	if err != nil {
+		t.Fatalf("Rollback returned error: %v", err)
+	}
+
+	_, err = s.GetChirp(ctx, c.ID)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Want the chirp rolled back, got %v", err)
+	}
+}
+
+func TestTransactionCommit(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+	id := createUser(t, s)
+
+	tx, err := s.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("BeginTx returned error: %v", err)
+	}
+	defer tx.Rollback()
+
+	c, err := tx.CreateChirp(ctx, database.CreateChirpParams{Body: "hi", UserID: id})
+	if err != nil {
+		t.Fatalf("CreateChirp returned error: %v", err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		t.Fatalf("Commit returned error: %v", err)
+	}
+
+	_, err = s.GetChirp(ctx, c.ID)
+	if err != nil {
+		t.Errorf("Want the committed chirp, got %v", err)
+	}
+	if tx.Commit() == nil {
+		t.Errorf("Want an error committing twice")
+	}
+}
+
+func TestDeleteUserCascades(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+	author := createUser(t, s)
+	reporter := createUser(t, s)
+
+	c, err := s.CreateChirp(ctx, database.CreateChirpParams{Body: "hi", UserID: author})
+	if err != nil {
+		t.Fatalf("CreateChirp returned error: %v", err)
+	}
+	err = s.CreateBookmark(ctx, database.CreateBookmarkParams{UserID: reporter, ChirpID: c.ID})
+	if err != nil {
+		t.Fatalf("CreateBookmark returned error: %v", err)
+	}
+	r, err := s.CreateReport(ctx, database.CreateReportParams{
+		ReporterID:   uuid.NullUUID{UUID: reporter, Valid: true},
+		TargetUserID: reporter,
+		Reason:       "spam",
+	})
+	if err != nil {
+		t.Fatalf("CreateReport returned error: %v", err)
+	}
+
+	// The deletion isn't due yet.
+	_, err = s.ScheduleUserDeletion(ctx, author)
+	if err != nil {
+		t.Fatalf("ScheduleUserDeletion returned error: %v", err)
+	}
+	n, err := s.DeleteUser(ctx, author)
+	if err != nil || n != 0 {
+		t.Fatalf("Want nothing deleted yet, got %d, %v", n, err)
+	}
+
+	s.SetClock(func() time.Time { return time.Now().AddDate(0, 0, 31) })
+	n, err = s.DeleteUser(ctx, author)
+	if err != nil || n != 1 {
+		t.Fatalf("Want the user deleted, got %d, %v", n, err)
+	}
+
+	_, err = s.GetChirp(ctx, c.ID)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Want the chirp deleted with its author, got %v", err)
+	}
+	ids, _ := s.GetBookmarkedChirpIDs(ctx, reporter)
+	if len(ids) != 0 {
+		t.Errorf("Want the bookmark deleted with the chirp, got %v", ids)
+	}
+
+	s.ResetUsers(ctx)
+	_, err = s.GetReport(ctx, r.ID)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Want the report deleted with its target, got %v", err)
+	}
+}
+
+func TestCreateWebhookEventConflict(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+	arg := database.CreateWebhookEventParams{Provider: "polka", EventID: "evt_1", EventType: "user.upgraded", Payload: []byte("{}")}
+
+	_, err := s.CreateWebhookEvent(ctx, arg)
+	if err != nil {
+		t.Fatalf("CreateWebhookEvent returned error: %v", err)
+	}
+	_, err = s.CreateWebhookEvent(ctx, arg)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Want sql.ErrNoRows for a duplicate event, got %v", err)
+	}
+}
+
+func TestRefreshTokenExpiry(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+	id := createUser(t, s)
+
+	_, err := s.CreateRefreshToken(ctx, database.CreateRefreshTokenParams{Token: "tok", UserID: id})
+	if err != nil {
+		t.Fatalf("CreateRefreshToken returned error: %v", err)
+	}
+	got, err := s.GetUserFromRefreshToken(ctx, "tok")
+	if err != nil || got != id {
+		t.Fatalf("Want %v, got %v, %v", id, got, err)
+	}
+
+	s.SetClock(func() time.Time { return time.Now().AddDate(0, 0, 61) })
+	_, err = s.GetUserFromRefreshToken(ctx, "tok")
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Want an expired token to be ignored, got %v", err)
+	}
+}
+
+func TestChirpVisibility(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+	author := createUser(t, s)
+	viewer := createUser(t, s)
+
+	_, err := s.CreateChirp(ctx, database.CreateChirpParams{Body: "hi", UserID: author})
+	if err != nil {
+		t.Fatalf("CreateChirp returned error: %v", err)
+	}
+
+	count := func() int {
+		t.Helper()
+		chirps, err := s.GetAllChirps(ctx, viewer)
+		if err != nil {
+			t.Fatalf("GetAllChirps returned error: %v", err)
+		}
+		return len(chirps)
+	}
+
+	if count() != 1 {
+		t.Fatalf("Want a public chirp to be visible")
+	}
+
+	s.UpdateUserProtected(ctx, database.UpdateUserProtectedParams{Protected: true, ID: author})
+	if count() != 0 {
+		t.Errorf("Want a protected user's chirp hidden from non-followers")
+	}
+	s.CreateFollow(ctx, database.CreateFollowParams{FollowerID: viewer, FolloweeID: author, Status: "pending"})
+	if count() != 0 {
+		t.Errorf("Want a protected user's chirp hidden until the follow is accepted")
+	}
+	s.AcceptFollowRequest(ctx, database.AcceptFollowRequestParams{FollowerID: viewer, FolloweeID: author})
+	if count() != 1 {
+		t.Errorf("Want a protected user's chirp visible to followers")
+	}
+
+	s.CreateMute(ctx, database.CreateMuteParams{MuterID: viewer, MutedID: author})
+	if count() != 0 {
+		t.Errorf("Want a muted user's chirp hidden")
+	}
+	s.DeleteMute(ctx, database.DeleteMuteParams{MuterID: viewer, MutedID: author})
+
+	s.CreateBlock(ctx, database.CreateBlockParams{BlockerID: author, BlockedID: viewer})
+	if count() != 0 {
+		t.Errorf("Want a chirp hidden across a block")
+	}
+}
+
+func TestPage(t *testing.T) {
+	rows := []int{1, 2, 3, 4, 5}
+	got := page(rows, 2, 1)
+	if len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Errorf("Want [2 3], got %v", got)
+	}
+	if got := page(rows, 10, 10); len(got) != 0 {
+		t.Errorf("Want no rows past the end, got %v", got)
+	}
+}
@@ -0,0 +1,52 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func (q queries) CreateNotification(ctx context.Context, arg database.CreateNotificationParams) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	if !st.users.has(arg.UserID) {
+		return foreignKeyViolation("notifications", "notifications_user_id_fkey")
+	}
+	n := database.Notification{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UserID:    arg.UserID,
+		Kind:      arg.Kind,
+		Body:      arg.Body,
+	}
+	st.notifications.put(n.ID, n)
+	return nil
+}
+
+func (q queries) GetNotificationsForUser(ctx context.Context, arg database.GetNotificationsForUserParams) ([]database.Notification, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	notifications := st.notifications.where(func(n database.Notification) bool { return n.UserID == arg.UserID })
+	slices.SortStableFunc(notifications, func(a, b database.Notification) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return page(notifications, arg.Limit, arg.Offset), nil
+}
+
+func (q queries) MarkNotificationsRead(ctx context.Context, userID uuid.UUID) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	st.notifications.update(func(n database.Notification) bool {
+		return n.UserID == userID && !n.ReadAt.Valid
+	}, func(n database.Notification) database.Notification {
+		n.ReadAt = sql.NullTime{Time: now, Valid: true}
+		return n
+	})
+	return nil
+}
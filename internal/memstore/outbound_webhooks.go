@@ -0,0 +1,232 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+// claimTimeout is how long a claimed delivery is left before another
+// worker may claim it again.
+const claimTimeout = 5 * time.Minute
+
+func (q queries) CreateWebhookEndpoint(ctx context.Context, arg database.CreateWebhookEndpointParams) (database.WebhookEndpoint, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	if arg.UserID.Valid && !st.users.has(arg.UserID.UUID) {
+		return database.WebhookEndpoint{}, foreignKeyViolation("webhook_endpoints", "webhook_endpoints_user_id_fkey")
+	}
+	e := database.WebhookEndpoint{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserID:    arg.UserID,
+		Url:       arg.Url,
+		Secret:    arg.Secret,
+		Events:    slices.Clone(arg.Events),
+		Active:    true,
+	}
+	st.webhookEndpoints.put(e.ID, e)
+	return e, nil
+}
+
+func (q queries) GetWebhookEndpoint(ctx context.Context, id uuid.UUID) (database.WebhookEndpoint, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	e, ok := st.webhookEndpoints.get(id)
+	if !ok {
+		return database.WebhookEndpoint{}, sql.ErrNoRows
+	}
+	return e, nil
+}
+
+// GetWebhookEndpointsForUser finds nothing for a NULL user ID, as
+// user_id = NULL is never true.
+func (q queries) GetWebhookEndpointsForUser(ctx context.Context, userID uuid.NullUUID) ([]database.WebhookEndpoint, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	endpoints := st.webhookEndpoints.where(func(e database.WebhookEndpoint) bool {
+		return userID.Valid && e.UserID == userID
+	})
+	sortEndpoints(endpoints)
+	return endpoints, nil
+}
+
+func (q queries) GetGlobalWebhookEndpoints(ctx context.Context) ([]database.WebhookEndpoint, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	endpoints := st.webhookEndpoints.where(func(e database.WebhookEndpoint) bool { return !e.UserID.Valid })
+	sortEndpoints(endpoints)
+	return endpoints, nil
+}
+
+func (q queries) DeleteWebhookEndpoint(ctx context.Context, id uuid.UUID) error {
+	st, _, done := q.stmt()
+	defer done()
+
+	st.deleteWebhookEndpoints(func(e database.WebhookEndpoint) bool { return e.ID == id })
+	return nil
+}
+
+func (q queries) CreateOutboxEvent(ctx context.Context, arg database.CreateOutboxEventParams) (database.OutboxEvent, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	if arg.UserID.Valid && !st.users.has(arg.UserID.UUID) {
+		return database.OutboxEvent{}, foreignKeyViolation("outbox_events", "outbox_events_user_id_fkey")
+	}
+	e := database.OutboxEvent{
+		ID:          uuid.New(),
+		CreatedAt:   now,
+		EventType:   arg.EventType,
+		UserID:      arg.UserID,
+		Payload:     slices.Clone(arg.Payload),
+		Traceparent: arg.Traceparent,
+	}
+	st.outboxEvents.put(e.ID, e)
+	return e, nil
+}
+
+func (q queries) GetOutboxEvent(ctx context.Context, id uuid.UUID) (database.OutboxEvent, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	e, ok := st.outboxEvents.get(id)
+	if !ok {
+		return database.OutboxEvent{}, sql.ErrNoRows
+	}
+	return e, nil
+}
+
+// GetUndispatchedOutboxEvents has no need for FOR UPDATE SKIP LOCKED, as
+// only one transaction runs at a time.
+func (q queries) GetUndispatchedOutboxEvents(ctx context.Context, limit int32) ([]database.OutboxEvent, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	events := st.outboxEvents.where(func(e database.OutboxEvent) bool { return !e.DispatchedAt.Valid })
+	slices.SortStableFunc(events, func(a, b database.OutboxEvent) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return page(events, limit, 0), nil
+}
+
+func (q queries) MarkOutboxEventDispatched(ctx context.Context, id uuid.UUID) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	st.outboxEvents.update(func(e database.OutboxEvent) bool { return e.ID == id }, func(e database.OutboxEvent) database.OutboxEvent {
+		e.DispatchedAt = sql.NullTime{Time: now, Valid: true}
+		return e
+	})
+	return nil
+}
+
+func (q queries) CreateWebhookDeliveriesForEvent(ctx context.Context, arg database.CreateWebhookDeliveriesForEventParams) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	endpoints := st.webhookEndpoints.where(func(e database.WebhookEndpoint) bool {
+		return e.Active &&
+			slices.Contains(e.Events, arg.EventType) &&
+			(!e.UserID.Valid || (arg.UserID.Valid && e.UserID.UUID == arg.UserID.UUID))
+	})
+	if len(endpoints) > 0 && !st.outboxEvents.has(arg.EventID) {
+		return foreignKeyViolation("webhook_deliveries", "webhook_deliveries_event_id_fkey")
+	}
+	for _, e := range endpoints {
+		d := database.WebhookDelivery{
+			ID:            uuid.New(),
+			CreatedAt:     now,
+			UpdatedAt:     now,
+			EndpointID:    e.ID,
+			EventID:       arg.EventID,
+			Status:        "pending",
+			NextAttemptAt: now,
+		}
+		st.webhookDeliveries.put(d.ID, d)
+	}
+	return nil
+}
+
+func (q queries) ClaimDueWebhookDeliveries(ctx context.Context, limit int32) ([]database.WebhookDelivery, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	due := st.webhookDeliveries.where(func(d database.WebhookDelivery) bool {
+		return d.Status == "pending" && !d.NextAttemptAt.After(now)
+	})
+	slices.SortStableFunc(due, func(a, b database.WebhookDelivery) int {
+		return a.NextAttemptAt.Compare(b.NextAttemptAt)
+	})
+	due = page(due, limit, 0)
+
+	claimed := make([]database.WebhookDelivery, 0, len(due))
+	for _, d := range due {
+		d.UpdatedAt = now
+		d.NextAttemptAt = now.Add(claimTimeout)
+		st.webhookDeliveries.put(d.ID, d)
+		claimed = append(claimed, d)
+	}
+	return claimed, nil
+}
+
+func (q queries) MarkWebhookDeliverySucceeded(ctx context.Context, arg database.MarkWebhookDeliverySucceededParams) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	st.webhookDeliveries.update(func(d database.WebhookDelivery) bool { return d.ID == arg.ID }, func(d database.WebhookDelivery) database.WebhookDelivery {
+		d.UpdatedAt = now
+		d.Status = "succeeded"
+		d.Attempts++
+		d.LastStatusCode = arg.LastStatusCode
+		d.LastError = sql.NullString{}
+		d.DeliveredAt = sql.NullTime{Time: now, Valid: true}
+		return d
+	})
+	return nil
+}
+
+func (q queries) MarkWebhookDeliveryFailed(ctx context.Context, arg database.MarkWebhookDeliveryFailedParams) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	if !oneOf(arg.Status, "pending", "succeeded", "dead") && st.webhookDeliveries.has(arg.ID) {
+		return checkViolation("webhook_deliveries", "webhook_deliveries_status_check")
+	}
+	st.webhookDeliveries.update(func(d database.WebhookDelivery) bool { return d.ID == arg.ID }, func(d database.WebhookDelivery) database.WebhookDelivery {
+		d.UpdatedAt = now
+		d.Status = arg.Status
+		d.Attempts++
+		d.LastStatusCode = arg.LastStatusCode
+		d.LastError = arg.LastError
+		d.NextAttemptAt = timestamp(now.Add(time.Duration(arg.BackoffSeconds * float64(time.Second))))
+		return d
+	})
+	return nil
+}
+
+func (q queries) GetWebhookDeliveriesForEndpoint(ctx context.Context, arg database.GetWebhookDeliveriesForEndpointParams) ([]database.WebhookDelivery, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	deliveries := st.webhookDeliveries.where(func(d database.WebhookDelivery) bool { return d.EndpointID == arg.EndpointID })
+	slices.SortStableFunc(deliveries, func(a, b database.WebhookDelivery) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return page(deliveries, arg.Limit, arg.Offset), nil
+}
+
+func sortEndpoints(endpoints []database.WebhookEndpoint) {
+	slices.SortStableFunc(endpoints, func(a, b database.WebhookEndpoint) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+}
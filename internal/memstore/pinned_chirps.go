@@ -0,0 +1,51 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func (q queries) PinChirp(ctx context.Context, arg database.PinChirpParams) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	key := pair{arg.UserID, arg.ChirpID}
+	if st.pinnedChirps.has(key) {
+		return nil
+	}
+	if !st.users.has(arg.UserID) {
+		return foreignKeyViolation("pinned_chirps", "pinned_chirps_user_id_fkey")
+	}
+	if !st.chirps.has(arg.ChirpID) {
+		return foreignKeyViolation("pinned_chirps", "pinned_chirps_chirp_id_fkey")
+	}
+	st.pinnedChirps.put(key, database.PinnedChirp{UserID: arg.UserID, ChirpID: arg.ChirpID, CreatedAt: now})
+	return nil
+}
+
+func (q queries) UnpinChirp(ctx context.Context, arg database.UnpinChirpParams) error {
+	st, _, done := q.stmt()
+	defer done()
+
+	st.pinnedChirps.delete(pair{arg.UserID, arg.ChirpID})
+	return nil
+}
+
+func (q queries) GetPinnedChirpIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	pinned := st.pinnedChirps.where(func(p database.PinnedChirp) bool { return p.UserID == userID })
+	slices.SortStableFunc(pinned, func(a, b database.PinnedChirp) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	var ids []uuid.UUID
+	for _, p := range pinned {
+		ids = append(ids, p.ChirpID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,70 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func (q queries) CreateRefreshToken(ctx context.Context, arg database.CreateRefreshTokenParams) (database.RefreshToken, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	if st.refreshTokens.has(arg.Token) {
+		return database.RefreshToken{}, uniqueViolation("refresh_tokens", "refresh_tokens_pkey")
+	}
+	if !st.users.has(arg.UserID) {
+		return database.RefreshToken{}, foreignKeyViolation("refresh_tokens", "refresh_tokens_user_id_fkey")
+	}
+	t := database.RefreshToken{
+		Token:     arg.Token,
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserID:    arg.UserID,
+		ExpiresAt: now.AddDate(0, 0, 60),
+	}
+	st.refreshTokens.put(t.Token, t)
+	return t, nil
+}
+
+func (q queries) GetUserFromRefreshToken(ctx context.Context, token string) (uuid.UUID, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	t, ok := st.refreshTokens.get(token)
+	if !ok || t.RevokedAt.Valid || !t.ExpiresAt.After(now) {
+		return uuid.Nil, sql.ErrNoRows
+	}
+	return t.UserID, nil
+}
+
+func (q queries) RevokeRefreshToken(ctx context.Context, token string) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	st.refreshTokens.update(func(t database.RefreshToken) bool {
+		return t.Token == token
+	}, revoke(now))
+	return nil
+}
+
+func (q queries) RevokeAllRefreshTokensForUser(ctx context.Context, userID uuid.UUID) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	st.refreshTokens.update(func(t database.RefreshToken) bool {
+		return t.UserID == userID && !t.RevokedAt.Valid
+	}, revoke(now))
+	return nil
+}
+
+func revoke(now time.Time) func(database.RefreshToken) database.RefreshToken {
+	return func(t database.RefreshToken) database.RefreshToken {
+		t.UpdatedAt = now
+		t.RevokedAt = sql.NullTime{Time: now, Valid: true}
+		return t
+	}
+}
@@ -0,0 +1,133 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+var reportReasons = []string{"spam", "harassment", "hate", "violence", "sexual", "self_harm", "impersonation", "other"}
+
+func (q queries) CreateReport(ctx context.Context, arg database.CreateReportParams) (database.Report, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	switch {
+	case !slices.Contains(reportReasons, arg.Reason):
+		return database.Report{}, checkViolation("reports", "reports_reason_check")
+	case arg.ReporterID.Valid && !st.users.has(arg.ReporterID.UUID):
+		return database.Report{}, foreignKeyViolation("reports", "reports_reporter_id_fkey")
+	case !st.users.has(arg.TargetUserID):
+		return database.Report{}, foreignKeyViolation("reports", "reports_target_user_id_fkey")
+	case arg.TargetChirpID.Valid && !st.chirps.has(arg.TargetChirpID.UUID):
+		return database.Report{}, foreignKeyViolation("reports", "reports_target_chirp_id_fkey")
+	}
+	r := database.Report{
+		ID:            uuid.New(),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		ReporterID:    arg.ReporterID,
+		TargetUserID:  arg.TargetUserID,
+		TargetChirpID: arg.TargetChirpID,
+		Reason:        arg.Reason,
+		Details:       arg.Details,
+		State:         "open",
+	}
+	st.reports.put(r.ID, r)
+	return r, nil
+}
+
+func (q queries) GetReport(ctx context.Context, id uuid.UUID) (database.Report, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	r, ok := st.reports.get(id)
+	if !ok {
+		return database.Report{}, sql.ErrNoRows
+	}
+	return r, nil
+}
+
+func (q queries) GetReports(ctx context.Context, arg database.GetReportsParams) ([]database.Report, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	reports := st.reports.where(all)
+	sortReports(reports)
+	return page(reports, arg.Limit, arg.Offset), nil
+}
+
+func (q queries) GetReportsByState(ctx context.Context, arg database.GetReportsByStateParams) ([]database.Report, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	reports := st.reports.where(func(r database.Report) bool { return r.State == arg.State })
+	sortReports(reports)
+	return page(reports, arg.Limit, arg.Offset), nil
+}
+
+func (q queries) CloseReport(ctx context.Context, arg database.CloseReportParams) (int64, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	r, ok := st.reports.get(arg.ID)
+	if !ok || r.State != "open" {
+		return 0, nil
+	}
+	if !oneOf(arg.State, "open", "resolved", "dismissed") {
+		return 0, checkViolation("reports", "reports_state_check")
+	}
+	r.UpdatedAt = now
+	r.State = arg.State
+	st.reports.put(r.ID, r)
+	return 1, nil
+}
+
+func (q queries) CreateModerationAction(ctx context.Context, arg database.CreateModerationActionParams) (database.ModerationAction, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	switch {
+	case !oneOf(arg.Action, "dismiss", "delete_chirp", "warn", "suspend", "read_only", "shadow_limit", "lift_suspension"):
+		return database.ModerationAction{}, checkViolation("moderation_actions", "moderation_actions_action_check")
+	case arg.ReportID.Valid && !st.reports.has(arg.ReportID.UUID):
+		return database.ModerationAction{}, foreignKeyViolation("moderation_actions", "moderation_actions_report_id_fkey")
+	case arg.ModeratorID.Valid && !st.users.has(arg.ModeratorID.UUID):
+		return database.ModerationAction{}, foreignKeyViolation("moderation_actions", "moderation_actions_moderator_id_fkey")
+	case !st.users.has(arg.TargetUserID):
+		return database.ModerationAction{}, foreignKeyViolation("moderation_actions", "moderation_actions_target_user_id_fkey")
+	}
+	a := database.ModerationAction{
+		ID:           uuid.New(),
+		CreatedAt:    now,
+		ReportID:     arg.ReportID,
+		ModeratorID:  arg.ModeratorID,
+		Action:       arg.Action,
+		Reason:       arg.Reason,
+		TargetUserID: arg.TargetUserID,
+	}
+	st.moderationActions.put(a.ID, a)
+	return a, nil
+}
+
+func (q queries) GetModerationActionsForReport(ctx context.Context, reportID uuid.NullUUID) ([]database.ModerationAction, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	actions := st.moderationActions.where(func(a database.ModerationAction) bool {
+		return reportID.Valid && a.ReportID == reportID
+	})
+	slices.SortStableFunc(actions, func(a, b database.ModerationAction) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return actions, nil
+}
+
+func sortReports(reports []database.Report) {
+	slices.SortStableFunc(reports, func(a, b database.Report) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+}
@@ -0,0 +1,161 @@
+package memstore
+
+import (
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+// state is every table. Rows are stored by value and slices in them are
+// never changed in place, so copying the tables copies the state.
+type state struct {
+	users                *table[uuid.UUID, database.User]
+	chirps               *table[uuid.UUID, database.Chirp]
+	refreshTokens        *table[string, database.RefreshToken]
+	bookmarks            *table[pair, database.Bookmark]
+	pinnedChirps         *table[pair, database.PinnedChirp]
+	blocks               *table[pair, database.Block]
+	mutes                *table[pair, database.Mute]
+	follows              *table[pair, database.Follow]
+	accountDeletions     *table[uuid.UUID, database.AccountDeletion]
+	dataExports          *table[uuid.UUID, database.DataExport]
+	webhookEvents        *table[uuid.UUID, database.WebhookEvent]
+	subscriptions        *table[uuid.UUID, database.Subscription]
+	entitlementOverrides *table[uuid.UUID, database.EntitlementOverride]
+	webhookEndpoints     *table[uuid.UUID, database.WebhookEndpoint]
+	outboxEvents         *table[uuid.UUID, database.OutboxEvent]
+	webhookDeliveries    *table[uuid.UUID, database.WebhookDelivery]
+	reports              *table[uuid.UUID, database.Report]
+	moderationActions    *table[uuid.UUID, database.ModerationAction]
+	notifications        *table[uuid.UUID, database.Notification]
+	auditEvents          *table[int64, database.AuditEvent]
+	// auditSeq is the audit_events id sequence. Like a Postgres sequence
+	// it isn't reset by a rollback, so it's shared with the clones.
+	auditSeq *int64
+	// auditHead is the audit_chain table's head_hash.
+	auditHead string
+	// auditPrune is the chirpy.audit_prune setting that lets audit events
+	// be deleted. Like SET LOCAL it ends with the transaction, so it isn't
+	// copied into clones.
+	auditPrune bool
+}
+
+func newState() *state {
+	return &state{
+		users:                newTable[uuid.UUID, database.User](),
+		chirps:               newTable[uuid.UUID, database.Chirp](),
+		refreshTokens:        newTable[string, database.RefreshToken](),
+		bookmarks:            newTable[pair, database.Bookmark](),
+		pinnedChirps:         newTable[pair, database.PinnedChirp](),
+		blocks:               newTable[pair, database.Block](),
+		mutes:                newTable[pair, database.Mute](),
+		follows:              newTable[pair, database.Follow](),
+		accountDeletions:     newTable[uuid.UUID, database.AccountDeletion](),
+		dataExports:          newTable[uuid.UUID, database.DataExport](),
+		webhookEvents:        newTable[uuid.UUID, database.WebhookEvent](),
+		subscriptions:        newTable[uuid.UUID, database.Subscription](),
+		entitlementOverrides: newTable[uuid.UUID, database.EntitlementOverride](),
+		webhookEndpoints:     newTable[uuid.UUID, database.WebhookEndpoint](),
+		outboxEvents:         newTable[uuid.UUID, database.OutboxEvent](),
+		webhookDeliveries:    newTable[uuid.UUID, database.WebhookDelivery](),
+		reports:              newTable[uuid.UUID, database.Report](),
+		moderationActions:    newTable[uuid.UUID, database.ModerationAction](),
+		notifications:        newTable[uuid.UUID, database.Notification](),
+		auditEvents:          newTable[int64, database.AuditEvent](),
+		auditSeq:             new(int64),
+	}
+}
+
+func (s *state) clone() *state {
+	return &state{
+		users:                s.users.clone(),
+		chirps:               s.chirps.clone(),
+		refreshTokens:        s.refreshTokens.clone(),
+		bookmarks:            s.bookmarks.clone(),
+		pinnedChirps:         s.pinnedChirps.clone(),
+		blocks:               s.blocks.clone(),
+		mutes:                s.mutes.clone(),
+		follows:              s.follows.clone(),
+		accountDeletions:     s.accountDeletions.clone(),
+		dataExports:          s.dataExports.clone(),
+		webhookEvents:        s.webhookEvents.clone(),
+		subscriptions:        s.subscriptions.clone(),
+		entitlementOverrides: s.entitlementOverrides.clone(),
+		webhookEndpoints:     s.webhookEndpoints.clone(),
+		outboxEvents:         s.outboxEvents.clone(),
+		webhookDeliveries:    s.webhookDeliveries.clone(),
+		reports:              s.reports.clone(),
+		moderationActions:    s.moderationActions.clone(),
+		notifications:        s.notifications.clone(),
+		auditEvents:          s.auditEvents.clone(),
+		auditSeq:             s.auditSeq,
+		auditHead:            s.auditHead,
+	}
+}
+
+// The delete functions below follow the schema's ON DELETE rules.
+
+func (s *state) deleteUsers(match func(database.User) bool) int64 {
+	users := s.users.deleteWhere(match)
+	for _, u := range users {
+		id := u.ID
+		nullID := uuid.NullUUID{UUID: id, Valid: true}
+
+		s.deleteChirps(func(c database.Chirp) bool { return c.UserID == id })
+		s.refreshTokens.deleteWhere(func(t database.RefreshToken) bool { return t.UserID == id })
+		s.bookmarks.deleteWhere(func(b database.Bookmark) bool { return b.UserID == id })
+		s.pinnedChirps.deleteWhere(func(p database.PinnedChirp) bool { return p.UserID == id })
+		s.blocks.deleteWhere(func(b database.Block) bool { return b.BlockerID == id || b.BlockedID == id })
+		s.mutes.deleteWhere(func(m database.Mute) bool { return m.MuterID == id || m.MutedID == id })
+		s.follows.deleteWhere(func(f database.Follow) bool { return f.FollowerID == id || f.FolloweeID == id })
+		s.dataExports.deleteWhere(func(e database.DataExport) bool { return e.UserID == id })
+		s.subscriptions.delete(id)
+		s.entitlementOverrides.delete(id)
+		s.deleteWebhookEndpoints(func(e database.WebhookEndpoint) bool { return e.UserID == nullID })
+		s.deleteOutboxEvents(func(e database.OutboxEvent) bool { return e.UserID == nullID })
+		s.reports.update(func(r database.Report) bool { return r.ReporterID == nullID }, func(r database.Report) database.Report {
+			r.ReporterID = uuid.NullUUID{}
+			return r
+		})
+		s.deleteReports(func(r database.Report) bool { return r.TargetUserID == id })
+		s.moderationActions.update(func(a database.ModerationAction) bool { return a.ModeratorID == nullID }, func(a database.ModerationAction) database.ModerationAction {
+			a.ModeratorID = uuid.NullUUID{}
+			return a
+		})
+		s.moderationActions.deleteWhere(func(a database.ModerationAction) bool { return a.TargetUserID == id })
+		s.notifications.deleteWhere(func(n database.Notification) bool { return n.UserID == id })
+	}
+	return int64(len(users))
+}
+
+func (s *state) deleteChirps(match func(database.Chirp) bool) {
+	for _, c := range s.chirps.deleteWhere(match) {
+		id := c.ID
+		s.bookmarks.deleteWhere(func(b database.Bookmark) bool { return b.ChirpID == id })
+		s.pinnedChirps.deleteWhere(func(p database.PinnedChirp) bool { return p.ChirpID == id })
+		s.reports.update(func(r database.Report) bool { return r.TargetChirpID == uuid.NullUUID{UUID: id, Valid: true} }, func(r database.Report) database.Report {
+			r.TargetChirpID = uuid.NullUUID{}
+			return r
+		})
+	}
+}
+
+func (s *state) deleteReports(match func(database.Report) bool) {
+	for _, r := range s.reports.deleteWhere(match) {
+		id := uuid.NullUUID{UUID: r.ID, Valid: true}
+		s.moderationActions.deleteWhere(func(a database.ModerationAction) bool { return a.ReportID == id })
+	}
+}
+
+func (s *state) deleteWebhookEndpoints(match func(database.WebhookEndpoint) bool) {
+	for _, e := range s.webhookEndpoints.deleteWhere(match) {
+		id := e.ID
+		s.webhookDeliveries.deleteWhere(func(d database.WebhookDelivery) bool { return d.EndpointID == id })
+	}
+}
+
+func (s *state) deleteOutboxEvents(match func(database.OutboxEvent) bool) {
+	for _, e := range s.outboxEvents.deleteWhere(match) {
+		id := e.ID
+		s.webhookDeliveries.deleteWhere(func(d database.WebhookDelivery) bool { return d.EventID == id })
+	}
+}
@@ -0,0 +1,129 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+// gracePeriodDays is the interval '7 days' past_due subscriptions get.
+const gracePeriodDays = 7
+
+func (q queries) ActivateSubscription(ctx context.Context, arg database.ActivateSubscriptionParams) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	periodEnd := now.AddDate(0, 1, 0)
+	if arg.CurrentPeriodEnd.Valid {
+		periodEnd = timestamp(arg.CurrentPeriodEnd.Time)
+	}
+
+	s, ok := st.subscriptions.get(arg.UserID)
+	if !ok {
+		if !st.users.has(arg.UserID) {
+			return foreignKeyViolation("subscriptions", "subscriptions_user_id_fkey")
+		}
+		s = database.Subscription{UserID: arg.UserID, CreatedAt: now}
+	}
+	s.UpdatedAt = now
+	s.Plan = arg.Plan
+	s.Status = "active"
+	s.CurrentPeriodEnd = periodEnd
+	s.GracePeriodEnd = sql.NullTime{}
+	st.subscriptions.put(s.UserID, s)
+	return nil
+}
+
+func (q queries) MarkSubscriptionPastDue(ctx context.Context, userID uuid.UUID) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	st.subscriptions.update(func(s database.Subscription) bool {
+		return s.UserID == userID && s.Status == "active"
+	}, func(s database.Subscription) database.Subscription {
+		s.UpdatedAt = now
+		s.Status = "past_due"
+		from := s.CurrentPeriodEnd
+		if now.After(from) {
+			from = now
+		}
+		s.GracePeriodEnd = sql.NullTime{Time: from.AddDate(0, 0, gracePeriodDays), Valid: true}
+		return s
+	})
+	return nil
+}
+
+func (q queries) CancelSubscription(ctx context.Context, userID uuid.UUID) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	st.subscriptions.update(func(s database.Subscription) bool {
+		return s.UserID == userID && oneOf(s.Status, "active", "past_due")
+	}, func(s database.Subscription) database.Subscription {
+		s.UpdatedAt = now
+		s.Status = "canceled"
+		return s
+	})
+	return nil
+}
+
+func (q queries) ExpireSubscription(ctx context.Context, userID uuid.UUID) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	st.subscriptions.update(func(s database.Subscription) bool {
+		return s.UserID == userID
+	}, func(s database.Subscription) database.Subscription {
+		s.UpdatedAt = now
+		s.Status = "expired"
+		s.GracePeriodEnd = sql.NullTime{}
+		return s
+	})
+	return nil
+}
+
+func (q queries) ExpireSubscriptions(ctx context.Context) (int64, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	updated := st.subscriptions.update(func(s database.Subscription) bool {
+		switch s.Status {
+		case "active", "canceled":
+			return !s.CurrentPeriodEnd.After(now)
+		case "past_due":
+			return s.GracePeriodEnd.Valid && !s.GracePeriodEnd.Time.After(now)
+		}
+		return false
+	}, func(s database.Subscription) database.Subscription {
+		s.UpdatedAt = now
+		if s.Status == "active" {
+			s.Status = "past_due"
+			s.GracePeriodEnd = sql.NullTime{Time: s.CurrentPeriodEnd.AddDate(0, 0, gracePeriodDays), Valid: true}
+		} else {
+			s.Status = "expired"
+		}
+		return s
+	})
+	return int64(len(updated)), nil
+}
+
+func (q queries) GetSubscription(ctx context.Context, userID uuid.UUID) (database.Subscription, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	s, ok := st.subscriptions.get(userID)
+	if !ok {
+		return database.Subscription{}, sql.ErrNoRows
+	}
+	return s, nil
+}
+
+func (q queries) IsChirpyRed(ctx context.Context, userID uuid.UUID) (bool, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	s, ok := st.subscriptions.get(userID)
+	return ok && s.Status != "expired", nil
+}
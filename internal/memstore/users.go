@@ -0,0 +1,252 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func (q queries) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.CreateUserRow, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	u := database.User{
+		ID:             uuid.New(),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		Email:          arg.Email,
+		HashedPassword: arg.HashedPassword,
+		Role:           "user",
+	}
+	st.users.put(u.ID, u)
+	return database.CreateUserRow{ID: u.ID, CreatedAt: u.CreatedAt, UpdatedAt: u.UpdatedAt, Email: u.Email}, nil
+}
+
+func (q queries) ResetUsers(ctx context.Context) error {
+	st, _, done := q.stmt()
+	defer done()
+
+	st.deleteUsers(all)
+	return nil
+}
+
+func (q queries) GetUser(ctx context.Context, id uuid.UUID) (database.User, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	u, ok := st.users.get(id)
+	if !ok {
+		return database.User{}, sql.ErrNoRows
+	}
+	return u, nil
+}
+
+// GetUserWithEmail returns the first user with the email. Emails aren't
+// unique in the schema, and Postgres doesn't promise which row it returns
+// either.
+func (q queries) GetUserWithEmail(ctx context.Context, email string) (database.User, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	users := st.users.where(func(u database.User) bool { return u.Email == email })
+	if len(users) == 0 {
+		return database.User{}, sql.ErrNoRows
+	}
+	return users[0], nil
+}
+
+func (q queries) UpdateUser(ctx context.Context, arg database.UpdateUserParams) (database.UpdateUserRow, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	u, ok := st.users.get(arg.ID)
+	if !ok {
+		return database.UpdateUserRow{}, sql.ErrNoRows
+	}
+	u.UpdatedAt = now
+	u.Email = arg.Email
+	u.HashedPassword = arg.HashedPassword
+	st.users.put(u.ID, u)
+	return database.UpdateUserRow{ID: u.ID, CreatedAt: u.CreatedAt, UpdatedAt: u.UpdatedAt, Email: u.Email}, nil
+}
+
+func (q queries) UpdateUserProtected(ctx context.Context, arg database.UpdateUserProtectedParams) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	st.users.update(byUserID(arg.ID), func(u database.User) database.User {
+		u.UpdatedAt = now
+		u.Protected = arg.Protected
+		return u
+	})
+	return nil
+}
+
+func (q queries) ScheduleUserDeletion(ctx context.Context, id uuid.UUID) (sql.NullTime, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	u, ok := st.users.get(id)
+	if !ok {
+		return sql.NullTime{}, sql.ErrNoRows
+	}
+	u.UpdatedAt = now
+	u.DeletionScheduledAt = sql.NullTime{Time: now.AddDate(0, 0, 30), Valid: true}
+	st.users.put(u.ID, u)
+	return u.DeletionScheduledAt, nil
+}
+
+func (q queries) CancelUserDeletion(ctx context.Context, id uuid.UUID) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	st.users.update(func(u database.User) bool {
+		return u.ID == id && u.DeletionScheduledAt.Valid
+	}, func(u database.User) database.User {
+		u.UpdatedAt = now
+		u.DeletionScheduledAt = sql.NullTime{}
+		return u
+	})
+	return nil
+}
+
+func (q queries) GetUsersDueForDeletion(ctx context.Context) ([]database.GetUsersDueForDeletionRow, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	var rows []database.GetUsersDueForDeletionRow
+	for _, u := range st.users.where(dueForDeletion(now)) {
+		rows = append(rows, database.GetUsersDueForDeletionRow{ID: u.ID, DeletionScheduledAt: u.DeletionScheduledAt})
+	}
+	return rows, nil
+}
+
+func (q queries) DeleteUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	due := dueForDeletion(now)
+	return st.deleteUsers(func(u database.User) bool {
+		return u.ID == id && due(u)
+	}), nil
+}
+
+func (q queries) CreateAccountDeletion(ctx context.Context, arg database.CreateAccountDeletionParams) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	if st.accountDeletions.has(arg.UserID) {
+		return uniqueViolation("account_deletions", "account_deletions_pkey")
+	}
+	st.accountDeletions.put(arg.UserID, database.AccountDeletion{
+		UserID:      arg.UserID,
+		ScheduledAt: timestamp(arg.ScheduledAt),
+		DeletedAt:   now,
+	})
+	return nil
+}
+
+func (q queries) GetUserRole(ctx context.Context, id uuid.UUID) (string, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	u, ok := st.users.get(id)
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	return u.Role, nil
+}
+
+func (q queries) UpdateUserRole(ctx context.Context, arg database.UpdateUserRoleParams) (int64, error) {
+	return q.updateRole(byUserID(arg.ID), arg.Role)
+}
+
+func (q queries) UpdateUserRoleByEmail(ctx context.Context, arg database.UpdateUserRoleByEmailParams) (int64, error) {
+	return q.updateRole(func(u database.User) bool { return u.Email == arg.Email }, arg.Role)
+}
+
+func (q queries) updateRole(match func(database.User) bool, role string) (int64, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	if !oneOf(role, "user", "moderator", "admin") && len(st.users.where(match)) > 0 {
+		return 0, checkViolation("users", "users_role_check")
+	}
+	updated := st.users.update(match, func(u database.User) database.User {
+		u.UpdatedAt = now
+		u.Role = role
+		return u
+	})
+	return int64(len(updated)), nil
+}
+
+func (q queries) SuspendUser(ctx context.Context, arg database.SuspendUserParams) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	if arg.Suspension.Valid && !oneOf(arg.Suspension.String, "suspended", "read_only", "shadow_limited") && st.users.has(arg.ID) {
+		return checkViolation("users", "users_suspension_check")
+	}
+	st.users.update(byUserID(arg.ID), func(u database.User) database.User {
+		u.UpdatedAt = now
+		u.Suspension = arg.Suspension
+		// NOW() plus a NULL interval is NULL, i.e. indefinite.
+		u.SuspendedUntil = sql.NullTime{}
+		if arg.DurationHours.Valid {
+			u.SuspendedUntil = sql.NullTime{Time: now.Add(time.Duration(arg.DurationHours.Int32) * time.Hour), Valid: true}
+		}
+		return u
+	})
+	return nil
+}
+
+func (q queries) GetActiveSuspension(ctx context.Context, id uuid.UUID) (sql.NullString, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	u, ok := st.users.get(id)
+	if !ok {
+		return sql.NullString{}, sql.ErrNoRows
+	}
+	if u.SuspendedUntil.Valid && !u.SuspendedUntil.Time.After(now) {
+		return sql.NullString{}, nil
+	}
+	return u.Suspension, nil
+}
+
+func (q queries) LiftSuspension(ctx context.Context, id uuid.UUID) (int64, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	updated := st.users.update(func(u database.User) bool {
+		return u.ID == id && u.Suspension.Valid
+	}, func(u database.User) database.User {
+		u.UpdatedAt = now
+		u.Suspension = sql.NullString{}
+		u.SuspendedUntil = sql.NullTime{}
+		return u
+	})
+	return int64(len(updated)), nil
+}
+
+func byUserID(id uuid.UUID) func(database.User) bool {
+	return func(u database.User) bool { return u.ID == id }
+}
+
+func dueForDeletion(now time.Time) func(database.User) bool {
+	return func(u database.User) bool {
+		return u.DeletionScheduledAt.Valid && !u.DeletionScheduledAt.Time.After(now)
+	}
+}
+
+// hiddenBySuspension is whether a user's suspension hides their chirps
+// and profile from everyone else.
+func hiddenBySuspension(u database.User, now time.Time) bool {
+	if !u.Suspension.Valid || !oneOf(u.Suspension.String, "suspended", "shadow_limited") {
+		return false
+	}
+	return !u.SuspendedUntil.Valid || u.SuspendedUntil.Time.After(now)
+}
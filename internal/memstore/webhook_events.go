@@ -0,0 +1,116 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+// CreateWebhookEvent returns sql.ErrNoRows for an event that has already
+// been received, as ON CONFLICT DO NOTHING returns no row.
+func (q queries) CreateWebhookEvent(ctx context.Context, arg database.CreateWebhookEventParams) (database.WebhookEvent, error) {
+	st, now, done := q.stmt()
+	defer done()
+
+	dup := st.webhookEvents.where(func(e database.WebhookEvent) bool {
+		return e.Provider == arg.Provider && e.EventID == arg.EventID
+	})
+	if len(dup) > 0 {
+		return database.WebhookEvent{}, sql.ErrNoRows
+	}
+	e := database.WebhookEvent{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Provider:  arg.Provider,
+		EventID:   arg.EventID,
+		EventType: arg.EventType,
+		Status:    "received",
+		Payload:   slices.Clone(arg.Payload),
+	}
+	st.webhookEvents.put(e.ID, e)
+	return e, nil
+}
+
+func (q queries) GetWebhookEvent(ctx context.Context, id uuid.UUID) (database.WebhookEvent, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	e, ok := st.webhookEvents.get(id)
+	if !ok {
+		return database.WebhookEvent{}, sql.ErrNoRows
+	}
+	return e, nil
+}
+
+func (q queries) GetWebhookEventByEventID(ctx context.Context, arg database.GetWebhookEventByEventIDParams) (database.WebhookEvent, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	events := st.webhookEvents.where(func(e database.WebhookEvent) bool {
+		return e.Provider == arg.Provider && e.EventID == arg.EventID
+	})
+	if len(events) == 0 {
+		return database.WebhookEvent{}, sql.ErrNoRows
+	}
+	return events[0], nil
+}
+
+func (q queries) GetWebhookEvents(ctx context.Context, arg database.GetWebhookEventsParams) ([]database.WebhookEvent, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	events := st.webhookEvents.where(all)
+	sortWebhookEvents(events)
+	return page(events, arg.Limit, arg.Offset), nil
+}
+
+func (q queries) GetWebhookEventsByStatus(ctx context.Context, arg database.GetWebhookEventsByStatusParams) ([]database.WebhookEvent, error) {
+	st, _, done := q.stmt()
+	defer done()
+
+	events := st.webhookEvents.where(func(e database.WebhookEvent) bool { return e.Status == arg.Status })
+	sortWebhookEvents(events)
+	return page(events, arg.Limit, arg.Offset), nil
+}
+
+func (q queries) MarkWebhookEventProcessed(ctx context.Context, arg database.MarkWebhookEventProcessedParams) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	if !oneOf(arg.Status, "received", "processed", "ignored", "failed") && st.webhookEvents.has(arg.ID) {
+		return checkViolation("webhook_events", "webhook_events_status_check")
+	}
+	st.webhookEvents.update(func(e database.WebhookEvent) bool { return e.ID == arg.ID }, func(e database.WebhookEvent) database.WebhookEvent {
+		e.UpdatedAt = now
+		e.Status = arg.Status
+		e.Attempts++
+		e.LastError = sql.NullString{}
+		e.ProcessedAt = sql.NullTime{Time: now, Valid: true}
+		return e
+	})
+	return nil
+}
+
+func (q queries) MarkWebhookEventFailed(ctx context.Context, arg database.MarkWebhookEventFailedParams) error {
+	st, now, done := q.stmt()
+	defer done()
+
+	st.webhookEvents.update(func(e database.WebhookEvent) bool { return e.ID == arg.ID }, func(e database.WebhookEvent) database.WebhookEvent {
+		e.UpdatedAt = now
+		e.Status = "failed"
+		e.Attempts++
+		e.LastError = arg.LastError
+		return e
+	})
+	return nil
+}
+
+func sortWebhookEvents(events []database.WebhookEvent) {
+	slices.SortStableFunc(events, func(a, b database.WebhookEvent) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+}
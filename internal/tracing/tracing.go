@@ -0,0 +1,39 @@
+// Package tracing sets up OpenTelemetry tracing: spans are propagated with
+// W3C trace context headers and exported over OTLP, so they join traces
+// started by other services.
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// Propagator reads and writes the W3C traceparent header.
+var Propagator = propagation.TraceContext{}
+
+// NewProvider returns a tracer provider that batches spans to the collector
+// at endpoint, such as http://localhost:4318, over OTLP/HTTP. With no
+// endpoint spans are still started, so trace context is propagated and
+// logged, but nothing is exported.
+func NewProvider(endpoint, serviceName string) (*sdktrace.TracerProvider, error) {
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	}
+
+	if endpoint != "" {
+		exporter, err := otlptracehttp.New(context.Background(),
+			otlptracehttp.WithEndpointURL(strings.TrimSuffix(endpoint, "/")+"/v1/traces"),
+		)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+	return sdktrace.NewTracerProvider(opts...), nil
+}
@@ -0,0 +1,115 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+type fakeDB struct {
+	DBTX
+	queries []string
+	err     error
+}
+
+func (f *fakeDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	f.queries = append(f.queries, query)
+	return nil, f.err
+}
+
+func TestDB(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracer := provider.Tracer("test")
+	fake := &fakeDB{err: errors.New("connection refused")}
+	db := WrapDB(fake, tracer, "postgresql")
+
+	const query = "-- name: DeleteChirp :exec\nDELETE FROM chirps WHERE id = $1"
+	db.ExecContext(context.Background(), query, 1)
+
+	ctx, span := tracer.Start(context.Background(), "DELETE /api/chirps/{chirpID}", trace.WithSpanKind(trace.SpanKindServer))
+	db.ExecContext(ctx, query, 1)
+	span.End()
+
+	if len(fake.queries) != 2 {
+		t.Errorf("Want both queries to run, got %d", len(fake.queries))
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Want only the query inside a trace to be traced, got %d spans", len(spans))
+	}
+	dbSpan, serverSpan := spans[0], spans[1]
+	if dbSpan.Name != "DeleteChirp" || dbSpan.SpanKind != trace.SpanKindClient {
+		t.Errorf("Want a client span named after the query, got %q, %v", dbSpan.Name, dbSpan.SpanKind)
+	}
+	if dbSpan.Parent.SpanID() != serverSpan.SpanContext.SpanID() {
+		t.Errorf("Want the query span to be a child of the server span")
+	}
+	if dbSpan.Status.Code != codes.Error || dbSpan.Status.Description != "connection refused" {
+		t.Errorf("Want the query span to record its error, got %+v", dbSpan.Status)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	var got collectortrace.ExportTraceServiceRequest
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		err := proto.Unmarshal(body, &got)
+		if err != nil {
+			t.Errorf("Error decoding export: %v", err)
+		}
+	}))
+	defer collector.Close()
+
+	provider, err := NewProvider(collector.URL+"/", "chirpy")
+	if err != nil {
+		t.Fatalf("NewProvider returned error: %v", err)
+	}
+	_, span := provider.Tracer("test").Start(context.Background(), "POST /api/chirps")
+	span.End()
+
+	err = provider.Shutdown(context.Background())
+	if err != nil {
+		t.Fatalf("Shutdown returned error: %v", err)
+	}
+
+	if len(got.ResourceSpans) != 1 {
+		t.Fatalf("Want one resource, got %d", len(got.ResourceSpans))
+	}
+	rs := got.ResourceSpans[0]
+	attrs := rs.Resource.GetAttributes()
+	if len(attrs) != 1 || attrs[0].Key != "service.name" || attrs[0].Value.GetStringValue() != "chirpy" {
+		t.Errorf("Unexpected resource: %v", rs.Resource)
+	}
+	if len(rs.ScopeSpans) != 1 || len(rs.ScopeSpans[0].Spans) != 1 || rs.ScopeSpans[0].Spans[0].Name != "POST /api/chirps" {
+		t.Errorf("Want the span to be exported, got %v", rs.ScopeSpans)
+	}
+}
+
+func TestNewProviderWithoutEndpoint(t *testing.T) {
+	provider, err := NewProvider("", "chirpy")
+	if err != nil {
+		t.Fatalf("NewProvider returned error: %v", err)
+	}
+	ctx, span := provider.Tracer("test").Start(context.Background(), "GET /")
+	defer span.End()
+
+	// Trace context is still created, so it's logged and propagated.
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		t.Errorf("Want a span context without an exporter")
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// validRequestID limits the request IDs taken from clients and proxies, as
+// they end up in logs and the audit trail.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// requestRecord is what the request log, metrics and traces report about
+// a request. middlewareLogging creates it once, wraps the response writer
+// with it and stores it in the request's context, where the rest of the
+// stack fills it in: the route when the mux matches one, and the user ID
+// when the caller's token is validated.
+type requestRecord struct {
+	http.ResponseWriter
+	id     string
+	status int
+	bytes  int
+	// route is the matched mux pattern without its method, or empty if
+	// no route matched.
+	route  string
+	userID uuid.UUID
+	// errMsg is the message of a 5xx error response, which is logged with
+	// the request so the cause of the error can be found.
+	errMsg string
+}
+
+const requestRecordKey contextKey = "requestRecord"
+
+// recordFrom returns the request's record, or nil outside the middleware.
+func recordFrom(ctx context.Context) *requestRecord {
+	rec, _ := ctx.Value(requestRecordKey).(*requestRecord)
+	return rec
+}
+
+func (r *requestRecord) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *requestRecord) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = 200
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+func (r *requestRecord) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// statusCode returns the response status, which is 200 if the handler
+// wrote nothing.
+func (r *requestRecord) statusCode() int {
+	if r.status == 0 {
+		return 200
+	}
+	return r.status
+}
+
+// middlewareRoute records the route the mux matched. It wraps the mux
+// itself, as the mux only sets the pattern on the request it's given.
+func middlewareRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		if r.Pattern == "" {
+			return
+		}
+		route := r.Pattern
+		if _, path, ok := strings.Cut(r.Pattern, " "); ok {
+			route = path
+		}
+		if rec := recordFrom(r.Context()); rec != nil {
+			rec.route = route
+		}
+		nameSpan(r, route)
+	})
+}
+
+// setUserID records the authenticated caller for the request log.
+func setUserID(r *http.Request, id uuid.UUID) {
+	if rec := recordFrom(r.Context()); rec != nil {
+		rec.userID = id
+	}
+}
+
+// middlewareLogging gives each request an ID, taken from the X-Request-ID
+// header when the caller sent a usable one, and logs the request once it's
+// been handled.
+func (cfg *apiConfig) middlewareLogging(next http.Handler) http.Handler {
+	logger := cfg.logs.Logger("http")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get("X-Request-ID")
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+		}
+		w.Header().Set("X-Request-ID", id)
+
+		rec := &requestRecord{ResponseWriter: w, id: id}
+		r = r.WithContext(context.WithValue(r.Context(), requestRecordKey, rec))
+		next.ServeHTTP(rec, r)
+
+		status := rec.statusCode()
+		route := rec.route
+		if route == "" {
+			route = "unmatched"
+		}
+
+		attrs := []slog.Attr{
+			slog.String("request_id", id),
+			slog.String("method", r.Method),
+			slog.String("route", route),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", rec.bytes),
+			slog.String("ip", clientIP(r)),
+		}
+		if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+			attrs = append(attrs, slog.String("trace_id", sc.TraceID().String()))
+		}
+		if rec.userID != uuid.Nil {
+			attrs = append(attrs, slog.String("user_id", rec.userID.String()))
+		}
+
+		level := slog.LevelInfo
+		if status >= 500 {
+			level = slog.LevelError
+			attrs = append(attrs, slog.String("error", rec.errMsg))
+		}
+		logger.LogAttrs(r.Context(), level, "request", attrs...)
+	})
+}
+
+// recordError keeps the message of a server error for the request log.
+// Middleware between here and the log may have wrapped the writer, so the
+// wrappers are unwrapped until the record is found.
+func recordError(w http.ResponseWriter, msg string) {
+	for {
+		if rec, ok := w.(*requestRecord); ok {
+			rec.errMsg = msg
+			return
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return
+		}
+		w = u.Unwrap()
+	}
+}
+
+// requestID returns the ID middlewareLogging gave the request ctx belongs
+// to, or "" for work that didn't start with a request.
+func requestID(ctx context.Context) string {
+	if rec := recordFrom(ctx); rec != nil {
+		return rec.id
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joshckidd/chirpy/internal/logging"
+)
+
+func TestRequestLog(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	chirp := a.chirp(alice, "hello")
+
+	var logs bytes.Buffer
+	a.cfg.logs = logging.New(&logs, logging.Levels{Default: slog.LevelInfo})
+	a.handler = a.cfg.routes(t.TempDir())
+
+	req := httptest.NewRequest("GET", "/api/chirps/"+chirp.ID.String(), nil)
+	req.Header.Set("Authorization", "Bearer "+alice.Token)
+	req.Header.Set("X-Request-ID", "req-123")
+	rec := a.serve(req)
+	if rec.Code != 200 || rec.Header().Get("X-Request-ID") != "req-123" {
+		t.Fatalf("Want 200 echoing the request ID, got %d and %q", rec.Code, rec.Header().Get("X-Request-ID"))
+	}
+	if req.Pattern != "" {
+		t.Errorf("Want the caller's request left alone, got pattern %q", req.Pattern)
+	}
+
+	var line struct {
+		RequestID string `json:"request_id"`
+		Route     string `json:"route"`
+		Status    int    `json:"status"`
+		UserID    string `json:"user_id"`
+		TraceID   string `json:"trace_id"`
+	}
+	err := json.Unmarshal(logs.Bytes(), &line)
+	if err != nil {
+		t.Fatalf("Want one JSON log line, got %q: %v", logs.String(), err)
+	}
+	if line.RequestID != "req-123" || line.Route != "/api/chirps/{chirpID}" || line.Status != 200 {
+		t.Errorf("Unexpected request log: %+v", line)
+	}
+	if line.UserID != alice.ID.String() {
+		t.Errorf("Want the caller's user ID %s, got %q", alice.ID, line.UserID)
+	}
+	if line.TraceID == "" {
+		t.Errorf("Want the trace ID logged")
+	}
+}
+
+func TestRequestLogUnmatched(t *testing.T) {
+	a := newTestAPI(t)
+
+	var logs bytes.Buffer
+	a.cfg.logs = logging.New(&logs, logging.Levels{Default: slog.LevelInfo})
+	a.handler = a.cfg.routes(t.TempDir())
+	a.expect(404, "GET", "/api/nothing-here", "bad-token", nil)
+
+	var line map[string]any
+	err := json.Unmarshal(logs.Bytes(), &line)
+	if err != nil {
+		t.Fatalf("Want one JSON log line, got %q: %v", logs.String(), err)
+	}
+	if line["route"] != "unmatched" {
+		t.Errorf("Want an unmatched route, got %v", line["route"])
+	}
+	if _, ok := line["user_id"]; ok {
+		t.Errorf("Want no user ID without a valid token, got %v", line["user_id"])
+	}
+}
@@ -1,60 +1,220 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"crypto/tls"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
 	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/joshckidd/chirpy/internal/auth"
+	"github.com/joshckidd/chirpy/internal/certreload"
+	"github.com/joshckidd/chirpy/internal/config"
 	"github.com/joshckidd/chirpy/internal/database"
+	"github.com/joshckidd/chirpy/internal/entitlements"
+	"github.com/joshckidd/chirpy/internal/logging"
+	"github.com/joshckidd/chirpy/internal/tracing"
 	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/pressly/goose/v3"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracerName is the instrumentation scope of the spans chirpy starts.
+const tracerName = "github.com/joshckidd/chirpy"
+
 type apiConfig struct {
 	fileserverHits atomic.Int32
-	db             *database.Queries
+	db             database.Store
 	environment    string
 	tokenSecret    string
-	polkaKey       string
+	// exportSecret signs data export download links.
+	exportSecret   string
+	polkaSecrets   []string
+	exportDir      string
+	plans          entitlements.Plans
+	metrics        *appMetrics
+	logs           *logging.Loggers
+	tracerProvider *sdktrace.TracerProvider
+	tracer         trace.Tracer
+	migrator       *goose.Provider
+	auditRetention time.Duration
+	auditQueue     auditQueue
+	// background tracks workers and other goroutines that shutdown waits
+	// for.
+	background   sync.WaitGroup
+	workersMu    sync.Mutex
+	workers      map[string]*workerHealth
+	shuttingDown atomic.Bool
 }
 
 func main() {
 	godotenv.Load()
-	dbURL := os.Getenv("DB_URL")
-	db, err := sql.Open("postgres", dbURL)
+	conf, args, err := config.Load(os.Args[1:], os.Getenv)
 	if err != nil {
-		fmt.Println("Database error.")
+		fmt.Println("Config error:", err)
 		os.Exit(1)
 	}
 
-	dbQueries := database.New(db)
+	db, dialect, err := database.Open(conf.DBURL)
+	if err != nil {
+		fmt.Println("Database error:", err)
+		os.Exit(1)
+	}
 
-	serveMux := http.NewServeMux()
-	serveMux.HandleFunc("GET /api/healthz", readinessEndpoint)
-	server := http.Server{
-		Handler: serveMux,
-		Addr:    ":8080",
+	if len(args) > 0 {
+		err = runCommand(context.Background(), db, dialect, args)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
 	}
 
 	var apiCfg apiConfig
-	apiCfg.db = dbQueries
-	apiCfg.environment = os.Getenv("PLATFORM")
-	apiCfg.tokenSecret = os.Getenv("SECRET")
-	apiCfg.polkaKey = os.Getenv("POLKA_KEY")
-	serveMux.Handle("/app/", apiCfg.middlewareMetricsInc(http.StripPrefix("/app/", http.FileServer(http.Dir("/home/josh/Documents/repos/github.com/joshckidd/chirpy")))))
-	serveMux.HandleFunc("GET /admin/metrics", apiCfg.returnMetrics)
-	serveMux.HandleFunc("POST /admin/reset", apiCfg.resetMetrics)
-	serveMux.HandleFunc("POST /api/users", apiCfg.postUser)
-	serveMux.HandleFunc("POST /api/chirps", apiCfg.postChirp)
-	serveMux.HandleFunc("GET /api/chirps", apiCfg.getChirps)
-	serveMux.HandleFunc("GET /api/chirps/{chirpID}", apiCfg.getChirp)
-	serveMux.HandleFunc("POST /api/login", apiCfg.userLogin)
-	serveMux.HandleFunc("POST /api/refresh", apiCfg.refreshJWT)
-	serveMux.HandleFunc("POST /api/revoke", apiCfg.revokeToken)
-	serveMux.HandleFunc("PUT /api/users", apiCfg.putUser)
-	serveMux.HandleFunc("DELETE /api/chirps/{chirpID}", apiCfg.deleteChirp)
-	serveMux.HandleFunc("POST /api/polka/webhooks", apiCfg.userRed)
-	server.ListenAndServe()
+	apiCfg.logs = logging.New(os.Stderr, conf.LogLevels)
+	logger := apiCfg.logs.Logger("server")
+	slog.SetDefault(logger)
+
+	var dump strings.Builder
+	conf.Dump(&dump)
+	logger.Info("effective config", "config", dump.String())
+
+	apiCfg.migrator, err = newMigrator(db, dialect)
+	if err != nil {
+		fmt.Println("Migration error:", err)
+		os.Exit(1)
+	}
+	err = checkSchema(context.Background(), apiCfg.migrator, conf.AutoMigrate, logger)
+	if err != nil {
+		fmt.Println("Schema error:", err)
+		os.Exit(1)
+	}
+
+	apiCfg.tracerProvider, err = tracing.NewProvider(conf.OTLPEndpoint, conf.ServiceName)
+	if err != nil {
+		fmt.Println("Tracing error:", err)
+		os.Exit(1)
+	}
+	apiCfg.tracer = apiCfg.tracerProvider.Tracer(tracerName)
+	dbSystem := "postgresql"
+	if dialect == database.SQLite {
+		dbSystem = "sqlite"
+	}
+	apiCfg.db = database.NewStore(db, dialect, func(d database.DBTX) database.DBTX {
+		return tracing.WrapDB(d, apiCfg.tracer, dbSystem)
+	})
+	apiCfg.metrics = newAppMetrics(&apiCfg, db)
+
+	server := http.Server{
+		Handler:           apiCfg.routes(conf.FileServerRoot),
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+		Addr:              conf.Addr,
+		ReadTimeout:       conf.ReadTimeout,
+		ReadHeaderTimeout: conf.ReadHeaderTimeout,
+		WriteTimeout:      conf.WriteTimeout,
+		IdleTimeout:       conf.IdleTimeout,
+		MaxHeaderBytes:    conf.MaxHeaderBytes,
+	}
+
+	apiCfg.environment = conf.Platform
+	apiCfg.tokenSecret = conf.Secret
+	apiCfg.exportSecret = conf.ExportURLSecret
+	if apiCfg.exportSecret == "" {
+		// Only allowed in dev. Download links then stop working on restart.
+		apiCfg.exportSecret, err = auth.MakeRefreshToken()
+		if err != nil {
+			fmt.Println("Export URL secret error:", err)
+			os.Exit(1)
+		}
+	}
+	apiCfg.polkaSecrets = conf.PolkaWebhookSecrets
+	apiCfg.exportDir = conf.ExportDir
+	apiCfg.auditRetention = conf.AuditRetention
+	apiCfg.plans = entitlements.DefaultPlans()
+	if conf.EntitlementsFile != "" {
+		apiCfg.plans, err = entitlements.LoadPlans(conf.EntitlementsFile)
+		if err != nil {
+			fmt.Println("Entitlements error:", err)
+			os.Exit(1)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Workers that make requests, or build exports, get as long as their
+	// timeouts allow before they count as stuck.
+	apiCfg.startWorker(ctx, "account deletion", time.Hour, time.Hour, apiCfg.purgeDeletedUsers)
+	apiCfg.startWorker(ctx, "data export cleanup", time.Hour, time.Hour, apiCfg.purgeExpiredExports)
+	apiCfg.startWorker(ctx, "data export resume", time.Minute, exportResumeBatchSize*exportTimeout, apiCfg.resumeDataExports)
+	apiCfg.startWorker(ctx, "subscription expiry", time.Hour, time.Hour, apiCfg.expireSubscriptions)
+	apiCfg.startWorker(ctx, "webhook dispatch", 5*time.Second, time.Minute, apiCfg.dispatchOutbox)
+	apiCfg.startWorker(ctx, "webhook delivery", 5*time.Second, deliveryBatchSize*deliveryTimeout, apiCfg.deliverWebhooks)
+	apiCfg.startWorker(ctx, "audit retention", time.Hour, time.Hour, apiCfg.pruneAuditEvents)
+	apiCfg.startWorker(ctx, "audit writer", auditFlushInterval, time.Minute, apiCfg.flushAuditQueue)
+
+	serveErr := make(chan error, 2)
+	if conf.TLSCertFile != "" {
+		certs, err := certreload.New(conf.TLSCertFile, conf.TLSKeyFile)
+		if err != nil {
+			fmt.Println("TLS error:", err)
+			os.Exit(1)
+		}
+		server.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		}
+		apiCfg.startWorker(ctx, "tls reload", time.Minute, time.Minute, func(context.Context) error {
+			reloaded, err := certs.Reload()
+			if reloaded {
+				logger.Info("reloaded TLS certificate", "file", conf.TLSCertFile)
+			}
+			return err
+		})
+		go func() { serveErr <- server.ListenAndServeTLS("", "") }()
+	} else {
+		go func() { serveErr <- server.ListenAndServe() }()
+	}
+	logger.Info("listening", "addr", conf.Addr, "tls", conf.TLSCertFile != "")
+
+	// Metrics get their own listener rather than a route on the public
+	// one, so they're only reachable where METRICS_ADDR is.
+	servers := []*http.Server{&server}
+	if conf.MetricsAddr != "" {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("GET /metrics", apiCfg.metrics.handler())
+		metricsServer := &http.Server{
+			Handler:           metricsMux,
+			ErrorLog:          server.ErrorLog,
+			Addr:              conf.MetricsAddr,
+			ReadHeaderTimeout: conf.ReadHeaderTimeout,
+		}
+		servers = append(servers, metricsServer)
+		go func() { serveErr <- metricsServer.ListenAndServe() }()
+		logger.Info("serving metrics", "addr", conf.MetricsAddr)
+	}
+
+	exitCode := 0
+	select {
+	case err = <-serveErr:
+		logger.Error("server failed", "error", err)
+		exitCode = 1
+		stop()
+	case <-ctx.Done():
+		logger.Info("shutting down")
+	}
+
+	exitCode = max(exitCode, apiCfg.shutdown(conf.ShutdownDelay, conf.ShutdownTimeout, servers...))
+	db.Close()
+	os.Exit(exitCode)
 }
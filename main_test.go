@@ -0,0 +1,413 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+	"github.com/joshckidd/chirpy/internal/entitlements"
+	"github.com/joshckidd/chirpy/internal/logging"
+	"github.com/joshckidd/chirpy/internal/memstore"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const testTokenSecret = "test-token-secret"
+
+const testExportURLSecret = "test-export-url-secret"
+
+const testPolkaSecret = "test-polka-secret"
+
+// testedRoutes collects the route of every request the tests make, named
+// the way middlewareTracing names server spans.
+var testedRoutes = struct {
+	sync.Mutex
+	seen map[string]bool
+}{seen: map[string]bool{}}
+
+// recordRoutes adds the server spans exported so far to testedRoutes.
+func recordRoutes(exporter *tracetest.InMemoryExporter) {
+	testedRoutes.Lock()
+	defer testedRoutes.Unlock()
+	for _, s := range exporter.GetSpans() {
+		if s.SpanKind == trace.SpanKindServer {
+			testedRoutes.seen[s.Name] = true
+		}
+	}
+}
+
+// testBackends are the stores the tests run against, one after the other.
+// The Postgres run needs a server named by TEST_DB_URL, and is skipped
+// without one.
+var testBackends = []string{"memory", "sqlite", "postgres"}
+
+// testBackend is the store newTestAPI uses in the current run.
+var testBackend string
+
+// TestMain runs the tests once for each store, then fails the run if a
+// route registered in routes.go wasn't hit by any test. The check is
+// skipped when only some tests were selected.
+func TestMain(m *testing.M) {
+	flag.Parse()
+	code := 0
+	for _, backend := range testBackends {
+		testBackend = backend
+		if backend == "postgres" && os.Getenv("TEST_DB_URL") == "" {
+			if testing.Verbose() {
+				fmt.Println("--- skipping the postgres store: TEST_DB_URL is not set")
+			}
+			continue
+		}
+		if testing.Verbose() {
+			fmt.Printf("--- running with the %s store\n", backend)
+		}
+		if c := m.Run(); c != 0 {
+			fmt.Printf("FAIL with the %s store\n", backend)
+			code = c
+		}
+	}
+	if code != 0 || flag.Lookup("test.run").Value.String() != "" {
+		os.Exit(code)
+	}
+
+	patterns, err := registeredRoutes("routes.go")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	for _, p := range patterns {
+		if !strings.Contains(p, " ") {
+			p = "GET " + p
+		}
+		if !testedRoutes.seen[p] {
+			fmt.Printf("route %q has no test\n", p)
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+// registeredRoutes returns the patterns passed to Handle and HandleFunc in
+// the given file.
+func registeredRoutes(filename string) ([]string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), filename, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	var patterns []string
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || (sel.Sel.Name != "Handle" && sel.Sel.Name != "HandleFunc") {
+			return true
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		p, err := strconv.Unquote(lit.Value)
+		if err == nil {
+			patterns = append(patterns, p)
+		}
+		return true
+	})
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("no routes found in %s", filename)
+	}
+	return patterns, nil
+}
+
+// testStore is a store whose clock tests can move.
+type testStore interface {
+	database.Store
+	SetClock(now func() time.Time)
+}
+
+// testAPI is the API wired to an in-memory store, a SQLite file or a
+// Postgres schema, so every route can be driven through httptest.
+type testAPI struct {
+	t       *testing.T
+	cfg     *apiConfig
+	store   testStore
+	handler http.Handler
+	// spans holds the spans of finished requests, and of any work they
+	// traced.
+	spans *tracetest.InMemoryExporter
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+
+	store := newTestStore(t)
+	spans := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans))
+	cfg := &apiConfig{
+		db:             store,
+		environment:    "dev",
+		tokenSecret:    testTokenSecret,
+		exportSecret:   testExportURLSecret,
+		polkaSecrets:   []string{testPolkaSecret},
+		exportDir:      t.TempDir(),
+		plans:          entitlements.DefaultPlans(),
+		logs:           logging.New(io.Discard, logging.Levels{}),
+		tracerProvider: provider,
+		tracer:         provider.Tracer(tracerName),
+		workers:        map[string]*workerHealth{},
+	}
+	cfg.metrics = newAppMetrics(cfg, nil)
+
+	root := t.TempDir()
+	err := os.WriteFile(root+"/index.html", []byte("Welcome to Chirpy"), 0o644)
+	if err != nil {
+		t.Fatalf("Error writing index.html: %v", err)
+	}
+
+	return &testAPI{t: t, cfg: cfg, store: store, handler: cfg.routes(root), spans: spans}
+}
+
+// newTestStore returns an empty store of the kind testBackend names.
+func newTestStore(t *testing.T) testStore {
+	t.Helper()
+
+	switch testBackend {
+	case "sqlite":
+	case "postgres":
+		return newPostgresTestStore(t)
+	default:
+		return memstore.New()
+	}
+
+	db, dialect, err := database.Open("sqlite:" + t.TempDir() + "/chirpy.db")
+	if err != nil {
+		t.Fatalf("Error opening SQLite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	p, err := newMigrator(db, dialect)
+	if err != nil {
+		t.Fatalf("Error loading migrations: %v", err)
+	}
+	_, err = p.Up(context.Background())
+	if err != nil {
+		t.Fatalf("Error migrating SQLite: %v", err)
+	}
+	return database.NewSQLiteStore(db, nil)
+}
+
+// postgresTestStore is a Postgres store in a schema of its own. Postgres
+// reads the time from its own clock, so tests that move the clock are
+// skipped against it.
+type postgresTestStore struct {
+	*database.PostgresStore
+	t *testing.T
+}
+
+func (s postgresTestStore) SetClock(func() time.Time) {
+	s.t.Skip("the Postgres store uses the database's clock")
+}
+
+// newPostgresTestStore migrates a new schema on the TEST_DB_URL server,
+// which is dropped when the test ends, so tests can't see each other's
+// rows.
+func newPostgresTestStore(t *testing.T) testStore {
+	t.Helper()
+
+	dbURL := os.Getenv("TEST_DB_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DB_URL is not set")
+	}
+
+	suffix := make([]byte, 8)
+	rand.Read(suffix)
+	schema := "test_" + hex.EncodeToString(suffix)
+
+	admin, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		t.Fatalf("Error connecting to Postgres: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() })
+	_, err = admin.Exec("CREATE SCHEMA " + schema)
+	if err != nil {
+		t.Fatalf("Error creating schema: %v", err)
+	}
+	t.Cleanup(func() {
+		_, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if err != nil {
+			t.Errorf("Error dropping schema %s: %v", schema, err)
+		}
+	})
+
+	u, err := url.Parse(dbURL)
+	if err != nil {
+		t.Fatalf("Error parsing TEST_DB_URL: %v", err)
+	}
+	q := u.Query()
+	q.Set("search_path", schema)
+	u.RawQuery = q.Encode()
+
+	db, dialect, err := database.Open(u.String())
+	if err != nil {
+		t.Fatalf("Error connecting to Postgres: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	p, err := newMigrator(db, dialect)
+	if err != nil {
+		t.Fatalf("Error loading migrations: %v", err)
+	}
+	_, err = p.Up(context.Background())
+	if err != nil {
+		t.Fatalf("Error migrating Postgres: %v", err)
+	}
+	return postgresTestStore{PostgresStore: database.NewPostgresStore(db, nil), t: t}
+}
+
+// do sends a request with body encoded as JSON, unless it is nil or
+// already a []byte, and authenticated with token if it isn't empty.
+func (a *testAPI) do(method, path, token string, body any) *httptest.ResponseRecorder {
+	a.t.Helper()
+
+	var r io.Reader
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		r = bytes.NewReader(b)
+	default:
+		dat, err := json.Marshal(b)
+		if err != nil {
+			a.t.Fatalf("Error encoding request body: %v", err)
+		}
+		r = bytes.NewReader(dat)
+	}
+
+	req := httptest.NewRequest(method, path, r)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return a.serve(req)
+}
+
+// serve sends req through the whole middleware stack.
+func (a *testAPI) serve(req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	a.handler.ServeHTTP(rec, req)
+	recordRoutes(a.spans)
+	return rec
+}
+
+// expect sends a request like do and fails the test unless it gets code.
+func (a *testAPI) expect(code int, method, path, token string, body any) *httptest.ResponseRecorder {
+	a.t.Helper()
+
+	rec := a.do(method, path, token, body)
+	if rec.Code != code {
+		a.t.Fatalf("%s %s: want %d, got %d: %s", method, path, code, rec.Code, rec.Body.String())
+	}
+	return rec
+}
+
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+
+	var v T
+	err := json.Unmarshal(rec.Body.Bytes(), &v)
+	if err != nil {
+		t.Fatalf("Error decoding %q: %v", rec.Body.String(), err)
+	}
+	return v
+}
+
+type testUser struct {
+	ID           uuid.UUID
+	Email        string
+	Token        string
+	RefreshToken string
+}
+
+// signup creates a user through the API and logs them in.
+func (a *testAPI) signup(name string) testUser {
+	a.t.Helper()
+
+	email := name + "@example.com"
+	a.expect(201, "POST", "/api/users", "", map[string]string{"email": email, "password": "hunter2"})
+	rec := a.expect(200, "POST", "/api/login", "", map[string]string{"email": email, "password": "hunter2"})
+	res := decode[returnUserRow](a.t, rec)
+	return testUser{ID: res.ID, Email: email, Token: res.Token, RefreshToken: res.RefreshToken}
+}
+
+// setRole changes a user's role directly, as the first admin has to be
+// made outside the API.
+func (a *testAPI) setRole(u testUser, role string) {
+	a.t.Helper()
+
+	_, err := a.store.UpdateUserRole(context.Background(), database.UpdateUserRoleParams{Role: role, ID: u.ID})
+	if err != nil {
+		a.t.Fatalf("Error setting role: %v", err)
+	}
+}
+
+func (a *testAPI) chirp(u testUser, body string) returnChirp {
+	a.t.Helper()
+
+	rec := a.expect(201, "POST", "/api/chirps", u.Token, map[string]string{"body": body})
+	return decode[returnChirp](a.t, rec)
+}
+
+// flushAudit writes the queued auth events, as the audit writer would.
+func (a *testAPI) flushAudit() {
+	a.t.Helper()
+
+	err := a.cfg.flushAuditQueue(context.Background())
+	if err != nil {
+		a.t.Fatalf("Error writing audit events: %v", err)
+	}
+}
+
+// auditActions returns the actions of every audit event, oldest first.
+func (a *testAPI) auditActions() []string {
+	a.t.Helper()
+
+	a.flushAudit()
+	events, err := a.store.GetAuditEvents(context.Background(), database.GetAuditEventsParams{Lim: 1000})
+	if err != nil {
+		a.t.Fatalf("Error getting audit events: %v", err)
+	}
+	actions := make([]string, len(events))
+	for i, e := range events {
+		actions[len(events)-1-i] = e.Action
+	}
+	return actions
+}
+
+func (a *testAPI) expectAudited(action string) {
+	a.t.Helper()
+
+	if !slices.Contains(a.auditActions(), action) {
+		a.t.Errorf("Want a %s audit event, got %v", action, a.auditActions())
+	}
+}
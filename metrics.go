@@ -0,0 +1,119 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+)
+
+type appMetrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	signups         prometheus.Counter
+	logins          *prometheus.CounterVec
+	chirpsCreated   prometheus.Counter
+	webhookEvents   *prometheus.CounterVec
+	deliveries      *prometheus.CounterVec
+}
+
+func newAppMetrics(cfg *apiConfig, db *sql.DB) *appMetrics {
+	reg := prometheus.NewRegistry()
+	factory := promauto.With(reg)
+	m := &appMetrics{
+		registry: reg,
+		requests: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "chirpy_http_requests_total",
+			Help: "HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "chirpy_http_request_duration_seconds",
+			Help:    "HTTP request latency by method, route and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		signups: factory.NewCounter(prometheus.CounterOpts{
+			Name: "chirpy_signups_total",
+			Help: "Users created.",
+		}),
+		logins: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "chirpy_logins_total",
+			Help: "Login attempts by result.",
+		}, []string{"result"}),
+		chirpsCreated: factory.NewCounter(prometheus.CounterOpts{
+			Name: "chirpy_chirps_created_total",
+			Help: "Chirps created.",
+		}),
+		webhookEvents: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "chirpy_webhook_events_total",
+			Help: "Inbound webhook events by provider and result.",
+		}, []string{"provider", "result"}),
+		deliveries: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "chirpy_webhook_deliveries_total",
+			Help: "Outbound webhook delivery attempts by result.",
+		}, []string{"result"}),
+	}
+
+	factory.NewCounterFunc(prometheus.CounterOpts{
+		Name: "chirpy_fileserver_hits_total",
+		Help: "Requests served from /app/.",
+	}, func() float64 {
+		return float64(cfg.fileserverHits.Load())
+	})
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	// There's no connection pool to report on when the handlers are tested
+	// against the in-memory store.
+	if db != nil {
+		reg.MustRegister(collectors.NewDBStatsCollector(db, "chirpy"))
+	}
+
+	return m
+}
+
+// handler serves the metrics in the Prometheus exposition format.
+func (m *appMetrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// counterValue reads a counter's current value, for the admin page.
+func counterValue(c prometheus.Counter) float64 {
+	var metric dto.Metric
+	err := c.Write(&metric)
+	if err != nil {
+		return 0
+	}
+	return metric.GetCounter().GetValue()
+}
+
+// middlewareRequestMetrics counts and times every request. Requests are
+// labelled with the mux pattern that matched rather than the raw path, so
+// IDs in the URL don't create a series each.
+func (cfg *apiConfig) middlewareRequestMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(w, r)
+
+		rec := recordFrom(r.Context())
+		if rec == nil {
+			return
+		}
+		route := rec.route
+		if route == "" {
+			route = "unmatched"
+		}
+
+		labels := []string{r.Method, route, strconv.Itoa(rec.statusCode())}
+		cfg.metrics.requests.WithLabelValues(labels...).Inc()
+		cfg.metrics.requestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	a := newTestAPI(t)
+	a.signup("alice")
+
+	rec := httptest.NewRecorder()
+	a.cfg.metrics.handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Code != 200 {
+		t.Fatalf("Want 200 from the metrics handler, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"chirpy_signups_total 1",
+		`chirpy_logins_total{result="success"} 1`,
+		`chirpy_http_requests_total{method="POST",route="/api/users",status="201"} 1`,
+		`chirpy_http_request_duration_seconds_count{method="POST",route="/api/users",status="201"} 1`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Want %q in metrics, got:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "go_sql_") {
+		t.Errorf("Want no connection pool metrics without a database, got:\n%s", body)
+	}
+}
+
+// TestMetricsNotPublic checks that /metrics is only served on the metrics
+// listener, not the public one.
+func TestMetricsNotPublic(t *testing.T) {
+	a := newTestAPI(t)
+	a.expect(404, "GET", "/metrics", "", nil)
+}
+
+// TestMetricsCounters checks the business counters, and that requests are
+// labelled by route rather than by the path they were made to.
+func TestMetricsCounters(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	a.expect(401, "POST", "/api/login", "", map[string]string{"email": alice.Email, "password": "wrong"})
+	c := a.chirp(alice, "counted")
+	a.expect(200, "GET", "/api/chirps/"+c.ID.String(), "", nil)
+	a.expect(404, "GET", "/nowhere", "", nil)
+	a.polka(map[string]any{"id": "evt_1", "event": "user.upgraded", "data": map[string]any{"user_id": alice.ID}}, testPolkaSecret)
+	a.polka(map[string]any{"id": "evt_2", "event": "user.upgraded", "data": map[string]any{"user_id": alice.ID}}, "wrong")
+
+	rec := httptest.NewRecorder()
+	a.cfg.metrics.handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`chirpy_logins_total{result="failure"} 1`,
+		"chirpy_chirps_created_total 1",
+		`chirpy_http_requests_total{method="GET",route="/api/chirps/{chirpID}",status="200"} 1`,
+		`chirpy_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`chirpy_webhook_events_total{provider="polka",result="processed"} 1`,
+		`chirpy_webhook_events_total{provider="polka",result="invalid_signature"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Want %q in metrics, got:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, c.ID.String()) {
+		t.Errorf("Want no chirp IDs in route labels, got:\n%s", body)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joshckidd/chirpy/internal/database"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+//go:embed sql/schema/*.sql sql/sqlite/schema/*.sql
+var schemaFiles embed.FS
+
+// migrationLockID is the Postgres advisory lock held while migrating, so
+// replicas starting together don't apply the same migration twice.
+const migrationLockID = 0x6368697270790001
+
+// newMigrator returns a goose provider for the migrations built into the
+// binary, so it always knows which schema version the code needs. SQLite
+// has its own migration set, as the Postgres one uses types and functions
+// it lacks.
+func newMigrator(db *sql.DB, dialect database.Dialect) (*goose.Provider, error) {
+	if dialect == database.SQLite {
+		fsys, err := fs.Sub(schemaFiles, "sql/sqlite/schema")
+		if err != nil {
+			return nil, err
+		}
+		return goose.NewProvider(goose.DialectSQLite3, db, fsys)
+	}
+
+	fsys, err := fs.Sub(schemaFiles, "sql/schema")
+	if err != nil {
+		return nil, err
+	}
+	locker, err := lock.NewPostgresSessionLocker(lock.WithLockID(migrationLockID))
+	if err != nil {
+		return nil, err
+	}
+	return goose.NewProvider(goose.DialectPostgres, db, fsys, goose.WithSessionLocker(locker))
+}
+
+// runMigrate runs the migrate subcommand.
+func runMigrate(ctx context.Context, db *sql.DB, dialect database.Dialect, action string) error {
+	p, err := newMigrator(db, dialect)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "up":
+		applied, err := p.Up(ctx)
+		for _, res := range applied {
+			fmt.Println("applied", res.Source.Path)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return nil
+	case "down":
+		res, err := p.Down(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Println("rolled back", res.Source.Path)
+		return nil
+	case "redo":
+		res, err := p.Down(ctx)
+		if err != nil {
+			return err
+		}
+		res, err = p.ApplyVersion(ctx, res.Source.Version, true)
+		if err != nil {
+			return err
+		}
+		fmt.Println("redid", res.Source.Path)
+		return nil
+	case "status":
+		statuses, err := p.Status(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "APPLIED AT\tMIGRATION")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.State == goose.StateApplied {
+				appliedAt = s.AppliedAt.Format(time.DateTime)
+			}
+			fmt.Fprintf(tw, "%s\t%s\n", appliedAt, s.Source.Path)
+		}
+		return tw.Flush()
+	default:
+		return errors.New(cliUsage)
+	}
+}
+
+// checkSchema applies pending migrations if asked to, then makes sure the
+// database isn't behind the code, which would fail queries at random.
+func checkSchema(ctx context.Context, p *goose.Provider, autoMigrate bool, logger *slog.Logger) error {
+	if autoMigrate {
+		applied, err := p.Up(ctx)
+		for _, res := range applied {
+			logger.Info("applied migration", "migration", res.Source.Path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	version, want, err := p.GetVersions(ctx)
+	if err != nil {
+		return err
+	}
+	if version < want {
+		return fmt.Errorf("database schema is at version %d but version %d is needed; run chirpy migrate up or set AUTO_MIGRATE", version, want)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func TestNotifications(t *testing.T) {
+	a := newTestAPI(t)
+	mod := a.signup("mod")
+	a.setRole(mod, roleModerator)
+	bob := a.signup("bob")
+
+	a.expect(401, "GET", "/api/notifications", "", nil)
+	notifications := decode[[]database.Notification](t, a.expect(200, "GET", "/api/notifications", bob.Token, nil))
+	if len(notifications) != 0 {
+		t.Errorf("Want no notifications, got %+v", notifications)
+	}
+
+	a.expect(201, "PUT", "/admin/users/"+bob.ID.String()+"/suspension", mod.Token, map[string]any{"action": "read_only", "reason": "cool off"})
+	notifications = decode[[]database.Notification](t, a.expect(200, "GET", "/api/notifications", bob.Token, nil))
+	if len(notifications) != 1 || notifications[0].Kind != "moderation" || notifications[0].ReadAt.Valid {
+		t.Fatalf("Want an unread moderation notification, got %+v", notifications)
+	}
+
+	a.expect(401, "POST", "/api/notifications/read", "", nil)
+	a.expect(204, "POST", "/api/notifications/read", bob.Token, nil)
+	notifications = decode[[]database.Notification](t, a.expect(200, "GET", "/api/notifications", bob.Token, nil))
+	if len(notifications) != 1 || !notifications[0].ReadAt.Valid {
+		t.Errorf("Want the notification read, got %+v", notifications)
+	}
+}
@@ -0,0 +1,576 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/auth"
+	"github.com/joshckidd/chirpy/internal/database"
+	"github.com/joshckidd/chirpy/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	outboxBatchSize       = 100
+	deliveryBatchSize     = 20
+	maxDeliveryAttempts   = 8
+	deliveryBaseBackoff   = 30 * time.Second
+	deliveryMaxBackoff    = 6 * time.Hour
+	deliveryTimeout       = 10 * time.Second
+	deliveryResponseLimit = 1 << 10
+)
+
+var webhookEventTypes = []string{
+	"chirp.created",
+	"chirp.deleted",
+	"user.followed",
+	"user.upgraded",
+}
+
+// webhookClient only connects to public addresses, so that an endpoint
+// can't be used to reach the network Chirpy runs in. The address is checked
+// as each connection is dialed, after DNS resolution, so a name that later
+// resolves somewhere private is still refused.
+var webhookClient = &http.Client{
+	Timeout:   deliveryTimeout,
+	Transport: newWebhookTransport(),
+}
+
+// webhookAddrAllowed reports whether webhooks may be sent to ip. Tests
+// replace it to deliver to servers on the loopback interface.
+var webhookAddrAllowed = isPublicAddr
+
+// nonPublicPrefixes are the ranges netip has no predicate for.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+func newWebhookTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would connect on our behalf, past the check on the address.
+	t.Proxy = nil
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !webhookAddrAllowed(addr.Addr()) {
+				return fmt.Errorf("webhook address %s is not public", addr.Addr())
+			}
+			return nil
+		},
+	}
+	t.DialContext = dialer.DialContext
+	return t
+}
+
+// isPublicAddr reports whether ip is outside the loopback, private,
+// link-local, multicast and unspecified ranges.
+func isPublicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+type returnWebhookEndpoint struct {
+	ID        uuid.UUID     `json:"id"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	UserID    uuid.NullUUID `json:"user_id"`
+	Url       string        `json:"url"`
+	Events    []string      `json:"events"`
+	Active    bool          `json:"active"`
+	Secret    string        `json:"secret,omitempty"`
+}
+
+// enqueueEvent records an outbound event in the outbox. Callers pass the
+// Queries for the transaction making the change, so the event exists if and
+// only if the change commits. userID is the user the event is about; their
+// own endpoints receive it along with the global ones. The trace in ctx is
+// stored with the event so its deliveries join the same trace.
+func enqueueEvent(ctx context.Context, q database.Querier, eventType string, userID uuid.UUID, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	carrier := propagation.MapCarrier{}
+	tracing.Propagator.Inject(ctx, carrier)
+
+	_, err = q.CreateOutboxEvent(ctx, database.CreateOutboxEventParams{
+		EventType:   eventType,
+		UserID:      uuid.NullUUID{UUID: userID, Valid: true},
+		Payload:     payload,
+		Traceparent: carrier.Get("traceparent"),
+	})
+	return err
+}
+
+// dispatchOutbox fans new outbox events out into one delivery per
+// subscribed endpoint.
+func (cfg *apiConfig) dispatchOutbox(ctx context.Context) error {
+	tx, err := cfg.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	events, err := tx.GetUndispatchedOutboxEvents(ctx, outboxBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		err = tx.CreateWebhookDeliveriesForEvent(ctx, database.CreateWebhookDeliveriesForEventParams{
+			EventID:   event.ID,
+			EventType: event.EventType,
+			UserID:    event.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = tx.MarkOutboxEventDispatched(ctx, event.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// deliverWebhooks sends the deliveries that are due. Claiming a delivery
+// pushes its next attempt out, so a crashed worker's claims are picked up
+// again later instead of being lost.
+func (cfg *apiConfig) deliverWebhooks(ctx context.Context) error {
+	deliveries, err := cfg.db.ClaimDueWebhookDeliveries(ctx, deliveryBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		err = cfg.deliverWebhook(ctx, delivery)
+		if err != nil {
+			cfg.logs.Logger("webhooks").Error("failed to record webhook delivery",
+				"delivery_id", delivery.ID,
+				"error", err,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (cfg *apiConfig) deliverWebhook(ctx context.Context, delivery database.WebhookDelivery) (err error) {
+	event, err := cfg.db.GetOutboxEvent(ctx, delivery.EventID)
+	if err != nil {
+		return err
+	}
+
+	ctx = tracing.Propagator.Extract(ctx, propagation.MapCarrier{"traceparent": event.Traceparent})
+	ctx, span := cfg.tracer.Start(ctx, "webhook "+event.EventType,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("webhook.delivery_id", delivery.ID.String()),
+			attribute.Int("webhook.attempt", int(delivery.Attempts)+1),
+		),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	endpoint, err := cfg.db.GetWebhookEndpoint(ctx, delivery.EndpointID)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(struct {
+		ID        uuid.UUID       `json:"id"`
+		Type      string          `json:"type"`
+		CreatedAt time.Time       `json:"created_at"`
+		Data      json.RawMessage `json:"data"`
+	}{
+		ID:        event.ID,
+		Type:      event.EventType,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	statusCode, sendErr := sendWebhook(ctx, endpoint, delivery.ID, event.EventType, body)
+	span.SetAttributes(semconv.HTTPResponseStatusCode(statusCode))
+	if sendErr != nil {
+		span.SetStatus(codes.Error, sendErr.Error())
+	}
+	if sendErr == nil {
+		cfg.metrics.deliveries.WithLabelValues("succeeded").Inc()
+		return cfg.db.MarkWebhookDeliverySucceeded(ctx, database.MarkWebhookDeliverySucceededParams{
+			LastStatusCode: sql.NullInt32{Int32: int32(statusCode), Valid: true},
+			ID:             delivery.ID,
+		})
+	}
+
+	status, result := "pending", "failed"
+	if delivery.Attempts+1 >= maxDeliveryAttempts {
+		status, result = "dead", "dead"
+	}
+	cfg.metrics.deliveries.WithLabelValues(result).Inc()
+
+	return cfg.db.MarkWebhookDeliveryFailed(ctx, database.MarkWebhookDeliveryFailedParams{
+		Status:         status,
+		LastStatusCode: sql.NullInt32{Int32: int32(statusCode), Valid: statusCode != 0},
+		LastError:      sql.NullString{String: sendErr.Error(), Valid: true},
+		BackoffSeconds: deliveryBackoff(delivery.Attempts).Seconds(),
+		ID:             delivery.ID,
+	})
+}
+
+// sendWebhook posts a signed event to the endpoint. It returns the response
+// status code, or 0 if no response was received.
+func sendWebhook(ctx context.Context, endpoint database.WebhookEndpoint, deliveryID uuid.UUID, eventType string, body []byte) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Chirpy-Event", eventType)
+	req.Header.Set("X-Chirpy-Delivery", deliveryID.String())
+	req.Header.Set("X-Chirpy-Timestamp", timestamp)
+	req.Header.Set("X-Chirpy-Signature", auth.SignWebhook(timestamp, body, endpoint.Secret))
+	tracing.Propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, deliveryResponseLimit))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// deliveryBackoff doubles the wait after every failed attempt, up to a cap.
+func deliveryBackoff(attempts int32) time.Duration {
+	backoff := deliveryBaseBackoff
+	for i := int32(0); i < attempts; i++ {
+		backoff *= 2
+		if backoff >= deliveryMaxBackoff {
+			return deliveryMaxBackoff
+		}
+	}
+	return backoff
+}
+
+func (cfg *apiConfig) postWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	if !cfg.checkCanWrite(w, r, id) {
+		return
+	}
+
+	cfg.createWebhookEndpoint(w, r, uuid.NullUUID{UUID: id, Valid: true})
+}
+
+func (cfg *apiConfig) getWebhookEndpoints(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	endpoints, err := cfg.db.GetWebhookEndpointsForUser(r.Context(), uuid.NullUUID{UUID: id, Valid: true})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 200, toReturnWebhookEndpoints(endpoints))
+}
+
+func (cfg *apiConfig) deleteWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	if !cfg.checkCanWrite(w, r, id) {
+		return
+	}
+
+	endpoint, ok := cfg.getOwnedWebhookEndpoint(w, r, uuid.NullUUID{UUID: id, Valid: true})
+	if !ok {
+		return
+	}
+
+	err = cfg.db.DeleteWebhookEndpoint(r.Context(), endpoint.ID)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 204, nil)
+}
+
+func (cfg *apiConfig) getWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	endpoint, ok := cfg.getOwnedWebhookEndpoint(w, r, uuid.NullUUID{UUID: id, Valid: true})
+	if !ok {
+		return
+	}
+
+	cfg.writeWebhookDeliveries(w, r, endpoint)
+}
+
+func (cfg *apiConfig) postAdminWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	cfg.createWebhookEndpoint(w, r, uuid.NullUUID{})
+}
+
+func (cfg *apiConfig) getAdminWebhookEndpoints(w http.ResponseWriter, r *http.Request) {
+	endpoints, err := cfg.db.GetGlobalWebhookEndpoints(r.Context())
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 200, toReturnWebhookEndpoints(endpoints))
+}
+
+func (cfg *apiConfig) deleteAdminWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	endpoint, ok := cfg.getOwnedWebhookEndpoint(w, r, uuid.NullUUID{})
+	if !ok {
+		return
+	}
+
+	err := cfg.db.DeleteWebhookEndpoint(r.Context(), endpoint.ID)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	cfg.audit(r, auditRecord{
+		Action:     "admin.webhook_endpoint_deleted",
+		ActorID:    actorID(r),
+		TargetType: "webhook_endpoint",
+		TargetID:   endpoint.ID.String(),
+	})
+	respondWithJSON(w, 204, nil)
+}
+
+func (cfg *apiConfig) getAdminWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	endpoint, ok := cfg.getOwnedWebhookEndpoint(w, r, uuid.NullUUID{})
+	if !ok {
+		return
+	}
+
+	cfg.writeWebhookDeliveries(w, r, endpoint)
+}
+
+// createWebhookEndpoint registers an endpoint for owner, or a global
+// endpoint that receives every event when owner is null. The signing secret
+// is only ever returned here.
+func (cfg *apiConfig) createWebhookEndpoint(w http.ResponseWriter, r *http.Request, owner uuid.NullUUID) {
+	type endpointParams struct {
+		Url    string   `json:"url"`
+		Events []string `json:"events"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := endpointParams{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Invalid request")
+		return
+	}
+
+	u, err := url.Parse(params.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		respondWithError(w, 400, "Endpoint URL must be an absolute http or https URL")
+		return
+	}
+	// Names are checked when deliveries connect, as what they resolve to
+	// can change; addresses can be refused now.
+	if ip, err := netip.ParseAddr(u.Hostname()); err == nil && !webhookAddrAllowed(ip) {
+		respondWithError(w, 400, "Endpoint URL must not point at a private address")
+		return
+	}
+
+	if len(params.Events) == 0 {
+		respondWithError(w, 400, "At least one event is required")
+		return
+	}
+	for _, event := range params.Events {
+		if !slices.Contains(webhookEventTypes, event) {
+			respondWithError(w, 400, "Unknown event: "+event)
+			return
+		}
+	}
+
+	secret, err := auth.MakeRefreshToken()
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	endpoint, err := cfg.db.CreateWebhookEndpoint(r.Context(), database.CreateWebhookEndpointParams{
+		UserID: owner,
+		Url:    u.String(),
+		Secret: secret,
+		Events: params.Events,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	// Only global endpoints are an admin action; users managing their own
+	// aren't audited.
+	if !owner.Valid {
+		cfg.audit(r, auditRecord{
+			Action:     "admin.webhook_endpoint_created",
+			ActorID:    actorID(r),
+			TargetType: "webhook_endpoint",
+			TargetID:   endpoint.ID.String(),
+			Metadata:   map[string]any{"url": endpoint.Url, "events": endpoint.Events},
+		})
+	}
+
+	ret := toReturnWebhookEndpoint(endpoint)
+	ret.Secret = endpoint.Secret
+	respondWithJSON(w, 201, ret)
+}
+
+// getOwnedWebhookEndpoint loads the endpoint named in the path and checks
+// that it belongs to owner, writing the error response if not.
+func (cfg *apiConfig) getOwnedWebhookEndpoint(w http.ResponseWriter, r *http.Request, owner uuid.NullUUID) (database.WebhookEndpoint, bool) {
+	endpointID, err := uuid.Parse(r.PathValue("endpointID"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return database.WebhookEndpoint{}, false
+	}
+
+	endpoint, err := cfg.db.GetWebhookEndpoint(r.Context(), endpointID)
+	if err != nil || endpoint.UserID != owner {
+		respondWithError(w, 404, "Webhook endpoint not found")
+		return database.WebhookEndpoint{}, false
+	}
+
+	return endpoint, true
+}
+
+func (cfg *apiConfig) writeWebhookDeliveries(w http.ResponseWriter, r *http.Request, endpoint database.WebhookEndpoint) {
+	limit, offset, err := getPagination(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	deliveries, err := cfg.db.GetWebhookDeliveriesForEndpoint(r.Context(), database.GetWebhookDeliveriesForEndpointParams{
+		EndpointID: endpoint.ID,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	if deliveries == nil {
+		deliveries = []database.WebhookDelivery{}
+	}
+
+	respondWithJSON(w, 200, deliveries)
+}
+
+func toReturnWebhookEndpoint(endpoint database.WebhookEndpoint) returnWebhookEndpoint {
+	return returnWebhookEndpoint{
+		ID:        endpoint.ID,
+		CreatedAt: endpoint.CreatedAt,
+		UpdatedAt: endpoint.UpdatedAt,
+		UserID:    endpoint.UserID,
+		Url:       endpoint.Url,
+		Events:    endpoint.Events,
+		Active:    endpoint.Active,
+	}
+}
+
+func toReturnWebhookEndpoints(endpoints []database.WebhookEndpoint) []returnWebhookEndpoint {
+	ret := []returnWebhookEndpoint{}
+	for _, endpoint := range endpoints {
+		ret = append(ret, toReturnWebhookEndpoint(endpoint))
+	}
+	return ret
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/auth"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+// webhookReceiver starts a server that records the webhooks posted to it
+// and responds with status.
+func webhookReceiver(t *testing.T, status int) (*httptest.Server, func() []receivedWebhook) {
+	t.Helper()
+	allowLoopbackWebhooks(t)
+
+	var mu sync.Mutex
+	var received []receivedWebhook
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, receivedWebhook{header: r.Header.Clone(), body: body})
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, func() []receivedWebhook {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]receivedWebhook(nil), received...)
+	}
+}
+
+// allowLoopbackWebhooks lets webhooks be sent to test servers until the
+// test ends.
+func allowLoopbackWebhooks(t *testing.T) {
+	allowed := webhookAddrAllowed
+	webhookAddrAllowed = func(ip netip.Addr) bool { return ip.IsLoopback() || allowed(ip) }
+	t.Cleanup(func() { webhookAddrAllowed = allowed })
+}
+
+func (a *testAPI) runOutbox() {
+	a.t.Helper()
+
+	ctx := context.Background()
+	err := a.cfg.dispatchOutbox(ctx)
+	if err != nil {
+		a.t.Fatalf("dispatchOutbox returned error: %v", err)
+	}
+	err = a.cfg.deliverWebhooks(ctx)
+	if err != nil {
+		a.t.Fatalf("deliverWebhooks returned error: %v", err)
+	}
+}
+
+func TestUserWebhookEndpoints(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	srv, received := webhookReceiver(t, 200)
+
+	a.expect(401, "POST", "/api/webhooks", "", map[string]any{"url": srv.URL, "events": []string{"chirp.created"}})
+	a.expect(400, "POST", "/api/webhooks", alice.Token, map[string]any{"url": "ftp://example.com", "events": []string{"chirp.created"}})
+	a.expect(400, "POST", "/api/webhooks", alice.Token, map[string]any{"url": srv.URL})
+	a.expect(400, "POST", "/api/webhooks", alice.Token, map[string]any{"url": srv.URL, "events": []string{"chirp.liked"}})
+
+	endpoint := decode[returnWebhookEndpoint](t, a.expect(201, "POST", "/api/webhooks", alice.Token, map[string]any{"url": srv.URL, "events": []string{"chirp.created"}}))
+	if endpoint.Secret == "" || endpoint.UserID.UUID != alice.ID {
+		t.Fatalf("Want alice's endpoint with its secret, got %+v", endpoint)
+	}
+
+	endpoints := decode[[]returnWebhookEndpoint](t, a.expect(200, "GET", "/api/webhooks", alice.Token, nil))
+	if len(endpoints) != 1 || endpoints[0].ID != endpoint.ID || endpoints[0].Secret != "" {
+		t.Errorf("Want alice's endpoint without its secret, got %+v", endpoints)
+	}
+	endpoints = decode[[]returnWebhookEndpoint](t, a.expect(200, "GET", "/api/webhooks", bob.Token, nil))
+	if len(endpoints) != 0 {
+		t.Errorf("Want no endpoints for bob, got %+v", endpoints)
+	}
+
+	// Only alice's own chirps are sent to her endpoint.
+	c := a.chirp(alice, "hello hooks")
+	a.chirp(bob, "not for alice")
+	a.runOutbox()
+
+	got := received()
+	if len(got) != 1 {
+		t.Fatalf("Want 1 webhook, got %d", len(got))
+	}
+	h := got[0].header
+	if h.Get("X-Chirpy-Event") != "chirp.created" {
+		t.Errorf("Want a chirp.created event, got %q", h.Get("X-Chirpy-Event"))
+	}
+	err := auth.ValidateWebhookSignature(h.Get("X-Chirpy-Timestamp"), h.Get("X-Chirpy-Signature"), got[0].body, []string{endpoint.Secret}, time.Minute)
+	if err != nil {
+		t.Errorf("Want a valid signature, got %v", err)
+	}
+	var event struct {
+		Type string         `json:"type"`
+		Data database.Chirp `json:"data"`
+	}
+	err = json.Unmarshal(got[0].body, &event)
+	if err != nil || event.Data.ID != c.ID {
+		t.Errorf("Want the chirp in the event, got %s, %v", got[0].body, err)
+	}
+
+	path := "/api/webhooks/" + endpoint.ID.String()
+	a.expect(404, "GET", path+"/deliveries", bob.Token, nil)
+	deliveries := decode[[]database.WebhookDelivery](t, a.expect(200, "GET", path+"/deliveries", alice.Token, nil))
+	if len(deliveries) != 1 || deliveries[0].Status != "succeeded" {
+		t.Errorf("Want a succeeded delivery, got %+v", deliveries)
+	}
+
+	a.expect(404, "DELETE", path, bob.Token, nil)
+	a.expect(204, "DELETE", path, alice.Token, nil)
+	a.expect(404, "GET", path+"/deliveries", alice.Token, nil)
+}
+
+func TestWebhookDeliveryRetry(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	srv, received := webhookReceiver(t, 503)
+
+	endpoint := decode[returnWebhookEndpoint](t, a.expect(201, "POST", "/api/webhooks", alice.Token, map[string]any{"url": srv.URL, "events": []string{"chirp.created"}}))
+	a.chirp(alice, "try again")
+	a.runOutbox()
+	// The retry isn't due yet.
+	a.runOutbox()
+
+	if n := len(received()); n != 1 {
+		t.Errorf("Want 1 attempt, got %d", n)
+	}
+	deliveries := decode[[]database.WebhookDelivery](t, a.expect(200, "GET", "/api/webhooks/"+endpoint.ID.String()+"/deliveries", alice.Token, nil))
+	if len(deliveries) != 1 || deliveries[0].Status != "pending" || deliveries[0].Attempts != 1 || deliveries[0].LastStatusCode.Int32 != 503 {
+		t.Errorf("Want a pending delivery after one failed attempt, got %+v", deliveries)
+	}
+}
+
+func TestAdminWebhookEndpoints(t *testing.T) {
+	a := newTestAPI(t)
+	admin := a.signup("admin")
+	a.setRole(admin, roleAdmin)
+	alice := a.signup("alice")
+	srv, received := webhookReceiver(t, 204)
+
+	a.expect(403, "POST", "/admin/webhooks/endpoints", alice.Token, map[string]any{"url": srv.URL, "events": []string{"user.followed"}})
+	endpoint := decode[returnWebhookEndpoint](t, a.expect(201, "POST", "/admin/webhooks/endpoints", admin.Token, map[string]any{"url": srv.URL, "events": []string{"user.followed"}}))
+	if endpoint.UserID.Valid {
+		t.Errorf("Want a global endpoint, got %+v", endpoint)
+	}
+	a.expectAudited("admin.webhook_endpoint_created")
+
+	endpoints := decode[[]returnWebhookEndpoint](t, a.expect(200, "GET", "/admin/webhooks/endpoints", admin.Token, nil))
+	if len(endpoints) != 1 || endpoints[0].ID != endpoint.ID {
+		t.Errorf("Want the global endpoint, got %+v", endpoints)
+	}
+
+	// Global endpoints get every user's events.
+	a.expect(200, "POST", "/api/users/"+admin.ID.String()+"/follow", alice.Token, nil)
+	a.runOutbox()
+	if n := len(received()); n != 1 {
+		t.Errorf("Want 1 webhook, got %d", n)
+	}
+
+	path := "/admin/webhooks/endpoints/" + endpoint.ID.String()
+	deliveries := decode[[]database.WebhookDelivery](t, a.expect(200, "GET", path+"/deliveries", admin.Token, nil))
+	if len(deliveries) != 1 || deliveries[0].Status != "succeeded" {
+		t.Errorf("Want a succeeded delivery, got %+v", deliveries)
+	}
+	a.expect(404, "GET", "/admin/webhooks/endpoints/"+uuid.NewString()+"/deliveries", admin.Token, nil)
+
+	a.expect(204, "DELETE", path, admin.Token, nil)
+	a.expect(404, "DELETE", path, admin.Token, nil)
+}
+
+func TestDeliveryBackoff(t *testing.T) {
+	if got := deliveryBackoff(0); got != deliveryBaseBackoff {
+		t.Errorf("Want %v, got %v", deliveryBaseBackoff, got)
+	}
+	if got := deliveryBackoff(2); got != 4*deliveryBaseBackoff {
+		t.Errorf("Want %v, got %v", 4*deliveryBaseBackoff, got)
+	}
+	if got := deliveryBackoff(100); got != deliveryMaxBackoff {
+		t.Errorf("Want %v, got %v", deliveryMaxBackoff, got)
+	}
+}
+
+func TestWebhookEndpointPrivateAddresses(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+
+	for _, u := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"https://10.1.2.3/hook",
+		"http://192.168.0.1/hook",
+		"http://[::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		rec := a.do("POST", "/api/webhooks", alice.Token, map[string]any{"url": u, "events": []string{"chirp.created"}})
+		if rec.Code != 400 {
+			t.Errorf("%s: want 400, got %d", u, rec.Code)
+		}
+	}
+}
+
+// TestWebhookDeliveryPrivateAddresses checks that a name is refused once
+// it resolves to a private address, which registration can't catch.
+func TestWebhookDeliveryPrivateAddresses(t *testing.T) {
+	var received atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Store(true)
+	}))
+	t.Cleanup(srv.Close)
+
+	u, _ := url.Parse(srv.URL)
+	endpoint := database.WebhookEndpoint{
+		Url:    "http://localhost:" + u.Port(),
+		Secret: "secret",
+	}
+	code, err := sendWebhook(context.Background(), endpoint, uuid.New(), "chirp.created", []byte("{}"))
+	if err == nil || code != 0 || received.Load() {
+		t.Errorf("Want the connection refused, got %d, %v", code, err)
+	}
+
+	allowLoopbackWebhooks(t)
+	code, err = sendWebhook(context.Background(), endpoint, uuid.New(), "chirp.created", []byte("{}"))
+	if err != nil || code != 200 || !received.Load() {
+		t.Errorf("Want the webhook delivered to a loopback address when allowed, got %d, %v", code, err)
+	}
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.216.34":      true,
+		"2606:4700::1111":    true,
+		"127.0.0.1":          false,
+		"10.0.0.1":           false,
+		"172.16.5.4":         false,
+		"192.168.1.1":        false,
+		"169.254.169.254":    false,
+		"100.64.0.1":         false,
+		"224.0.0.1":          false,
+		"0.0.0.0":            false,
+		"::":                 false,
+		"::1":                false,
+		"fe80::1":            false,
+		"fd00::1":            false,
+		"ff02::1":            false,
+		"::ffff:192.168.1.1": false,
+	}
+	for addr, want := range tests {
+		if got := isPublicAddr(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("%s: want %v, got %v", addr, want, got)
+		}
+	}
+}
+
+// TestWebhookDeliveryDead checks that a delivery is retried once its
+// backoff has passed, and given up on after maxDeliveryAttempts.
+func TestWebhookDeliveryDead(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	srv, received := webhookReceiver(t, 500)
+
+	endpoint := decode[returnWebhookEndpoint](t, a.expect(201, "POST", "/api/webhooks", alice.Token, map[string]any{"url": srv.URL, "events": []string{"chirp.created"}}))
+	a.chirp(alice, "nobody home")
+	start := time.Now()
+	for i := range maxDeliveryAttempts + 2 {
+		a.store.SetClock(func() time.Time { return start.Add(time.Duration(i) * (deliveryMaxBackoff + time.Minute)) })
+		a.runOutbox()
+	}
+
+	if n := len(received()); n != maxDeliveryAttempts {
+		t.Errorf("Want %d attempts, got %d", maxDeliveryAttempts, n)
+	}
+	deliveries := decode[[]database.WebhookDelivery](t, a.expect(200, "GET", "/api/webhooks/"+endpoint.ID.String()+"/deliveries", alice.Token, nil))
+	if len(deliveries) != 1 || deliveries[0].Status != "dead" || deliveries[0].Attempts != maxDeliveryAttempts {
+		t.Errorf("Want a dead delivery, got %+v", deliveries)
+	}
+}
+
+func TestWebhookDeliveryRecovers(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	allowLoopbackWebhooks(t)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(503)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	endpoint := decode[returnWebhookEndpoint](t, a.expect(201, "POST", "/api/webhooks", alice.Token, map[string]any{"url": srv.URL, "events": []string{"chirp.created"}}))
+	a.chirp(alice, "second time lucky")
+	a.runOutbox()
+	a.store.SetClock(func() time.Time { return time.Now().Add(deliveryBaseBackoff + time.Second) })
+	a.runOutbox()
+
+	deliveries := decode[[]database.WebhookDelivery](t, a.expect(200, "GET", "/api/webhooks/"+endpoint.ID.String()+"/deliveries", alice.Token, nil))
+	if calls.Load() != 2 || len(deliveries) != 1 || deliveries[0].Status != "succeeded" || deliveries[0].Attempts != 2 {
+		t.Errorf("Want the retry to succeed, got %d calls and %+v", calls.Load(), deliveries)
+	}
+}
+
+// TestWebhookEventTypes checks that each event type is sent, and only to
+// endpoints subscribed to it.
+func TestWebhookEventTypes(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	srv, received := webhookReceiver(t, 200)
+	a.expect(201, "POST", "/api/webhooks", alice.Token, map[string]any{"url": srv.URL, "events": []string{"chirp.deleted", "user.upgraded"}})
+
+	c := a.chirp(alice, "short lived")
+	a.expect(204, "DELETE", "/api/chirps/"+c.ID.String(), alice.Token, nil)
+	a.expect(200, "POST", "/api/users/"+bob.ID.String()+"/follow", alice.Token, nil)
+	rec := a.polka(map[string]any{"id": "evt_1", "event": "user.upgraded", "data": map[string]any{"user_id": alice.ID}}, testPolkaSecret)
+	if rec.Code != 204 {
+		t.Fatalf("Want 204 upgrading alice, got %d", rec.Code)
+	}
+	a.runOutbox()
+
+	var events []string
+	for _, w := range received() {
+		events = append(events, w.header.Get("X-Chirpy-Event"))
+	}
+	if len(events) != 2 || events[0] != "chirp.deleted" || events[1] != "user.upgraded" {
+		t.Errorf("Want chirp.deleted and user.upgraded, got %v", events)
+	}
+}
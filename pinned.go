@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/auth"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func (cfg *apiConfig) putPinnedChirp(w http.ResponseWriter, r *http.Request) {
+	type pinParam struct {
+		ChirpID uuid.UUID `json:"chirp_id"`
+	}
+
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	if !cfg.checkCanWrite(w, r, id) {
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := pinParam{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Invalid request")
+		return
+	}
+
+	c, err := cfg.db.GetChirp(r.Context(), params.ChirpID)
+	if err != nil {
+		respondWithError(w, 404, "Chirp not found")
+		return
+	}
+	if c.UserID != id {
+		respondWithError(w, 403, "Unauthorized user")
+		return
+	}
+
+	limits, err := cfg.getLimits(r.Context(), id)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	pinned, err := cfg.db.GetPinnedChirpIDs(r.Context(), id)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	if slices.Contains(pinned, c.ID) {
+		respondWithJSON(w, 204, nil)
+		return
+	}
+
+	if len(pinned) >= limits.MaxPinnedChirps {
+		respondWithError(w, 400, "Pinned chirp limit reached")
+		return
+	}
+
+	err = cfg.db.PinChirp(r.Context(), database.PinChirpParams{
+		UserID:  id,
+		ChirpID: c.ID,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 204, nil)
+}
+
+func (cfg *apiConfig) deletePinnedChirp(w http.ResponseWriter, r *http.Request) {
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	if !cfg.checkCanWrite(w, r, id) {
+		return
+	}
+
+	chirpID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	err = cfg.db.UnpinChirp(r.Context(), database.UnpinChirpParams{
+		UserID:  id,
+		ChirpID: chirpID,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 204, nil)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPinnedChirps(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	older := a.chirp(alice, "older")
+	newer := a.chirp(alice, "newer")
+	bobs := a.chirp(bob, "not alice's")
+
+	a.expect(401, "PUT", "/api/users/me/pinned", "", map[string]any{"chirp_id": older.ID})
+	a.expect(404, "PUT", "/api/users/me/pinned", alice.Token, map[string]any{"chirp_id": uuid.New()})
+	a.expect(403, "PUT", "/api/users/me/pinned", alice.Token, map[string]any{"chirp_id": bobs.ID})
+	a.expect(204, "PUT", "/api/users/me/pinned", alice.Token, map[string]any{"chirp_id": older.ID})
+
+	// The free plan allows one pinned chirp.
+	a.expect(400, "PUT", "/api/users/me/pinned", alice.Token, map[string]any{"chirp_id": newer.ID})
+
+	path := "/api/chirps?author_id=" + alice.ID.String() + "&sort=desc&pinned=true"
+	chirps := decode[[]returnChirp](t, a.expect(200, "GET", path, "", nil))
+	if len(chirps) != 2 || chirps[0].ID != older.ID || !chirps[0].Pinned {
+		t.Errorf("Want the pinned chirp first, got %+v", chirps)
+	}
+
+	a.expect(204, "DELETE", "/api/users/me/pinned/"+older.ID.String(), alice.Token, nil)
+	chirps = decode[[]returnChirp](t, a.expect(200, "GET", path, "", nil))
+	if len(chirps) != 2 || chirps[0].Pinned || chirps[1].Pinned {
+		t.Errorf("Want no pinned chirps, got %+v", chirps)
+	}
+	a.expect(204, "PUT", "/api/users/me/pinned", alice.Token, map[string]any{"chirp_id": newer.ID})
+}
+
+func TestPinnedChirpsRed(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	var chirps []returnChirp
+	for _, body := range []string{"one", "two", "three", "four"} {
+		chirps = append(chirps, a.chirp(alice, body))
+	}
+	rec := a.polka(map[string]any{"id": "evt_1", "event": "user.upgraded", "data": map[string]any{"user_id": alice.ID}}, testPolkaSecret)
+	if rec.Code != 204 {
+		t.Fatalf("Want 204 upgrading alice, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Red allows three pinned chirps.
+	for _, c := range chirps[:3] {
+		a.expect(204, "PUT", "/api/users/me/pinned", alice.Token, map[string]any{"chirp_id": c.ID})
+	}
+	a.expect(400, "PUT", "/api/users/me/pinned", alice.Token, map[string]any{"chirp_id": chirps[3].ID})
+
+	got := decode[[]returnChirp](t, a.expect(200, "GET", "/api/chirps?author_id="+alice.ID.String()+"&sort=desc&pinned=true", "", nil))
+	if len(got) != 4 || !got[0].Pinned || !got[1].Pinned || !got[2].Pinned || got[3].Pinned || got[3].ID != chirps[3].ID {
+		t.Errorf("Want the three pinned chirps first, got %+v", got)
+	}
+
+	// Without asking for them, pinned chirps keep their place.
+	got = decode[[]returnChirp](t, a.expect(200, "GET", "/api/chirps?author_id="+alice.ID.String()+"&sort=desc", "", nil))
+	if len(got) != 4 || got[0].ID != chirps[3].ID || got[0].Pinned {
+		t.Errorf("Want chirps in order without pinned flags, got %+v", got)
+	}
+}
+
+// TestDeletePinnedChirp checks that deleting a pinned chirp unpins it, so
+// it doesn't use up a pin.
+func TestDeletePinnedChirp(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	pinned := a.chirp(alice, "pinned")
+	other := a.chirp(alice, "other")
+	a.expect(204, "PUT", "/api/users/me/pinned", alice.Token, map[string]any{"chirp_id": pinned.ID})
+
+	a.expect(204, "DELETE", "/api/chirps/"+pinned.ID.String(), alice.Token, nil)
+	ids, err := a.store.GetPinnedChirpIDs(context.Background(), alice.ID)
+	if err != nil || len(ids) != 0 {
+		t.Errorf("Want nothing pinned, got %v, %v", ids, err)
+	}
+	a.expect(204, "PUT", "/api/users/me/pinned", alice.Token, map[string]any{"chirp_id": other.ID})
+}
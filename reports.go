@@ -0,0 +1,341 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/auth"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+const maxReportDetailsLength = 1000
+
+var reportReasons = []string{"spam", "harassment", "hate", "violence", "sexual", "self_harm", "impersonation", "other"}
+
+var reportStates = []string{"open", "resolved", "dismissed"}
+
+var moderationActions = []string{"dismiss", "delete_chirp", "warn", "suspend", "read_only", "shadow_limit"}
+
+func (cfg *apiConfig) postReport(w http.ResponseWriter, r *http.Request) {
+	type reportParams struct {
+		ChirpID *uuid.UUID `json:"chirp_id"`
+		UserID  *uuid.UUID `json:"user_id"`
+		Reason  string     `json:"reason"`
+		Details string     `json:"details"`
+	}
+
+	tokenString, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	id, err := cfg.validateToken(r, tokenString)
+	if err != nil {
+		respondWithError(w, 401, err.Error())
+		return
+	}
+
+	if !cfg.checkCanWrite(w, r, id) {
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := reportParams{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Invalid request")
+		return
+	}
+
+	if !slices.Contains(reportReasons, params.Reason) {
+		respondWithError(w, 400, "Unknown reason")
+		return
+	}
+	if len(params.Details) > maxReportDetailsLength {
+		respondWithError(w, 400, "Details are too long")
+		return
+	}
+	if (params.ChirpID == nil) == (params.UserID == nil) {
+		respondWithError(w, 400, "Report either a chirp_id or a user_id")
+		return
+	}
+
+	createParams := database.CreateReportParams{
+		ReporterID: uuid.NullUUID{UUID: id, Valid: true},
+		Reason:     params.Reason,
+		Details:    params.Details,
+	}
+
+	if params.ChirpID != nil {
+		c, err := cfg.db.GetChirp(r.Context(), *params.ChirpID)
+		if err != nil {
+			respondWithError(w, 404, "Chirp not found")
+			return
+		}
+		ok, err := cfg.canViewChirp(r.Context(), id, c)
+		if err != nil {
+			respondWithError(w, 500, err.Error())
+			return
+		}
+		if !ok {
+			respondWithError(w, 404, "Chirp not found")
+			return
+		}
+		createParams.TargetUserID = c.UserID
+		createParams.TargetChirpID = uuid.NullUUID{UUID: c.ID, Valid: true}
+	} else {
+		target, err := cfg.db.GetUser(r.Context(), *params.UserID)
+		if err != nil {
+			respondWithError(w, 404, "User not found")
+			return
+		}
+		createParams.TargetUserID = target.ID
+	}
+
+	if createParams.TargetUserID == id {
+		respondWithError(w, 400, "Cannot report yourself")
+		return
+	}
+
+	report, err := cfg.db.CreateReport(r.Context(), createParams)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 201, report)
+}
+
+func (cfg *apiConfig) getReports(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := getPagination(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	var reports []database.Report
+	state := r.URL.Query().Get("state")
+	if state != "" {
+		if !slices.Contains(reportStates, state) {
+			respondWithError(w, 400, "Unknown state")
+			return
+		}
+		reports, err = cfg.db.GetReportsByState(r.Context(), database.GetReportsByStateParams{
+			State:  state,
+			Limit:  limit,
+			Offset: offset,
+		})
+	} else {
+		reports, err = cfg.db.GetReports(r.Context(), database.GetReportsParams{
+			Limit:  limit,
+			Offset: offset,
+		})
+	}
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	if reports == nil {
+		reports = []database.Report{}
+	}
+
+	respondWithJSON(w, 200, reports)
+}
+
+func (cfg *apiConfig) getReport(w http.ResponseWriter, r *http.Request) {
+	type returnReport struct {
+		database.Report
+		Actions []database.ModerationAction `json:"actions"`
+	}
+
+	reportID, err := uuid.Parse(r.PathValue("reportID"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	report, err := cfg.db.GetReport(r.Context(), reportID)
+	if err != nil {
+		respondWithError(w, 404, "Report not found")
+		return
+	}
+
+	actions, err := cfg.db.GetModerationActionsForReport(r.Context(), uuid.NullUUID{UUID: reportID, Valid: true})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	if actions == nil {
+		actions = []database.ModerationAction{}
+	}
+
+	respondWithJSON(w, 200, returnReport{Report: report, Actions: actions})
+}
+
+// postModerationAction closes an open report with one of the moderation
+// actions. The action, the report's new state, the record of who acted and
+// the notification to the reported user are written in one transaction.
+func (cfg *apiConfig) postModerationAction(w http.ResponseWriter, r *http.Request) {
+	type actionParams struct {
+		Action        string `json:"action"`
+		Reason        string `json:"reason"`
+		DurationHours *int32 `json:"duration_hours"`
+	}
+
+	reportID, err := uuid.Parse(r.PathValue("reportID"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := actionParams{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Invalid request")
+		return
+	}
+
+	if !slices.Contains(moderationActions, params.Action) {
+		respondWithError(w, 400, "Unknown action")
+		return
+	}
+	if params.Reason == "" {
+		respondWithError(w, 400, "A reason is required")
+		return
+	}
+	_, restricts := suspensionStates[params.Action]
+	if params.DurationHours != nil && (!restricts || *params.DurationHours <= 0) {
+		respondWithError(w, 400, "duration_hours must be positive and is only used to restrict an account")
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context())
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	report, err := tx.GetReport(r.Context(), reportID)
+	if err != nil {
+		respondWithError(w, 404, "Report not found")
+		return
+	}
+	// A moderator can't close, or act on, a report against themselves,
+	// even just to dismiss it.
+	if report.TargetUserID == actorID(r) {
+		respondWithError(w, 400, "Cannot act on a report against yourself")
+		return
+	}
+
+	state := "resolved"
+	if params.Action == "dismiss" {
+		state = "dismissed"
+	}
+
+	n, err := tx.CloseReport(r.Context(), database.CloseReportParams{
+		State: state,
+		ID:    report.ID,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	if n == 0 {
+		respondWithError(w, 409, "Report is already closed")
+		return
+	}
+
+	var notice string
+	switch params.Action {
+	case "delete_chirp":
+		if !report.TargetChirpID.Valid {
+			respondWithError(w, 409, "The reported chirp no longer exists")
+			return
+		}
+		c, err := tx.GetChirp(r.Context(), report.TargetChirpID.UUID)
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, 409, "The reported chirp no longer exists")
+			return
+		}
+		if err != nil {
+			respondWithError(w, 500, err.Error())
+			return
+		}
+		err = deleteChirpWithEvent(r.Context(), tx, c)
+		if err != nil {
+			respondWithError(w, 500, err.Error())
+			return
+		}
+		notice = "One of your chirps was removed"
+	case "warn":
+		notice = "You have received a warning"
+	case "suspend", "read_only", "shadow_limit":
+		target, err := tx.GetUser(r.Context(), report.TargetUserID)
+		if err != nil {
+			respondWithError(w, 500, err.Error())
+			return
+		}
+		if !outranks(actorRole(r), target.Role) {
+			respondWithError(w, 403, "Cannot restrict a user whose role is not below yours")
+			return
+		}
+		notice, err = restrictUser(r.Context(), tx, report.TargetUserID, params.Action, params.DurationHours)
+		if err != nil {
+			respondWithError(w, 500, err.Error())
+			return
+		}
+	}
+
+	action, err := tx.CreateModerationAction(r.Context(), database.CreateModerationActionParams{
+		ReportID:     uuid.NullUUID{UUID: report.ID, Valid: true},
+		TargetUserID: report.TargetUserID,
+		ModeratorID:  uuid.NullUUID{UUID: actorID(r), Valid: true},
+		Action:       params.Action,
+		Reason:       params.Reason,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	err = appendAudit(r.Context(), tx, r, auditRecord{
+		Action:     "moderation." + params.Action,
+		ActorID:    actorID(r),
+		TargetType: "user",
+		TargetID:   report.TargetUserID.String(),
+		Metadata:   map[string]any{"report_id": report.ID, "reason": params.Reason, "duration_hours": params.DurationHours},
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	// Dismissals and shadow limits aren't reported to the user.
+	if notice != "" {
+		err = tx.CreateNotification(r.Context(), database.CreateNotificationParams{
+			UserID: report.TargetUserID,
+			Kind:   "moderation",
+			Body:   fmt.Sprintf("%s (%s): %s", notice, report.Reason, params.Reason),
+		})
+		if err != nil {
+			respondWithError(w, 500, err.Error())
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 201, action)
+}
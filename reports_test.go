@@ -0,0 +1,162 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func TestPostReport(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	c := a.chirp(bob, "spam spam spam")
+
+	a.expect(401, "POST", "/api/reports", "", map[string]any{"chirp_id": c.ID, "reason": "spam"})
+	a.expect(400, "POST", "/api/reports", alice.Token, map[string]any{"chirp_id": c.ID, "reason": "boring"})
+	a.expect(400, "POST", "/api/reports", alice.Token, map[string]any{"chirp_id": c.ID, "user_id": bob.ID, "reason": "spam"})
+	a.expect(400, "POST", "/api/reports", alice.Token, map[string]any{"user_id": alice.ID, "reason": "spam"})
+	a.expect(404, "POST", "/api/reports", alice.Token, map[string]any{"chirp_id": uuid.New(), "reason": "spam"})
+	a.expect(404, "POST", "/api/reports", alice.Token, map[string]any{"user_id": uuid.New(), "reason": "spam"})
+
+	report := decode[database.Report](t, a.expect(201, "POST", "/api/reports", alice.Token, map[string]any{"chirp_id": c.ID, "reason": "spam"}))
+	if report.TargetUserID != bob.ID || report.TargetChirpID.UUID != c.ID || report.State != "open" {
+		t.Errorf("Want an open report of bob's chirp, got %+v", report)
+	}
+	report = decode[database.Report](t, a.expect(201, "POST", "/api/reports", alice.Token, map[string]any{"user_id": bob.ID, "reason": "harassment"}))
+	if report.TargetUserID != bob.ID || report.TargetChirpID.Valid {
+		t.Errorf("Want a report of bob, got %+v", report)
+	}
+}
+
+func TestModerateReport(t *testing.T) {
+	a := newTestAPI(t)
+	mod := a.signup("mod")
+	a.setRole(mod, roleModerator)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	c := a.chirp(bob, "spam spam spam")
+
+	report := decode[database.Report](t, a.expect(201, "POST", "/api/reports", alice.Token, map[string]any{"chirp_id": c.ID, "reason": "spam"}))
+	dismissed := decode[database.Report](t, a.expect(201, "POST", "/api/reports", alice.Token, map[string]any{"user_id": bob.ID, "reason": "other"}))
+
+	a.expect(403, "GET", "/admin/reports", alice.Token, nil)
+	reports := decode[[]database.Report](t, a.expect(200, "GET", "/admin/reports?state=open", mod.Token, nil))
+	if len(reports) != 2 {
+		t.Errorf("Want 2 open reports, got %+v", reports)
+	}
+	a.expect(400, "GET", "/admin/reports?state=unknown", mod.Token, nil)
+
+	path := "/admin/reports/" + report.ID.String()
+	a.expect(404, "GET", "/admin/reports/"+uuid.NewString(), mod.Token, nil)
+	a.expect(400, "POST", path+"/actions", mod.Token, map[string]any{"action": "banish", "reason": "spam"})
+	a.expect(400, "POST", path+"/actions", mod.Token, map[string]any{"action": "delete_chirp"})
+	a.expect(400, "POST", path+"/actions", mod.Token, map[string]any{"action": "warn", "reason": "spam", "duration_hours": 1})
+	a.expect(404, "POST", "/admin/reports/"+uuid.NewString()+"/actions", mod.Token, map[string]any{"action": "warn", "reason": "spam"})
+
+	a.expect(201, "POST", path+"/actions", mod.Token, map[string]any{"action": "delete_chirp", "reason": "spam"})
+	a.expect(404, "GET", "/api/chirps/"+c.ID.String(), "", nil)
+	a.expect(409, "POST", path+"/actions", mod.Token, map[string]any{"action": "warn", "reason": "spam"})
+	a.expect(201, "POST", "/admin/reports/"+dismissed.ID.String()+"/actions", mod.Token, map[string]any{"action": "dismiss", "reason": "not a problem"})
+
+	type returnReport struct {
+		database.Report
+		Actions []database.ModerationAction `json:"actions"`
+	}
+	res := decode[returnReport](t, a.expect(200, "GET", path, mod.Token, nil))
+	if res.State != "resolved" || len(res.Actions) != 1 || res.Actions[0].Action != "delete_chirp" {
+		t.Errorf("Want a resolved report with its action, got %+v", res)
+	}
+	reports = decode[[]database.Report](t, a.expect(200, "GET", "/admin/reports?state=dismissed", mod.Token, nil))
+	if len(reports) != 1 || reports[0].ID != dismissed.ID {
+		t.Errorf("Want the dismissed report, got %+v", reports)
+	}
+	a.expectAudited("moderation.delete_chirp")
+}
+
+// TestModerateReportAgainstAdmin checks that reporting an admin doesn't
+// let a moderator restrict them.
+func TestModerateReportAgainstAdmin(t *testing.T) {
+	a := newTestAPI(t)
+	mod := a.signup("mod")
+	a.setRole(mod, roleModerator)
+	admin := a.signup("admin")
+	a.setRole(admin, roleAdmin)
+
+	report := decode[database.Report](t, a.expect(201, "POST", "/api/reports", mod.Token, map[string]any{"user_id": admin.ID, "reason": "harassment"}))
+	path := "/admin/reports/" + report.ID.String() + "/actions"
+	for _, action := range []string{"suspend", "read_only", "shadow_limit"} {
+		a.expect(403, "POST", path, mod.Token, map[string]any{"action": action, "reason": "payback"})
+	}
+	a.expect(200, "POST", "/api/refresh", admin.RefreshToken, nil)
+
+	// The report is still open, and can be handled without restricting.
+	a.expect(201, "POST", path, mod.Token, map[string]any{"action": "warn", "reason": "be nice"})
+}
+
+func TestModerateReportAgainstSelf(t *testing.T) {
+	a := newTestAPI(t)
+	mod := a.signup("mod")
+	a.setRole(mod, roleModerator)
+	other := a.signup("other")
+	a.setRole(other, roleModerator)
+	alice := a.signup("alice")
+
+	report := decode[database.Report](t, a.expect(201, "POST", "/api/reports", alice.Token, map[string]any{"user_id": mod.ID, "reason": "harassment"}))
+	path := "/admin/reports/" + report.ID.String() + "/actions"
+	for _, action := range moderationActions {
+		a.expect(400, "POST", path, mod.Token, map[string]any{"action": action, "reason": "not me"})
+	}
+
+	// The report is still open for another moderator.
+	a.expect(201, "POST", path, other.Token, map[string]any{"action": "dismiss", "reason": "no evidence"})
+}
+
+// TestModerationOutcomes checks that each action is recorded against the
+// moderator who took it, and that the reported user hears about the ones
+// that affect them.
+func TestModerationOutcomes(t *testing.T) {
+	a := newTestAPI(t)
+	mod := a.signup("mod")
+	a.setRole(mod, roleModerator)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+
+	long := strings.Repeat("a", maxReportDetailsLength+1)
+	a.expect(400, "POST", "/api/reports", alice.Token, map[string]any{"user_id": bob.ID, "reason": "spam", "details": long})
+
+	act := func(action string, body map[string]any) database.ModerationAction {
+		t.Helper()
+		report := decode[database.Report](t, a.expect(201, "POST", "/api/reports", alice.Token, map[string]any{"user_id": bob.ID, "reason": "harassment", "details": "rude replies"}))
+		body["action"] = action
+		return decode[database.ModerationAction](t, a.expect(201, "POST", "/admin/reports/"+report.ID.String()+"/actions", mod.Token, body))
+	}
+
+	for _, action := range []string{"dismiss", "shadow_limit"} {
+		act(action, map[string]any{"reason": "quiet"})
+	}
+	notifications := decode[[]database.Notification](t, a.expect(200, "GET", "/api/notifications", bob.Token, nil))
+	if len(notifications) != 0 {
+		t.Errorf("Want no notifications for dismissals or shadow limits, got %+v", notifications)
+	}
+
+	action := act("warn", map[string]any{"reason": "be nice"})
+	if action.ModeratorID.UUID != mod.ID || action.TargetUserID != bob.ID || action.Reason != "be nice" {
+		t.Errorf("Want the warning recorded against mod, got %+v", action)
+	}
+	act("suspend", map[string]any{"reason": "kept at it", "duration_hours": 24})
+	a.expect(403, "POST", "/api/login", "", map[string]string{"email": bob.Email, "password": "hunter2"})
+
+	notifications = decode[[]database.Notification](t, a.expect(200, "GET", "/api/notifications", bob.Token, nil))
+	if len(notifications) != 2 {
+		t.Fatalf("Want notifications for the warning and the suspension, got %+v", notifications)
+	}
+	for _, n := range notifications {
+		if n.Kind != "moderation" || !strings.Contains(n.Body, "harassment") {
+			t.Errorf("Want a moderation notification giving the reason, got %+v", n)
+		}
+	}
+	a.expectAudited("moderation.suspend")
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/auth"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+const (
+	roleUser      = "user"
+	roleModerator = "moderator"
+	roleAdmin     = "admin"
+)
+
+// roles is ordered from least to most privileged; each role can do
+// everything the ones before it can.
+var roles = []string{roleUser, roleModerator, roleAdmin}
+
+type contextKey string
+
+const (
+	actorIDKey   contextKey = "actorID"
+	actorRoleKey contextKey = "actorRole"
+)
+
+func hasRole(have, want string) bool {
+	return slices.Contains(roles, have) && slices.Index(roles, have) >= slices.Index(roles, want)
+}
+
+// outranks reports whether role is more privileged than target's, which it
+// has to be to act against that user: moderators can't restrict each other
+// or an admin.
+func outranks(role, target string) bool {
+	return slices.Contains(roles, role) && slices.Index(roles, role) > slices.Index(roles, target)
+}
+
+// requireRole only lets the request through if the caller's role is at
+// least role. The role is read from the database on every request rather
+// than baked into the JWT, so a demotion takes effect straight away. A
+// suspended caller is turned away, and a read-only one can only make
+// requests that don't change anything.
+func (cfg *apiConfig) requireRole(role string, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenString, err := auth.GetBearerToken(r.Header)
+		if err != nil {
+			respondWithError(w, 401, err.Error())
+			return
+		}
+
+		id, err := cfg.validateToken(r, tokenString)
+		if err != nil {
+			respondWithError(w, 401, err.Error())
+			return
+		}
+
+		have, err := cfg.db.GetUserRole(r.Context(), id)
+		if err != nil {
+			respondWithError(w, 401, "Unknown user")
+			return
+		}
+		if !hasRole(have, role) {
+			respondWithError(w, 403, "Insufficient role")
+			return
+		}
+
+		suspension, err := cfg.db.GetActiveSuspension(r.Context(), id)
+		if err != nil {
+			respondWithError(w, 500, err.Error())
+			return
+		}
+		switch {
+		case suspension.String == "suspended":
+			respondWithError(w, 403, "Account suspended")
+			return
+		case suspension.String == "read_only" && !safeMethod(r.Method):
+			respondWithError(w, 403, "Account is read-only")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), actorIDKey, id)
+		ctx = context.WithValue(ctx, actorRoleKey, have)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// safeMethod reports whether requests with method only read.
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// actorID returns the ID of the user that requireRole authenticated.
+func actorID(r *http.Request) uuid.UUID {
+	id, _ := r.Context().Value(actorIDKey).(uuid.UUID)
+	return id
+}
+
+// actorRole returns the role requireRole found for the user.
+func actorRole(r *http.Request) string {
+	role, _ := r.Context().Value(actorRoleKey).(string)
+	return role
+}
+
+func (cfg *apiConfig) putUserRole(w http.ResponseWriter, r *http.Request) {
+	type roleParam struct {
+		Role string `json:"role"`
+	}
+
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := roleParam{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Invalid request")
+		return
+	}
+	if !slices.Contains(roles, params.Role) {
+		respondWithError(w, 400, "Unknown role")
+		return
+	}
+
+	// Admins can't demote themselves, so there is always at least one
+	// left to undo a mistake.
+	if userID == actorID(r) && params.Role != roleAdmin {
+		respondWithError(w, 400, "Cannot change your own role")
+		return
+	}
+
+	n, err := cfg.db.UpdateUserRole(r.Context(), database.UpdateUserRoleParams{
+		Role: params.Role,
+		ID:   userID,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	if n == 0 {
+		respondWithError(w, 404, "User not found")
+		return
+	}
+
+	cfg.audit(r, auditRecord{
+		Action:     "admin.role_changed",
+		ActorID:    actorID(r),
+		TargetType: "user",
+		TargetID:   userID.String(),
+		Metadata:   params,
+	})
+	respondWithJSON(w, 200, params)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestHasRole(t *testing.T) {
+	cases := []struct {
+		have, want string
+		ok         bool
+	}{
+		{roleAdmin, roleModerator, true},
+		{roleModerator, roleModerator, true},
+		{roleUser, roleModerator, false},
+		{"owner", roleUser, false},
+	}
+	for _, c := range cases {
+		if got := hasRole(c.have, c.want); got != c.ok {
+			t.Errorf("hasRole(%q, %q): want %v, got %v", c.have, c.want, c.ok, got)
+		}
+	}
+}
+
+func TestPutUserRole(t *testing.T) {
+	a := newTestAPI(t)
+	admin := a.signup("admin")
+	a.setRole(admin, roleAdmin)
+	bob := a.signup("bob")
+
+	path := "/admin/users/" + bob.ID.String() + "/role"
+	a.expect(401, "PUT", path, "", map[string]string{"role": roleModerator})
+	a.expect(403, "PUT", path, bob.Token, map[string]string{"role": roleAdmin})
+	a.expect(400, "PUT", path, admin.Token, map[string]string{"role": "owner"})
+	a.expect(400, "PUT", "/admin/users/"+admin.ID.String()+"/role", admin.Token, map[string]string{"role": roleUser})
+	a.expect(404, "PUT", "/admin/users/"+uuid.NewString()+"/role", admin.Token, map[string]string{"role": roleModerator})
+
+	a.expect(403, "GET", "/admin/reports", bob.Token, nil)
+	a.expect(200, "PUT", path, admin.Token, map[string]string{"role": roleModerator})
+	a.expect(200, "GET", "/admin/reports", bob.Token, nil)
+	a.expect(403, "GET", "/admin/audit", bob.Token, nil)
+	a.expectAudited("admin.role_changed")
+}
+
+func TestRequireRoleRestricted(t *testing.T) {
+	a := newTestAPI(t)
+	admin := a.signup("admin")
+	a.setRole(admin, roleAdmin)
+	mod := a.signup("mod")
+	a.setRole(mod, roleModerator)
+	bob := a.signup("bob")
+
+	modPath := "/admin/users/" + mod.ID.String() + "/suspension"
+	bobPath := "/admin/users/" + bob.ID.String() + "/suspension"
+
+	// A read-only moderator can still look, but not act.
+	a.expect(201, "PUT", modPath, admin.Token, map[string]any{"action": "read_only", "reason": "cool off"})
+	a.expect(200, "GET", "/admin/reports", mod.Token, nil)
+	a.expect(403, "PUT", bobPath, mod.Token, map[string]any{"action": "suspend", "reason": "spam"})
+
+	// A suspended one can do neither with a token issued beforehand.
+	a.expect(201, "PUT", modPath, admin.Token, map[string]any{"action": "suspend", "reason": "abuse"})
+	a.expect(403, "GET", "/admin/reports", mod.Token, nil)
+
+	a.expect(201, "DELETE", modPath, admin.Token, map[string]any{"reason": "appeal"})
+	a.expect(201, "PUT", bobPath, mod.Token, map[string]any{"action": "suspend", "reason": "spam"})
+}
+
+func TestSetRoleByEmail(t *testing.T) {
+	a := newTestAPI(t)
+	bob := a.signup("bob")
+
+	ctx := context.Background()
+	err := setRoleByEmail(ctx, a.store, "nobody@example.com", roleModerator)
+	if err == nil {
+		t.Errorf("Want an error for an unknown email")
+	}
+	err = setRoleByEmail(ctx, a.store, bob.Email, roleModerator)
+	if err != nil {
+		t.Fatalf("setRoleByEmail returned error: %v", err)
+	}
+
+	a.expect(200, "GET", "/admin/reports", bob.Token, nil)
+	a.expectAudited("admin.role_changed")
+}
+
+// TestAdminRoutesNeedRole checks that every /admin route turns away callers
+// below the role it needs before the handler runs.
+func TestAdminRoutesNeedRole(t *testing.T) {
+	a := newTestAPI(t)
+	mod := a.signup("mod")
+	a.setRole(mod, roleModerator)
+	bob := a.signup("bob")
+
+	id := uuid.NewString()
+	routes := []struct {
+		method, path, role string
+	}{
+		{"GET", "/admin/metrics", roleAdmin},
+		{"POST", "/admin/reset", roleAdmin},
+		{"GET", "/admin/webhooks/events", roleAdmin},
+		{"POST", "/admin/webhooks/events/" + id + "/replay", roleAdmin},
+		{"POST", "/admin/webhooks/endpoints", roleAdmin},
+		{"GET", "/admin/webhooks/endpoints", roleAdmin},
+		{"DELETE", "/admin/webhooks/endpoints/" + id, roleAdmin},
+		{"GET", "/admin/webhooks/endpoints/" + id + "/deliveries", roleAdmin},
+		{"GET", "/admin/users/" + id + "/entitlements", roleAdmin},
+		{"PUT", "/admin/users/" + id + "/entitlements", roleAdmin},
+		{"DELETE", "/admin/users/" + id + "/entitlements", roleAdmin},
+		{"PUT", "/admin/users/" + id + "/role", roleAdmin},
+		{"GET", "/admin/audit", roleAdmin},
+		{"GET", "/admin/audit/verify", roleAdmin},
+		{"GET", "/admin/reports", roleModerator},
+		{"GET", "/admin/reports/" + id, roleModerator},
+		{"POST", "/admin/reports/" + id + "/actions", roleModerator},
+		{"PUT", "/admin/users/" + id + "/suspension", roleModerator},
+		{"DELETE", "/admin/users/" + id + "/suspension", roleModerator},
+	}
+	for _, r := range routes {
+		a.expect(401, r.method, r.path, "", nil)
+		a.expect(403, r.method, r.path, bob.Token, nil)
+		if r.role == roleAdmin {
+			a.expect(403, r.method, r.path, mod.Token, nil)
+		}
+	}
+}
+
+// TestDemotionImmediate checks that a demoted user loses access with the
+// token they already hold.
+func TestDemotionImmediate(t *testing.T) {
+	a := newTestAPI(t)
+	admin := a.signup("admin")
+	a.setRole(admin, roleAdmin)
+	mod := a.signup("mod")
+	a.setRole(mod, roleModerator)
+
+	a.expect(200, "GET", "/admin/reports", mod.Token, nil)
+	a.expect(200, "PUT", "/admin/users/"+mod.ID.String()+"/role", admin.Token, map[string]string{"role": roleUser})
+	a.expect(403, "GET", "/admin/reports", mod.Token, nil)
+}
@@ -0,0 +1,73 @@
+package main
+
+import "net/http"
+
+// routes registers every endpoint and wraps them in the middleware every
+// request goes through.
+func (cfg *apiConfig) routes(fileServerRoot string) http.Handler {
+	serveMux := http.NewServeMux()
+	serveMux.HandleFunc("GET /api/livez", livenessEndpoint)
+	serveMux.HandleFunc("GET /api/readyz", cfg.readinessEndpoint)
+	// Kept for probes that were set up before livez and readyz.
+	serveMux.HandleFunc("GET /api/healthz", livenessEndpoint)
+	serveMux.Handle("/app/", cfg.middlewareMetricsInc(http.StripPrefix("/app/", http.FileServer(http.Dir(fileServerRoot)))))
+	serveMux.Handle("GET /admin/metrics", cfg.requireRole(roleAdmin, cfg.returnMetrics))
+	serveMux.Handle("POST /admin/reset", cfg.requireRole(roleAdmin, cfg.resetMetrics))
+	serveMux.HandleFunc("POST /api/users", cfg.postUser)
+	serveMux.HandleFunc("POST /api/chirps", cfg.postChirp)
+	serveMux.HandleFunc("GET /api/chirps", cfg.getChirps)
+	serveMux.HandleFunc("GET /api/chirps/{chirpID}", cfg.getChirp)
+	serveMux.HandleFunc("POST /api/login", cfg.userLogin)
+	serveMux.HandleFunc("POST /api/refresh", cfg.refreshJWT)
+	serveMux.HandleFunc("POST /api/revoke", cfg.revokeToken)
+	serveMux.HandleFunc("PUT /api/users", cfg.putUser)
+	serveMux.HandleFunc("DELETE /api/chirps/{chirpID}", cfg.deleteChirp)
+	serveMux.HandleFunc("POST /api/polka/webhooks", cfg.userRed)
+	serveMux.Handle("GET /admin/webhooks/events", cfg.requireRole(roleAdmin, cfg.getWebhookEvents))
+	serveMux.Handle("POST /admin/webhooks/events/{eventID}/replay", cfg.requireRole(roleAdmin, cfg.replayWebhookEvent))
+	serveMux.Handle("POST /admin/webhooks/endpoints", cfg.requireRole(roleAdmin, cfg.postAdminWebhookEndpoint))
+	serveMux.Handle("GET /admin/webhooks/endpoints", cfg.requireRole(roleAdmin, cfg.getAdminWebhookEndpoints))
+	serveMux.Handle("DELETE /admin/webhooks/endpoints/{endpointID}", cfg.requireRole(roleAdmin, cfg.deleteAdminWebhookEndpoint))
+	serveMux.Handle("GET /admin/webhooks/endpoints/{endpointID}/deliveries", cfg.requireRole(roleAdmin, cfg.getAdminWebhookDeliveries))
+	serveMux.Handle("GET /admin/users/{userID}/entitlements", cfg.requireRole(roleAdmin, cfg.getUserEntitlements))
+	serveMux.Handle("PUT /admin/users/{userID}/entitlements", cfg.requireRole(roleAdmin, cfg.putUserEntitlements))
+	serveMux.Handle("DELETE /admin/users/{userID}/entitlements", cfg.requireRole(roleAdmin, cfg.deleteUserEntitlements))
+	serveMux.Handle("PUT /admin/users/{userID}/role", cfg.requireRole(roleAdmin, cfg.putUserRole))
+	serveMux.Handle("GET /admin/audit", cfg.requireRole(roleAdmin, cfg.getAuditEvents))
+	serveMux.Handle("GET /admin/audit/verify", cfg.requireRole(roleAdmin, cfg.verifyAuditEvents))
+	serveMux.Handle("GET /admin/reports", cfg.requireRole(roleModerator, cfg.getReports))
+	serveMux.Handle("GET /admin/reports/{reportID}", cfg.requireRole(roleModerator, cfg.getReport))
+	serveMux.Handle("POST /admin/reports/{reportID}/actions", cfg.requireRole(roleModerator, cfg.postModerationAction))
+	serveMux.Handle("PUT /admin/users/{userID}/suspension", cfg.requireRole(roleModerator, cfg.putUserSuspension))
+	serveMux.Handle("DELETE /admin/users/{userID}/suspension", cfg.requireRole(roleModerator, cfg.deleteUserSuspension))
+	serveMux.HandleFunc("POST /api/chirps/{chirpID}/bookmark", cfg.postBookmark)
+	serveMux.HandleFunc("DELETE /api/chirps/{chirpID}/bookmark", cfg.deleteBookmark)
+	serveMux.HandleFunc("GET /api/bookmarks", cfg.getBookmarks)
+	serveMux.HandleFunc("PUT /api/users/me/pinned", cfg.putPinnedChirp)
+	serveMux.HandleFunc("DELETE /api/users/me/pinned/{chirpID}", cfg.deletePinnedChirp)
+	serveMux.HandleFunc("POST /api/users/{userID}/block", cfg.postBlock)
+	serveMux.HandleFunc("DELETE /api/users/{userID}/block", cfg.deleteBlock)
+	serveMux.HandleFunc("GET /api/users/me/blocks", cfg.getBlocks)
+	serveMux.HandleFunc("POST /api/users/{userID}/mute", cfg.postMute)
+	serveMux.HandleFunc("DELETE /api/users/{userID}/mute", cfg.deleteMute)
+	serveMux.HandleFunc("GET /api/users/me/mutes", cfg.getMutes)
+	serveMux.HandleFunc("PUT /api/users/me/protected", cfg.putProtected)
+	serveMux.HandleFunc("POST /api/users/{userID}/follow", cfg.postFollow)
+	serveMux.HandleFunc("DELETE /api/users/{userID}/follow", cfg.deleteFollow)
+	serveMux.HandleFunc("GET /api/follow-requests", cfg.getFollowRequests)
+	serveMux.HandleFunc("POST /api/follow-requests/{userID}/accept", cfg.acceptFollowRequest)
+	serveMux.HandleFunc("POST /api/follow-requests/{userID}/reject", cfg.rejectFollowRequest)
+	serveMux.HandleFunc("DELETE /api/users/me", cfg.deleteUser)
+	serveMux.HandleFunc("POST /api/users/me/export", cfg.postDataExport)
+	serveMux.HandleFunc("GET /api/users/me/export/{exportID}", cfg.getDataExport)
+	serveMux.HandleFunc("GET /api/exports/{exportID}/download", cfg.downloadDataExport)
+	serveMux.HandleFunc("POST /api/webhooks", cfg.postWebhookEndpoint)
+	serveMux.HandleFunc("GET /api/webhooks", cfg.getWebhookEndpoints)
+	serveMux.HandleFunc("DELETE /api/webhooks/{endpointID}", cfg.deleteWebhookEndpoint)
+	serveMux.HandleFunc("GET /api/webhooks/{endpointID}/deliveries", cfg.getWebhookDeliveries)
+	serveMux.HandleFunc("POST /api/reports", cfg.postReport)
+	serveMux.HandleFunc("GET /api/notifications", cfg.getNotifications)
+	serveMux.HandleFunc("POST /api/notifications/read", cfg.readNotifications)
+
+	return cfg.middlewareTracing(cfg.middlewareLogging(cfg.middlewareRequestMetrics(middlewareRoute(serveMux))))
+}
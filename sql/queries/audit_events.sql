@@ -0,0 +1,70 @@
+-- name: LockAuditChain :exec
+SELECT pg_advisory_xact_lock(hashtext('audit_events'));
+
+-- name: GetLastAuditHash :one
+SELECT head_hash
+FROM audit_chain;
+
+-- name: SetAuditChainHead :exec
+UPDATE audit_chain
+SET head_hash = $1;
+
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (created_at, action, actor_id, target_type, target_id, ip, user_agent, request_id, metadata, prev_hash, hash)
+VALUES (
+    $1
+    ,$2
+    ,$3
+    ,$4
+    ,$5
+    ,$6
+    ,$7
+    ,$8
+    ,$9
+    ,$10
+    ,$11
+)
+RETURNING *;
+
+-- name: GetAuditEvents :many
+SELECT *
+FROM audit_events
+WHERE (sqlc.narg(actor_id)::uuid IS NULL OR actor_id = sqlc.narg(actor_id))
+    AND (sqlc.narg(action)::text IS NULL OR action = sqlc.narg(action))
+    AND (sqlc.narg(target_id)::text IS NULL OR target_id = sqlc.narg(target_id))
+    AND (sqlc.narg(since)::timestamp IS NULL OR created_at >= sqlc.narg(since))
+    AND (sqlc.narg(until)::timestamp IS NULL OR created_at < sqlc.narg(until))
+ORDER BY id DESC
+LIMIT sqlc.arg(lim)
+OFFSET sqlc.arg(off);
+
+-- name: GetAuditEventsAfter :many
+SELECT *
+FROM audit_events
+WHERE id > $1
+ORDER BY id
+LIMIT $2;
+
+-- name: AllowAuditPrune :exec
+SELECT set_config('chirpy.audit_prune', 'on', true);
+
+-- name: DeleteAuditEventsBefore :execrows
+DELETE FROM audit_events
+WHERE id < (
+    SELECT COALESCE(MIN(id), (SELECT MAX(id) + 1 FROM audit_events))
+    FROM audit_events
+    WHERE created_at >= $1
+);
+
+-- name: GetFirstAuditPrevHash :one
+SELECT prev_hash
+FROM audit_events
+ORDER BY id
+LIMIT 1;
+
+-- name: GetLatestAuditPrune :one
+SELECT *
+FROM audit_events
+WHERE action = 'audit.pruned'
+ORDER BY id DESC
+LIMIT 1;
\ No newline at end of file
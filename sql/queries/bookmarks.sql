@@ -0,0 +1,59 @@
+-- name: CreateBookmark :exec
+INSERT INTO bookmarks (user_id, chirp_id, created_at)
+VALUES (
+    $1
+    ,$2
+    ,NOW()
+)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteBookmark :exec
+DELETE FROM bookmarks
+WHERE user_id = $1
+    AND chirp_id = $2;
+
+-- name: GetBookmarkedChirpIDs :many
+SELECT chirp_id
+FROM bookmarks
+WHERE user_id = $1;
+
+-- name: GetBookmarkedChirps :many
+SELECT chirps.*
+FROM chirps
+JOIN bookmarks ON bookmarks.chirp_id = chirps.id
+WHERE bookmarks.user_id = $1
+    AND NOT EXISTS (
+        SELECT 1
+        FROM blocks
+        WHERE (blocker_id = $1 AND blocked_id = chirps.user_id)
+            OR (blocker_id = chirps.user_id AND blocked_id = $1)
+    )
+    AND (
+        chirps.user_id = $1
+        OR NOT EXISTS (
+            SELECT 1
+            FROM users
+            WHERE users.id = chirps.user_id
+                AND users.protected
+        )
+        OR EXISTS (
+            SELECT 1
+            FROM follows
+            WHERE follower_id = $1
+                AND followee_id = chirps.user_id
+                AND status = 'accepted'
+        )
+    )
+    AND (
+        chirps.user_id = $1
+        OR NOT EXISTS (
+            SELECT 1
+            FROM users
+            WHERE users.id = chirps.user_id
+                AND users.suspension IN ('suspended', 'shadow_limited')
+                AND (users.suspended_until IS NULL OR users.suspended_until > NOW())
+        )
+    )
+ORDER BY bookmarks.created_at DESC
+LIMIT $2
+OFFSET $3;
\ No newline at end of file
@@ -12,6 +12,43 @@ RETURNING *;
 -- name: GetAllChirps :many
 SELECT * 
 FROM chirps 
+WHERE NOT EXISTS (
+        SELECT 1
+        FROM blocks
+        WHERE (blocker_id = sqlc.arg(viewer_id) AND blocked_id = chirps.user_id)
+            OR (blocker_id = chirps.user_id AND blocked_id = sqlc.arg(viewer_id))
+    )
+    AND NOT EXISTS (
+        SELECT 1
+        FROM mutes
+        WHERE muter_id = sqlc.arg(viewer_id) AND muted_id = chirps.user_id
+    )
+    AND (
+        chirps.user_id = sqlc.arg(viewer_id)
+        OR NOT EXISTS (
+            SELECT 1
+            FROM users
+            WHERE users.id = chirps.user_id
+                AND users.protected
+        )
+        OR EXISTS (
+            SELECT 1
+            FROM follows
+            WHERE follower_id = sqlc.arg(viewer_id)
+                AND followee_id = chirps.user_id
+                AND status = 'accepted'
+        )
+    )
+    AND (
+        chirps.user_id = sqlc.arg(viewer_id)
+        OR NOT EXISTS (
+            SELECT 1
+            FROM users
+            WHERE users.id = chirps.user_id
+                AND users.suspension IN ('suspended', 'shadow_limited')
+                AND (users.suspended_until IS NULL OR users.suspended_until > NOW())
+        )
+    )
 ORDER BY created_at;
 
 -- name: GetChirp :one
@@ -26,5 +63,43 @@ WHERE id = $1;
 -- name: GetChirpsForUser :many
 SELECT * 
 FROM chirps 
+WHERE user_id = sqlc.arg(user_id)
+    AND NOT EXISTS (
+        SELECT 1
+        FROM blocks
+        WHERE (blocker_id = sqlc.arg(viewer_id) AND blocked_id = chirps.user_id)
+            OR (blocker_id = chirps.user_id AND blocked_id = sqlc.arg(viewer_id))
+    )
+    AND (
+        chirps.user_id = sqlc.arg(viewer_id)
+        OR NOT EXISTS (
+            SELECT 1
+            FROM users
+            WHERE users.id = chirps.user_id
+                AND users.protected
+        )
+        OR EXISTS (
+            SELECT 1
+            FROM follows
+            WHERE follower_id = sqlc.arg(viewer_id)
+                AND followee_id = chirps.user_id
+                AND status = 'accepted'
+        )
+    )
+    AND (
+        chirps.user_id = sqlc.arg(viewer_id)
+        OR NOT EXISTS (
+            SELECT 1
+            FROM users
+            WHERE users.id = chirps.user_id
+                AND users.suspension IN ('suspended', 'shadow_limited')
+                AND (users.suspended_until IS NULL OR users.suspended_until > NOW())
+        )
+    )
+ORDER BY created_at;
+
+-- name: CountChirpsInLastHour :one
+SELECT COUNT(*)
+FROM chirps
 WHERE user_id = $1
-ORDER BY created_at;
\ No newline at end of file
+    AND created_at > NOW() - interval '1 hour';
\ No newline at end of file
@@ -0,0 +1,86 @@
+-- name: CreateDataExport :one
+INSERT INTO data_exports (id, created_at, updated_at, user_id, status)
+VALUES (
+    gen_random_uuid()
+    ,NOW()
+    ,NOW()
+    ,$1
+    ,'pending'
+)
+ON CONFLICT (user_id) WHERE status = 'pending' DO NOTHING
+RETURNING *;
+
+-- name: GetDataExport :one
+SELECT *
+FROM data_exports
+WHERE id = $1;
+
+-- name: CompleteDataExport :exec
+UPDATE data_exports
+SET (updated_at, status, file_path, expires_at) = (NOW(), 'ready', $1, NOW() + interval '7 days')
+WHERE id = $2;
+
+-- name: FailDataExport :exec
+UPDATE data_exports
+SET (updated_at, status) = (NOW(), 'failed')
+WHERE id = $1;
+
+-- name: ClaimAbandonedDataExports :many
+UPDATE data_exports
+SET updated_at = NOW()
+WHERE id IN (
+    SELECT id
+    FROM data_exports
+    WHERE status = 'pending'
+        AND updated_at <= NOW() - interval '15 minutes'
+    ORDER BY created_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: GetDataExportFilesForUser :many
+SELECT file_path
+FROM data_exports
+WHERE user_id = $1
+    AND file_path IS NOT NULL;
+
+-- name: DeleteExpiredDataExports :many
+DELETE FROM data_exports
+WHERE expires_at <= NOW()
+RETURNING file_path;
+
+-- name: GetChirpsForExport :many
+SELECT *
+FROM chirps
+WHERE user_id = sqlc.arg(user_id)
+    AND (
+        created_at > sqlc.arg(after_created_at)
+        OR (created_at = sqlc.arg(after_created_at) AND id > sqlc.arg(after_id))
+    )
+ORDER BY created_at, id
+LIMIT sqlc.arg(batch_size);
+
+-- name: GetBookmarksForExport :many
+SELECT *
+FROM bookmarks
+WHERE user_id = sqlc.arg(user_id)
+    AND (
+        created_at > sqlc.arg(after_created_at)
+        OR (created_at = sqlc.arg(after_created_at) AND chirp_id > sqlc.arg(after_chirp_id))
+    )
+ORDER BY created_at, chirp_id
+LIMIT sqlc.arg(batch_size);
+
+-- name: GetFollowsForExport :many
+SELECT *
+FROM follows
+WHERE follower_id = $1
+    OR followee_id = $1
+ORDER BY created_at;
+
+-- name: GetRefreshTokensForExport :many
+SELECT created_at, updated_at, expires_at, revoked_at
+FROM refresh_tokens
+WHERE user_id = $1
+ORDER BY created_at;
\ No newline at end of file
@@ -0,0 +1,19 @@
+-- name: UpsertEntitlementOverride :exec
+INSERT INTO entitlement_overrides (user_id, created_at, updated_at, overrides)
+VALUES (
+    $1
+    ,NOW()
+    ,NOW()
+    ,$2
+)
+ON CONFLICT (user_id) DO UPDATE
+SET (updated_at, overrides) = (NOW(), EXCLUDED.overrides);
+
+-- name: GetEntitlementOverride :one
+SELECT overrides
+FROM entitlement_overrides
+WHERE user_id = $1;
+
+-- name: DeleteEntitlementOverride :exec
+DELETE FROM entitlement_overrides
+WHERE user_id = $1;
\ No newline at end of file
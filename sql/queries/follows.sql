@@ -0,0 +1,78 @@
+-- name: CreateFollow :execrows
+INSERT INTO follows (follower_id, followee_id, status, created_at, updated_at)
+VALUES (
+    $1
+    ,$2
+    ,$3
+    ,NOW()
+    ,NOW()
+)
+ON CONFLICT DO NOTHING;
+
+-- name: GetFollow :one
+SELECT *
+FROM follows
+WHERE follower_id = $1
+    AND followee_id = $2;
+
+-- name: DeleteFollow :exec
+DELETE FROM follows
+WHERE follower_id = $1
+    AND followee_id = $2;
+
+-- name: DeleteFollowsBetween :exec
+DELETE FROM follows
+WHERE (follower_id = sqlc.arg(user_id) AND followee_id = sqlc.arg(other_user_id))
+    OR (follower_id = sqlc.arg(other_user_id) AND followee_id = sqlc.arg(user_id));
+
+-- name: GetFollowRequests :many
+SELECT *
+FROM follows
+WHERE followee_id = $1
+    AND status = 'pending'
+ORDER BY created_at
+LIMIT $2
+OFFSET $3;
+
+-- name: AcceptFollowRequest :execrows
+UPDATE follows
+SET (updated_at, status) = (NOW(), 'accepted')
+WHERE follower_id = $1
+    AND followee_id = $2
+    AND status = 'pending';
+
+-- name: RejectFollowRequest :execrows
+DELETE FROM follows
+WHERE follower_id = $1
+    AND followee_id = $2
+    AND status = 'pending';
+
+-- name: AcceptAllFollowRequests :many
+UPDATE follows
+SET (updated_at, status) = (NOW(), 'accepted')
+WHERE followee_id = $1
+    AND status = 'pending'
+RETURNING follower_id;
+
+-- name: CanViewUser :one
+SELECT (
+    users.id = sqlc.arg(viewer_id)
+    OR (
+        (
+            NOT users.protected
+            OR EXISTS (
+                SELECT 1
+                FROM follows
+                WHERE follower_id = sqlc.arg(viewer_id)
+                    AND followee_id = users.id
+                    AND status = 'accepted'
+            )
+        )
+        AND NOT (
+            COALESCE(users.suspension IN ('suspended', 'shadow_limited'), false)
+            AND (users.suspended_until IS NULL OR users.suspended_until > NOW())
+        )
+    )
+) AS can_view
+FROM users
+WHERE users.id = sqlc.arg(user_id);
\ No newline at end of file
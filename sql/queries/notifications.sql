@@ -0,0 +1,23 @@
+-- name: CreateNotification :exec
+INSERT INTO notifications (id, created_at, user_id, kind, body)
+VALUES (
+    gen_random_uuid()
+    ,NOW()
+    ,$1
+    ,$2
+    ,$3
+);
+
+-- name: GetNotificationsForUser :many
+SELECT *
+FROM notifications
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: MarkNotificationsRead :exec
+UPDATE notifications
+SET read_at = NOW()
+WHERE user_id = $1
+    AND read_at IS NULL;
\ No newline at end of file
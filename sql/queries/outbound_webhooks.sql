@@ -0,0 +1,110 @@
+-- name: CreateWebhookEndpoint :one
+INSERT INTO webhook_endpoints (id, created_at, updated_at, user_id, url, secret, events)
+VALUES (
+    gen_random_uuid()
+    ,NOW()
+    ,NOW()
+    ,$1
+    ,$2
+    ,$3
+    ,$4
+)
+RETURNING *;
+
+-- name: GetWebhookEndpoint :one
+SELECT *
+FROM webhook_endpoints
+WHERE id = $1;
+
+-- name: GetWebhookEndpointsForUser :many
+SELECT *
+FROM webhook_endpoints
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: GetGlobalWebhookEndpoints :many
+SELECT *
+FROM webhook_endpoints
+WHERE user_id IS NULL
+ORDER BY created_at;
+
+-- name: DeleteWebhookEndpoint :exec
+DELETE FROM webhook_endpoints
+WHERE id = $1;
+
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox_events (id, created_at, event_type, user_id, payload, traceparent)
+VALUES (
+    gen_random_uuid()
+    ,NOW()
+    ,$1
+    ,$2
+    ,$3
+    ,$4
+)
+RETURNING *;
+
+-- name: GetOutboxEvent :one
+SELECT *
+FROM outbox_events
+WHERE id = $1;
+
+-- name: GetUndispatchedOutboxEvents :many
+SELECT *
+FROM outbox_events
+WHERE dispatched_at IS NULL
+ORDER BY created_at
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxEventDispatched :exec
+UPDATE outbox_events
+SET dispatched_at = NOW()
+WHERE id = $1;
+
+-- name: CreateWebhookDeliveriesForEvent :exec
+INSERT INTO webhook_deliveries (id, created_at, updated_at, endpoint_id, event_id, status, next_attempt_at)
+SELECT gen_random_uuid(), NOW(), NOW(), webhook_endpoints.id, sqlc.arg(event_id), 'pending', NOW()
+FROM webhook_endpoints
+WHERE active
+    AND sqlc.arg(event_type)::text = ANY(events)
+    AND (user_id IS NULL OR user_id = sqlc.narg(user_id));
+
+-- name: ClaimDueWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET (updated_at, next_attempt_at) = (NOW(), NOW() + interval '5 minutes')
+WHERE id IN (
+    SELECT id
+    FROM webhook_deliveries
+    WHERE status = 'pending'
+        AND next_attempt_at <= NOW()
+    ORDER BY next_attempt_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkWebhookDeliverySucceeded :exec
+UPDATE webhook_deliveries
+SET (updated_at, status, attempts, last_status_code, last_error, delivered_at) = (NOW(), 'succeeded', attempts + 1, $1, NULL, NOW())
+WHERE id = $2;
+
+-- name: MarkWebhookDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET (updated_at, status, attempts, last_status_code, last_error, next_attempt_at) = (
+    NOW()
+    ,sqlc.arg(status)
+    ,attempts + 1
+    ,sqlc.narg(last_status_code)
+    ,sqlc.narg(last_error)
+    ,NOW() + make_interval(secs => sqlc.arg(backoff_seconds)::float8)
+)
+WHERE id = sqlc.arg(id);
+
+-- name: GetWebhookDeliveriesForEndpoint :many
+SELECT *
+FROM webhook_deliveries
+WHERE endpoint_id = $1
+ORDER BY created_at DESC
+LIMIT $2
+OFFSET $3;
\ No newline at end of file
@@ -0,0 +1,19 @@
+-- name: PinChirp :exec
+INSERT INTO pinned_chirps (user_id, chirp_id, created_at)
+VALUES (
+    $1
+    ,$2
+    ,NOW()
+)
+ON CONFLICT DO NOTHING;
+
+-- name: UnpinChirp :exec
+DELETE FROM pinned_chirps
+WHERE user_id = $1
+    AND chirp_id = $2;
+
+-- name: GetPinnedChirpIDs :many
+SELECT chirp_id
+FROM pinned_chirps
+WHERE user_id = $1
+ORDER BY created_at;
\ No newline at end of file
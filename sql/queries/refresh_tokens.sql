@@ -19,4 +19,10 @@ WHERE revoked_at IS NULL
 -- name: RevokeRefreshToken :exec
 UPDATE refresh_tokens 
 SET (updated_at, revoked_at) = (NOW(), NOW())
-WHERE token = $1;
\ No newline at end of file
+WHERE token = $1;
+
+-- name: RevokeAllRefreshTokensForUser :exec
+UPDATE refresh_tokens
+SET (updated_at, revoked_at) = (NOW(), NOW())
+WHERE user_id = $1
+    AND revoked_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,58 @@
+-- name: CreateReport :one
+INSERT INTO reports (id, created_at, updated_at, reporter_id, target_user_id, target_chirp_id, reason, details)
+VALUES (
+    gen_random_uuid()
+    ,NOW()
+    ,NOW()
+    ,$1
+    ,$2
+    ,$3
+    ,$4
+    ,$5
+)
+RETURNING *;
+
+-- name: GetReport :one
+SELECT *
+FROM reports
+WHERE id = $1;
+
+-- name: GetReports :many
+SELECT *
+FROM reports
+ORDER BY created_at
+LIMIT $1
+OFFSET $2;
+
+-- name: GetReportsByState :many
+SELECT *
+FROM reports
+WHERE state = $1
+ORDER BY created_at
+LIMIT $2
+OFFSET $3;
+
+-- name: CloseReport :execrows
+UPDATE reports
+SET (updated_at, state) = (NOW(), $1)
+WHERE id = $2
+    AND state = 'open';
+
+-- name: CreateModerationAction :one
+INSERT INTO moderation_actions (id, created_at, report_id, target_user_id, moderator_id, action, reason)
+VALUES (
+    gen_random_uuid()
+    ,NOW()
+    ,$1
+    ,$2
+    ,$3
+    ,$4
+    ,$5
+)
+RETURNING *;
+
+-- name: GetModerationActionsForReport :many
+SELECT *
+FROM moderation_actions
+WHERE report_id = $1
+ORDER BY created_at;
\ No newline at end of file
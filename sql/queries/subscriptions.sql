@@ -0,0 +1,59 @@
+-- name: ActivateSubscription :exec
+INSERT INTO subscriptions (user_id, created_at, updated_at, plan, status, current_period_end)
+VALUES (
+    sqlc.arg(user_id)
+    ,NOW()
+    ,NOW()
+    ,sqlc.arg(plan)
+    ,'active'
+    ,COALESCE(sqlc.narg(current_period_end), NOW() + interval '1 month')
+)
+ON CONFLICT (user_id) DO UPDATE
+SET (updated_at, plan, status, current_period_end, grace_period_end) = (
+    NOW()
+    ,EXCLUDED.plan
+    ,'active'
+    ,EXCLUDED.current_period_end
+    ,NULL
+);
+
+-- name: MarkSubscriptionPastDue :exec
+UPDATE subscriptions
+SET (updated_at, status, grace_period_end) = (NOW(), 'past_due', GREATEST(current_period_end, NOW()) + interval '7 days')
+WHERE user_id = $1
+    AND status = 'active';
+
+-- name: CancelSubscription :exec
+UPDATE subscriptions
+SET (updated_at, status) = (NOW(), 'canceled')
+WHERE user_id = $1
+    AND status IN ('active', 'past_due');
+
+-- name: ExpireSubscription :exec
+UPDATE subscriptions
+SET (updated_at, status, grace_period_end) = (NOW(), 'expired', NULL)
+WHERE user_id = $1;
+
+-- name: ExpireSubscriptions :execrows
+UPDATE subscriptions
+SET (updated_at, status, grace_period_end) = (
+    NOW()
+    ,CASE WHEN status = 'active' THEN 'past_due' ELSE 'expired' END
+    ,CASE WHEN status = 'active' THEN current_period_end + interval '7 days' ELSE grace_period_end END
+)
+WHERE (status = 'active' AND current_period_end <= NOW())
+    OR (status = 'past_due' AND grace_period_end <= NOW())
+    OR (status = 'canceled' AND current_period_end <= NOW());
+
+-- name: GetSubscription :one
+SELECT *
+FROM subscriptions
+WHERE user_id = $1;
+
+-- name: IsChirpyRed :one
+SELECT EXISTS (
+    SELECT 1
+    FROM subscriptions
+    WHERE user_id = $1
+        AND status <> 'expired'
+);
\ No newline at end of file
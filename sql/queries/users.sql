@@ -7,11 +7,16 @@ VALUES (
     ,$1
     ,$2
 )
-RETURNING id, created_at, updated_at, email, is_chirpy_red;
+RETURNING id, created_at, updated_at, email;
 
 -- name: ResetUsers :exec
 DELETE FROM users;
 
+-- name: GetUser :one
+SELECT *
+FROM users
+WHERE id = $1;
+
 -- name: GetUserWithEmail :one
 SELECT *
 FROM users
@@ -21,9 +26,76 @@ WHERE email = $1;
 UPDATE users 
 SET (updated_at, email, hashed_password) = (NOW(), $1 ,$2)
 WHERE id = $3
-RETURNING id, created_at, updated_at, email, is_chirpy_red;
+RETURNING id, created_at, updated_at, email;
 
--- name: UpdateUserRed :exec
+-- name: UpdateUserProtected :exec
 UPDATE users 
-SET is_chirpy_red = true
-WHERE id = $1;
\ No newline at end of file
+SET (updated_at, protected) = (NOW(), $1)
+WHERE id = $2;
+
+-- name: ScheduleUserDeletion :one
+UPDATE users
+SET (updated_at, deletion_scheduled_at) = (NOW(), NOW() + interval '30 days')
+WHERE id = $1
+RETURNING deletion_scheduled_at;
+
+-- name: CancelUserDeletion :exec
+UPDATE users
+SET (updated_at, deletion_scheduled_at) = (NOW(), NULL)
+WHERE id = $1
+    AND deletion_scheduled_at IS NOT NULL;
+
+-- name: GetUsersDueForDeletion :many
+SELECT id, deletion_scheduled_at
+FROM users
+WHERE deletion_scheduled_at <= NOW();
+
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1
+    AND deletion_scheduled_at <= NOW();
+
+-- name: CreateAccountDeletion :exec
+INSERT INTO account_deletions (user_id, scheduled_at, deleted_at)
+VALUES (
+    $1
+    ,$2
+    ,NOW()
+);
+
+-- name: GetUserRole :one
+SELECT role
+FROM users
+WHERE id = $1;
+
+-- name: UpdateUserRole :execrows
+UPDATE users
+SET (updated_at, role) = (NOW(), $1)
+WHERE id = $2;
+
+-- name: UpdateUserRoleByEmail :execrows
+UPDATE users
+SET (updated_at, role) = (NOW(), $1)
+WHERE email = $2;
+
+-- name: SuspendUser :exec
+UPDATE users
+SET (updated_at, suspension, suspended_until) = (
+    NOW()
+    ,sqlc.arg(suspension)
+    ,NOW() + make_interval(hours => sqlc.narg(duration_hours)::int)
+)
+WHERE id = sqlc.arg(id);
+
+-- name: GetActiveSuspension :one
+SELECT CASE
+    WHEN suspended_until IS NULL OR suspended_until > NOW() THEN suspension
+END AS suspension
+FROM users
+WHERE id = $1;
+
+-- name: LiftSuspension :execrows
+UPDATE users
+SET (updated_at, suspension, suspended_until) = (NOW(), NULL, NULL)
+WHERE id = $1
+    AND suspension IS NOT NULL;
\ No newline at end of file
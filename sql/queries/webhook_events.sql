@@ -0,0 +1,50 @@
+-- name: CreateWebhookEvent :one
+INSERT INTO webhook_events (id, created_at, updated_at, provider, event_id, event_type, status, payload)
+VALUES (
+    gen_random_uuid()
+    ,NOW()
+    ,NOW()
+    ,$1
+    ,$2
+    ,$3
+    ,'received'
+    ,$4
+)
+ON CONFLICT (provider, event_id) DO NOTHING
+RETURNING *;
+
+-- name: GetWebhookEvent :one
+SELECT *
+FROM webhook_events
+WHERE id = $1;
+
+-- name: GetWebhookEventByEventID :one
+SELECT *
+FROM webhook_events
+WHERE provider = $1
+    AND event_id = $2;
+
+-- name: GetWebhookEvents :many
+SELECT *
+FROM webhook_events
+ORDER BY created_at DESC
+LIMIT $1
+OFFSET $2;
+
+-- name: GetWebhookEventsByStatus :many
+SELECT *
+FROM webhook_events
+WHERE status = $1
+ORDER BY created_at DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: MarkWebhookEventProcessed :exec
+UPDATE webhook_events
+SET (updated_at, status, attempts, last_error, processed_at) = (NOW(), $1, attempts + 1, NULL, NOW())
+WHERE id = $2;
+
+-- name: MarkWebhookEventFailed :exec
+UPDATE webhook_events
+SET (updated_at, status, attempts, last_error) = (NOW(), 'failed', attempts + 1, $1)
+WHERE id = $2;
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE bookmarks (
+    user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+    ,chirp_id UUID NOT NULL REFERENCES chirps ON DELETE CASCADE
+    ,created_at TIMESTAMP NOT NULL
+    ,PRIMARY KEY (user_id, chirp_id)
+);
+
+-- +goose Down
+DROP TABLE bookmarks;
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN protected BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE follows (
+    follower_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+    ,followee_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+    ,status TEXT NOT NULL CHECK (status IN ('pending', 'accepted'))
+    ,created_at TIMESTAMP NOT NULL
+    ,updated_at TIMESTAMP NOT NULL
+    ,PRIMARY KEY (follower_id, followee_id)
+);
+
+-- +goose Down
+DROP TABLE follows;
+ALTER TABLE users DROP COLUMN protected;
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN deletion_scheduled_at TIMESTAMP;
+
+CREATE TABLE account_deletions (
+    user_id UUID NOT NULL PRIMARY KEY
+    ,scheduled_at TIMESTAMP NOT NULL
+    ,deleted_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE account_deletions;
+ALTER TABLE users DROP COLUMN deletion_scheduled_at;
\ No newline at end of file
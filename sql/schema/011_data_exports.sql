@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE data_exports (
+    id UUID NOT NULL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,updated_at TIMESTAMP NOT NULL
+    ,user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+    ,status TEXT NOT NULL CHECK (status IN ('pending', 'ready', 'failed'))
+    ,file_path TEXT
+    ,expires_at TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE data_exports;
\ No newline at end of file
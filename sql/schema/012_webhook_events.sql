@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE webhook_events (
+    id UUID NOT NULL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,updated_at TIMESTAMP NOT NULL
+    ,provider TEXT NOT NULL
+    ,event_id TEXT NOT NULL
+    ,event_type TEXT NOT NULL
+    ,status TEXT NOT NULL CHECK (status IN ('received', 'processed', 'ignored', 'failed'))
+    ,attempts INTEGER NOT NULL DEFAULT 0
+    ,payload JSONB NOT NULL
+    ,last_error TEXT
+    ,processed_at TIMESTAMP
+    ,UNIQUE (provider, event_id)
+);
+
+-- +goose Down
+DROP TABLE webhook_events;
\ No newline at end of file
@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE subscriptions (
+    user_id UUID NOT NULL PRIMARY KEY REFERENCES users ON DELETE CASCADE
+    ,created_at TIMESTAMP NOT NULL
+    ,updated_at TIMESTAMP NOT NULL
+    ,plan TEXT NOT NULL
+    ,status TEXT NOT NULL CHECK (status IN ('active', 'past_due', 'canceled', 'expired'))
+    ,current_period_end TIMESTAMP NOT NULL
+    ,grace_period_end TIMESTAMP
+);
+
+-- Red from before subscriptions has no renewal to extend it, so it is given
+-- a period end that ExpireSubscriptions never reaches.
+INSERT INTO subscriptions (user_id, created_at, updated_at, plan, status, current_period_end)
+SELECT id, NOW(), NOW(), 'chirpy_red', 'active', '9999-12-31'
+FROM users
+WHERE is_chirpy_red;
+
+ALTER TABLE users DROP COLUMN is_chirpy_red;
+
+-- +goose Down
+ALTER TABLE users ADD COLUMN is_chirpy_red BOOLEAN DEFAULT false;
+
+UPDATE users
+SET is_chirpy_red = true
+WHERE id IN (
+    SELECT user_id
+    FROM subscriptions
+    WHERE status <> 'expired'
+);
+
+DROP TABLE subscriptions;
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE entitlement_overrides (
+    user_id UUID NOT NULL PRIMARY KEY REFERENCES users ON DELETE CASCADE
+    ,created_at TIMESTAMP NOT NULL
+    ,updated_at TIMESTAMP NOT NULL
+    ,overrides JSONB NOT NULL
+);
+
+-- +goose Down
+DROP TABLE entitlement_overrides;
\ No newline at end of file
@@ -0,0 +1,43 @@
+-- +goose Up
+CREATE TABLE webhook_endpoints (
+    id UUID NOT NULL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,updated_at TIMESTAMP NOT NULL
+    ,user_id UUID REFERENCES users ON DELETE CASCADE
+    ,url TEXT NOT NULL
+    ,secret TEXT NOT NULL
+    ,events TEXT[] NOT NULL
+    ,active BOOLEAN NOT NULL DEFAULT true
+);
+
+CREATE TABLE outbox_events (
+    id UUID NOT NULL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,event_type TEXT NOT NULL
+    ,user_id UUID REFERENCES users ON DELETE CASCADE
+    ,payload JSONB NOT NULL
+    ,dispatched_at TIMESTAMP
+);
+
+CREATE INDEX outbox_events_undispatched_idx ON outbox_events (created_at) WHERE dispatched_at IS NULL;
+
+CREATE TABLE webhook_deliveries (
+    id UUID NOT NULL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,updated_at TIMESTAMP NOT NULL
+    ,endpoint_id UUID NOT NULL REFERENCES webhook_endpoints ON DELETE CASCADE
+    ,event_id UUID NOT NULL REFERENCES outbox_events ON DELETE CASCADE
+    ,status TEXT NOT NULL CHECK (status IN ('pending', 'succeeded', 'dead'))
+    ,attempts INTEGER NOT NULL DEFAULT 0
+    ,next_attempt_at TIMESTAMP NOT NULL
+    ,last_status_code INTEGER
+    ,last_error TEXT
+    ,delivered_at TIMESTAMP
+);
+
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE webhook_deliveries;
+DROP TABLE outbox_events;
+DROP TABLE webhook_endpoints;
\ No newline at end of file
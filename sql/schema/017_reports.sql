@@ -0,0 +1,42 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN suspension TEXT CHECK (suspension IN ('suspended'));
+ALTER TABLE users ADD COLUMN suspended_until TIMESTAMP;
+
+CREATE TABLE reports (
+    id UUID NOT NULL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,updated_at TIMESTAMP NOT NULL
+    ,reporter_id UUID REFERENCES users ON DELETE SET NULL
+    ,target_user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+    ,target_chirp_id UUID REFERENCES chirps ON DELETE SET NULL
+    ,reason TEXT NOT NULL CHECK (reason IN ('spam', 'harassment', 'hate', 'violence', 'sexual', 'self_harm', 'impersonation', 'other'))
+    ,details TEXT NOT NULL
+    ,state TEXT NOT NULL DEFAULT 'open' CHECK (state IN ('open', 'resolved', 'dismissed'))
+);
+
+CREATE INDEX reports_state_idx ON reports (state, created_at);
+
+CREATE TABLE moderation_actions (
+    id UUID NOT NULL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,report_id UUID NOT NULL REFERENCES reports ON DELETE CASCADE
+    ,moderator_id UUID REFERENCES users ON DELETE SET NULL
+    ,action TEXT NOT NULL CHECK (action IN ('dismiss', 'delete_chirp', 'warn', 'suspend'))
+    ,reason TEXT NOT NULL
+);
+
+CREATE TABLE notifications (
+    id UUID NOT NULL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+    ,kind TEXT NOT NULL
+    ,body TEXT NOT NULL
+    ,read_at TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE notifications;
+DROP TABLE moderation_actions;
+DROP TABLE reports;
+ALTER TABLE users DROP COLUMN suspended_until;
+ALTER TABLE users DROP COLUMN suspension;
\ No newline at end of file
@@ -0,0 +1,34 @@
+-- +goose Up
+ALTER TABLE users DROP CONSTRAINT users_suspension_check;
+ALTER TABLE users ADD CONSTRAINT users_suspension_check CHECK (suspension IN ('suspended', 'read_only', 'shadow_limited'));
+
+ALTER TABLE moderation_actions DROP CONSTRAINT moderation_actions_action_check;
+ALTER TABLE moderation_actions ADD CONSTRAINT moderation_actions_action_check CHECK (action IN ('dismiss', 'delete_chirp', 'warn', 'suspend', 'read_only', 'shadow_limit', 'lift_suspension'));
+
+ALTER TABLE moderation_actions ALTER COLUMN report_id DROP NOT NULL;
+ALTER TABLE moderation_actions ADD COLUMN target_user_id UUID REFERENCES users ON DELETE CASCADE;
+
+UPDATE moderation_actions
+SET target_user_id = reports.target_user_id
+FROM reports
+WHERE reports.id = moderation_actions.report_id;
+
+ALTER TABLE moderation_actions ALTER COLUMN target_user_id SET NOT NULL;
+
+-- +goose Down
+DELETE FROM moderation_actions
+WHERE report_id IS NULL
+    OR action NOT IN ('dismiss', 'delete_chirp', 'warn', 'suspend');
+
+ALTER TABLE moderation_actions DROP COLUMN target_user_id;
+ALTER TABLE moderation_actions ALTER COLUMN report_id SET NOT NULL;
+
+ALTER TABLE moderation_actions DROP CONSTRAINT moderation_actions_action_check;
+ALTER TABLE moderation_actions ADD CONSTRAINT moderation_actions_action_check CHECK (action IN ('dismiss', 'delete_chirp', 'warn', 'suspend'));
+
+UPDATE users
+SET (suspension, suspended_until) = (NULL, NULL)
+WHERE suspension <> 'suspended';
+
+ALTER TABLE users DROP CONSTRAINT users_suspension_check;
+ALTER TABLE users ADD CONSTRAINT users_suspension_check CHECK (suspension IN ('suspended'));
\ No newline at end of file
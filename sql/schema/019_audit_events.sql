@@ -0,0 +1,34 @@
+-- +goose Up
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,action TEXT NOT NULL
+    ,actor_id UUID
+    ,target_type TEXT NOT NULL
+    ,target_id TEXT NOT NULL
+    ,ip TEXT NOT NULL
+    ,user_agent TEXT NOT NULL
+    ,request_id TEXT NOT NULL
+    ,metadata JSON NOT NULL
+    ,prev_hash TEXT NOT NULL
+    ,hash TEXT NOT NULL
+);
+
+CREATE INDEX audit_events_created_at_idx ON audit_events (created_at);
+CREATE INDEX audit_events_actor_id_idx ON audit_events (actor_id);
+
+-- +goose StatementBegin
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_events_no_update
+BEFORE UPDATE ON audit_events
+FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+-- +goose Down
+DROP TABLE audit_events;
+DROP FUNCTION audit_events_append_only;
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE outbox_events ADD COLUMN traceparent TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE outbox_events DROP COLUMN traceparent;
\ No newline at end of file
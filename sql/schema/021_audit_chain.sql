@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE audit_chain (
+    id INTEGER NOT NULL PRIMARY KEY CHECK (id = 1)
+    ,head_hash TEXT NOT NULL
+);
+
+INSERT INTO audit_chain (id, head_hash)
+VALUES (1, COALESCE((SELECT hash FROM audit_events ORDER BY id DESC LIMIT 1), ''));
+
+-- +goose StatementBegin
+CREATE FUNCTION audit_events_prune_only() RETURNS trigger AS $$
+BEGIN
+    IF current_setting('chirpy.audit_prune', true) = 'on' THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'audit_events can only be deleted by the retention policy';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_events_no_delete
+BEFORE DELETE ON audit_events
+FOR EACH ROW EXECUTE FUNCTION audit_events_prune_only();
+
+CREATE TRIGGER audit_events_no_truncate
+BEFORE TRUNCATE ON audit_events
+FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
+
+-- +goose Down
+DROP TRIGGER audit_events_no_truncate ON audit_events;
+DROP TRIGGER audit_events_no_delete ON audit_events;
+DROP FUNCTION audit_events_prune_only;
+DROP TABLE audit_chain;
\ No newline at end of file
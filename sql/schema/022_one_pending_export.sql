@@ -0,0 +1,15 @@
+-- +goose Up
+UPDATE data_exports
+SET (updated_at, status) = (NOW(), 'failed')
+WHERE status = 'pending'
+    AND id NOT IN (
+        SELECT DISTINCT ON (user_id) id
+        FROM data_exports
+        WHERE status = 'pending'
+        ORDER BY user_id, created_at DESC, id DESC
+    );
+
+CREATE UNIQUE INDEX data_exports_one_pending ON data_exports (user_id) WHERE status = 'pending';
+
+-- +goose Down
+DROP INDEX data_exports_one_pending;
\ No newline at end of file
@@ -0,0 +1,19 @@
+-- +goose Up
+-- 013 used to give Red from before subscriptions a period end a month out,
+-- which nothing renews, so it lapsed. Those rows are the ones created by
+-- 013, in the same transaction that recorded it, whose period end hasn't
+-- changed since. They get the period end 013 now gives, and those already
+-- past due are made active again.
+UPDATE subscriptions
+SET (updated_at, status, current_period_end, grace_period_end) = (NOW(), 'active', '9999-12-31', NULL)
+WHERE status IN ('active', 'past_due')
+    AND current_period_end = created_at + interval '1 month'
+    AND created_at = (
+        SELECT MAX(tstamp)
+        FROM goose_db_version
+        WHERE version_id = 13
+            AND is_applied
+    );
+
+-- +goose Down
+-- The old period ends were wrong, so nothing is put back.
\ No newline at end of file
@@ -0,0 +1,73 @@
+-- name: GetLastAuditHash :one
+SELECT head_hash
+FROM audit_chain;
+
+-- name: SetAuditChainHead :exec
+UPDATE audit_chain
+SET head_hash = ?
+    ,pruning = FALSE;
+
+-- name: CreateAuditEvent :execlastid
+INSERT INTO audit_events (created_at, action, actor_id, target_type, target_id, ip, user_agent, request_id, metadata, prev_hash, hash)
+VALUES (
+    ?
+    ,?
+    ,?
+    ,?
+    ,?
+    ,?
+    ,?
+    ,?
+    ,?
+    ,?
+    ,?
+);
+
+-- name: GetAuditEvent :one
+SELECT *
+FROM audit_events
+WHERE id = ?;
+
+-- name: GetAuditEvents :many
+SELECT *
+FROM audit_events
+WHERE (actor_id = sqlc.narg(actor_id) OR sqlc.narg(actor_id) IS NULL)
+    AND (action = sqlc.narg(action) OR sqlc.narg(action) IS NULL)
+    AND (target_id = sqlc.narg(target_id) OR sqlc.narg(target_id) IS NULL)
+    AND (created_at >= sqlc.narg(since) OR sqlc.narg(since) IS NULL)
+    AND (created_at < sqlc.narg(until) OR sqlc.narg(until) IS NULL)
+ORDER BY id DESC
+LIMIT sqlc.arg(lim)
+OFFSET sqlc.arg(off);
+
+-- name: GetAuditEventsAfter :many
+SELECT *
+FROM audit_events
+WHERE id > ?
+ORDER BY id
+LIMIT ?;
+
+-- name: AllowAuditPrune :exec
+UPDATE audit_chain
+SET pruning = TRUE;
+
+-- name: DeleteAuditEventsBefore :execrows
+DELETE FROM audit_events
+WHERE id < (
+    SELECT COALESCE(MIN(id), (SELECT MAX(id) + 1 FROM audit_events))
+    FROM audit_events
+    WHERE created_at >= ?
+);
+
+-- name: GetFirstAuditPrevHash :one
+SELECT prev_hash
+FROM audit_events
+ORDER BY id
+LIMIT 1;
+
+-- name: GetLatestAuditPrune :one
+SELECT *
+FROM audit_events
+WHERE action = 'audit.pruned'
+ORDER BY id DESC
+LIMIT 1;
\ No newline at end of file
@@ -0,0 +1,51 @@
+-- name: CreateBlock :exec
+INSERT INTO blocks (blocker_id, blocked_id, created_at)
+VALUES (
+    sqlc.arg(blocker_id)
+    ,sqlc.arg(blocked_id)
+    ,sqlc.arg(now)
+)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteBlock :exec
+DELETE FROM blocks
+WHERE blocker_id = ?
+    AND blocked_id = ?;
+
+-- name: GetBlocks :many
+SELECT *
+FROM blocks
+WHERE blocker_id = ?
+ORDER BY created_at DESC
+LIMIT ?
+OFFSET ?;
+
+-- name: IsBlocked :one
+SELECT CAST(EXISTS (
+    SELECT 1
+    FROM blocks
+    WHERE (blocker_id = sqlc.arg(user_id) AND blocked_id = sqlc.arg(other_user_id))
+        OR (blocker_id = sqlc.arg(other_user_id) AND blocked_id = sqlc.arg(user_id))
+) AS BOOLEAN);
+
+-- name: CreateMute :exec
+INSERT INTO mutes (muter_id, muted_id, created_at)
+VALUES (
+    sqlc.arg(muter_id)
+    ,sqlc.arg(muted_id)
+    ,sqlc.arg(now)
+)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteMute :exec
+DELETE FROM mutes
+WHERE muter_id = ?
+    AND muted_id = ?;
+
+-- name: GetMutes :many
+SELECT *
+FROM mutes
+WHERE muter_id = ?
+ORDER BY created_at DESC
+LIMIT ?
+OFFSET ?;
\ No newline at end of file
@@ -0,0 +1,62 @@
+-- name: CreateBookmark :exec
+INSERT INTO bookmarks (user_id, chirp_id, created_at)
+VALUES (
+    sqlc.arg(user_id)
+    ,sqlc.arg(chirp_id)
+    ,sqlc.arg(now)
+)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteBookmark :exec
+DELETE FROM bookmarks
+WHERE user_id = ?
+    AND chirp_id = ?;
+
+-- name: GetBookmarkedChirpIDs :many
+SELECT chirp_id
+FROM bookmarks
+WHERE user_id = ?;
+
+-- name: GetBookmarkedChirps :many
+SELECT chirps.*
+FROM chirps
+JOIN bookmarks ON bookmarks.chirp_id = chirps.id
+WHERE bookmarks.user_id = sqlc.arg(user_id)
+    AND chirps.user_id NOT IN (
+        SELECT blocked_id
+        FROM blocks
+        WHERE blocker_id = sqlc.arg(user_id)
+    )
+    AND chirps.user_id NOT IN (
+        SELECT blocker_id
+        FROM blocks
+        WHERE blocked_id = sqlc.arg(user_id)
+    )
+    AND (
+        chirps.user_id = sqlc.arg(user_id)
+        OR NOT EXISTS (
+            SELECT 1
+            FROM users
+            WHERE users.id = chirps.user_id
+                AND users.protected
+        )
+        OR EXISTS (
+            SELECT 1
+            FROM follows
+            WHERE follower_id = sqlc.arg(user_id)
+                AND followee_id = chirps.user_id
+                AND status = 'accepted'
+        )
+    )
+    AND (
+        chirps.user_id = sqlc.arg(user_id)
+        OR chirps.user_id NOT IN (
+            SELECT id
+            FROM users
+            WHERE suspension IN ('suspended', 'shadow_limited')
+                AND (suspended_until IS NULL OR suspended_until > sqlc.arg(now))
+        )
+    )
+ORDER BY bookmarks.created_at DESC
+LIMIT sqlc.arg(limit)
+OFFSET sqlc.arg(offset);
\ No newline at end of file
@@ -0,0 +1,110 @@
+-- name: CreateChirp :exec
+INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+VALUES (
+    sqlc.arg(id)
+    ,sqlc.arg(now)
+    ,sqlc.arg(now)
+    ,sqlc.arg(body)
+    ,sqlc.arg(user_id)
+);
+
+-- name: GetAllChirps :many
+SELECT *
+FROM chirps
+WHERE chirps.user_id NOT IN (
+        SELECT blocked_id
+        FROM blocks
+        WHERE blocks.blocker_id = sqlc.arg(viewer_id)
+    )
+    AND chirps.user_id NOT IN (
+        SELECT blocker_id
+        FROM blocks
+        WHERE blocks.blocked_id = sqlc.arg(viewer_id)
+    )
+    AND chirps.user_id NOT IN (
+        SELECT muted_id
+        FROM mutes
+        WHERE muter_id = sqlc.arg(viewer_id)
+    )
+    AND (
+        chirps.user_id = sqlc.arg(viewer_id)
+        OR NOT EXISTS (
+            SELECT 1
+            FROM users
+            WHERE users.id = chirps.user_id
+                AND users.protected
+        )
+        OR EXISTS (
+            SELECT 1
+            FROM follows
+            WHERE follower_id = sqlc.arg(viewer_id)
+                AND followee_id = chirps.user_id
+                AND status = 'accepted'
+        )
+    )
+    AND (
+        chirps.user_id = sqlc.arg(viewer_id)
+        OR chirps.user_id NOT IN (
+            SELECT id
+            FROM users
+            WHERE suspension IN ('suspended', 'shadow_limited')
+                AND (suspended_until IS NULL OR suspended_until > sqlc.arg(now))
+        )
+    )
+ORDER BY created_at;
+
+-- name: GetChirp :one
+SELECT *
+FROM chirps
+WHERE id = ?;
+
+-- name: DeleteChirp :exec
+DELETE FROM chirps
+WHERE id = ?;
+
+-- name: GetChirpsForUser :many
+SELECT *
+FROM chirps
+WHERE user_id = sqlc.arg(user_id)
+    AND chirps.user_id NOT IN (
+        SELECT blocked_id
+        FROM blocks
+        WHERE blocks.blocker_id = sqlc.arg(viewer_id)
+    )
+    AND chirps.user_id NOT IN (
+        SELECT blocker_id
+        FROM blocks
+        WHERE blocks.blocked_id = sqlc.arg(viewer_id)
+    )
+    AND (
+        chirps.user_id = sqlc.arg(viewer_id)
+        OR NOT EXISTS (
+            SELECT 1
+            FROM users
+            WHERE users.id = chirps.user_id
+                AND users.protected
+        )
+        OR EXISTS (
+            SELECT 1
+            FROM follows
+            WHERE follower_id = sqlc.arg(viewer_id)
+                AND followee_id = chirps.user_id
+                AND status = 'accepted'
+        )
+    )
+    AND (
+        chirps.user_id = sqlc.arg(viewer_id)
+        OR chirps.user_id NOT IN (
+            SELECT id
+            FROM users
+            WHERE suspension IN ('suspended', 'shadow_limited')
+                AND (suspended_until IS NULL OR suspended_until > sqlc.arg(now))
+        )
+    )
+ORDER BY created_at;
+
+-- name: CountChirpsInLastHour :one
+SELECT COUNT(*)
+FROM chirps
+WHERE user_id = sqlc.arg(user_id)
+    AND created_at > sqlc.arg(since);
\ No newline at end of file
@@ -0,0 +1,95 @@
+-- name: CreateDataExport :execrows
+INSERT INTO data_exports (id, created_at, updated_at, user_id, status)
+VALUES (
+    sqlc.arg(id)
+    ,sqlc.arg(now)
+    ,sqlc.arg(now)
+    ,sqlc.arg(user_id)
+    ,'pending'
+)
+ON CONFLICT (user_id) WHERE status = 'pending' DO NOTHING;
+
+-- name: GetDataExport :one
+SELECT *
+FROM data_exports
+WHERE id = ?;
+
+-- name: CompleteDataExport :exec
+UPDATE data_exports
+SET updated_at = sqlc.arg(now)
+    ,status = 'ready'
+    ,file_path = sqlc.arg(file_path)
+    ,expires_at = sqlc.arg(expires_at)
+WHERE id = sqlc.arg(id);
+
+-- name: FailDataExport :exec
+UPDATE data_exports
+SET updated_at = sqlc.arg(now)
+    ,status = 'failed'
+WHERE id = sqlc.arg(id);
+
+-- name: GetAbandonedDataExports :many
+SELECT *
+FROM data_exports
+WHERE status = 'pending'
+    AND updated_at <= sqlc.arg(claimed_before)
+ORDER BY created_at
+LIMIT sqlc.arg(limit);
+
+-- name: ClaimDataExport :execrows
+UPDATE data_exports
+SET updated_at = sqlc.arg(now)
+WHERE id = sqlc.arg(id)
+    AND status = 'pending'
+    AND updated_at <= sqlc.arg(claimed_before);
+
+-- name: GetDataExportFilesForUser :many
+SELECT file_path
+FROM data_exports
+WHERE user_id = ?
+    AND file_path IS NOT NULL;
+
+-- name: GetExpiredDataExports :many
+SELECT *
+FROM data_exports
+WHERE expires_at <= sqlc.arg(now);
+
+-- name: DeleteExpiredDataExport :execrows
+DELETE FROM data_exports
+WHERE id = sqlc.arg(id)
+    AND expires_at <= sqlc.arg(now);
+
+-- name: GetChirpsForExport :many
+SELECT *
+FROM chirps
+WHERE user_id = sqlc.arg(user_id)
+    AND (
+        created_at > sqlc.arg(after_created_at)
+        OR (created_at = sqlc.arg(after_created_at) AND id > sqlc.arg(after_id))
+    )
+ORDER BY created_at, id
+LIMIT sqlc.arg(batch_size);
+
+-- name: GetBookmarksForExport :many
+SELECT *
+FROM bookmarks
+WHERE user_id = sqlc.arg(user_id)
+    AND (
+        created_at > sqlc.arg(after_created_at)
+        OR (created_at = sqlc.arg(after_created_at) AND chirp_id > sqlc.arg(after_chirp_id))
+    )
+ORDER BY created_at, chirp_id
+LIMIT sqlc.arg(batch_size);
+
+-- name: GetFollowsForExport :many
+SELECT *
+FROM follows
+WHERE follower_id = sqlc.arg(user_id)
+    OR followee_id = sqlc.arg(user_id)
+ORDER BY created_at;
+
+-- name: GetRefreshTokensForExport :many
+SELECT created_at, updated_at, expires_at, revoked_at
+FROM refresh_tokens
+WHERE user_id = ?
+ORDER BY created_at;
\ No newline at end of file
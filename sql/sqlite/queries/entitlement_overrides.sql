@@ -0,0 +1,20 @@
+-- name: UpsertEntitlementOverride :exec
+INSERT INTO entitlement_overrides (user_id, created_at, updated_at, overrides)
+VALUES (
+    sqlc.arg(user_id)
+    ,sqlc.arg(now)
+    ,sqlc.arg(now)
+    ,sqlc.arg(overrides)
+)
+ON CONFLICT (user_id) DO UPDATE
+SET updated_at = excluded.updated_at
+    ,overrides = excluded.overrides;
+
+-- name: GetEntitlementOverride :one
+SELECT overrides
+FROM entitlement_overrides
+WHERE user_id = ?;
+
+-- name: DeleteEntitlementOverride :exec
+DELETE FROM entitlement_overrides
+WHERE user_id = ?;
\ No newline at end of file
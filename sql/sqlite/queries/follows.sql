@@ -0,0 +1,80 @@
+-- name: CreateFollow :execrows
+INSERT INTO follows (follower_id, followee_id, status, created_at, updated_at)
+VALUES (
+    sqlc.arg(follower_id)
+    ,sqlc.arg(followee_id)
+    ,sqlc.arg(status)
+    ,sqlc.arg(now)
+    ,sqlc.arg(now)
+)
+ON CONFLICT DO NOTHING;
+
+-- name: GetFollow :one
+SELECT *
+FROM follows
+WHERE follower_id = ?
+    AND followee_id = ?;
+
+-- name: DeleteFollow :exec
+DELETE FROM follows
+WHERE follower_id = ?
+    AND followee_id = ?;
+
+-- name: DeleteFollowsBetween :exec
+DELETE FROM follows
+WHERE (follower_id = sqlc.arg(user_id) AND followee_id = sqlc.arg(other_user_id))
+    OR (follower_id = sqlc.arg(other_user_id) AND followee_id = sqlc.arg(user_id));
+
+-- name: GetFollowRequests :many
+SELECT *
+FROM follows
+WHERE followee_id = ?
+    AND status = 'pending'
+ORDER BY created_at
+LIMIT ?
+OFFSET ?;
+
+-- name: AcceptFollowRequest :execrows
+UPDATE follows
+SET updated_at = sqlc.arg(now)
+    ,status = 'accepted'
+WHERE follower_id = sqlc.arg(follower_id)
+    AND followee_id = sqlc.arg(followee_id)
+    AND status = 'pending';
+
+-- name: RejectFollowRequest :execrows
+DELETE FROM follows
+WHERE follower_id = ?
+    AND followee_id = ?
+    AND status = 'pending';
+
+-- name: GetPendingFollowerIDs :many
+SELECT follower_id
+FROM follows
+WHERE followee_id = ?
+    AND status = 'pending'
+ORDER BY created_at;
+
+-- name: CanViewUser :one
+SELECT CAST((
+    users.id = sqlc.arg(viewer_id)
+    OR (
+        (
+            NOT users.protected
+            OR EXISTS (
+                SELECT 1
+                FROM follows
+                WHERE follower_id = sqlc.arg(viewer_id)
+                    AND followee_id = users.id
+                    AND status = 'accepted'
+            )
+        )
+        AND (
+            users.suspension IS NULL
+            OR users.suspension NOT IN ('suspended', 'shadow_limited')
+            OR (users.suspended_until IS NOT NULL AND users.suspended_until <= sqlc.arg(now))
+        )
+    )
+) AS BOOLEAN) AS can_view
+FROM users
+WHERE users.id = sqlc.arg(user_id);
\ No newline at end of file
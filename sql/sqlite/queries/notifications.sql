@@ -0,0 +1,23 @@
+-- name: CreateNotification :exec
+INSERT INTO notifications (id, created_at, user_id, kind, body)
+VALUES (
+    sqlc.arg(id)
+    ,sqlc.arg(now)
+    ,sqlc.arg(user_id)
+    ,sqlc.arg(kind)
+    ,sqlc.arg(body)
+);
+
+-- name: GetNotificationsForUser :many
+SELECT *
+FROM notifications
+WHERE user_id = ?
+ORDER BY created_at DESC
+LIMIT ?
+OFFSET ?;
+
+-- name: MarkNotificationsRead :exec
+UPDATE notifications
+SET read_at = sqlc.arg(now)
+WHERE user_id = sqlc.arg(user_id)
+    AND read_at IS NULL;
\ No newline at end of file
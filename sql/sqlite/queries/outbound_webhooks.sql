@@ -0,0 +1,125 @@
+-- name: CreateWebhookEndpoint :exec
+INSERT INTO webhook_endpoints (id, created_at, updated_at, user_id, url, secret, events)
+VALUES (
+    sqlc.arg(id)
+    ,sqlc.arg(now)
+    ,sqlc.arg(now)
+    ,sqlc.narg(user_id)
+    ,sqlc.arg(url)
+    ,sqlc.arg(secret)
+    ,sqlc.arg(events)
+);
+
+-- name: GetWebhookEndpoint :one
+SELECT *
+FROM webhook_endpoints
+WHERE id = ?;
+
+-- name: GetWebhookEndpointsForUser :many
+SELECT *
+FROM webhook_endpoints
+WHERE user_id = ?
+ORDER BY created_at;
+
+-- name: GetGlobalWebhookEndpoints :many
+SELECT *
+FROM webhook_endpoints
+WHERE user_id IS NULL
+ORDER BY created_at;
+
+-- name: DeleteWebhookEndpoint :exec
+DELETE FROM webhook_endpoints
+WHERE id = ?;
+
+-- name: CreateOutboxEvent :exec
+INSERT INTO outbox_events (id, created_at, event_type, user_id, payload, traceparent)
+VALUES (
+    sqlc.arg(id)
+    ,sqlc.arg(now)
+    ,sqlc.arg(event_type)
+    ,sqlc.narg(user_id)
+    ,sqlc.arg(payload)
+    ,sqlc.arg(traceparent)
+);
+
+-- name: GetOutboxEvent :one
+SELECT *
+FROM outbox_events
+WHERE id = ?;
+
+-- name: GetUndispatchedOutboxEvents :many
+SELECT *
+FROM outbox_events
+WHERE dispatched_at IS NULL
+ORDER BY created_at
+LIMIT ?;
+
+-- name: MarkOutboxEventDispatched :exec
+UPDATE outbox_events
+SET dispatched_at = sqlc.arg(now)
+WHERE id = sqlc.arg(id);
+
+-- name: CreateWebhookDeliveriesForEvent :exec
+-- One delivery is made per matching endpoint, so the IDs are generated here
+-- as version 4 UUIDs rather than passed in. events is a JSON array of
+-- strings, so the quoted event type only matches a whole element.
+INSERT INTO webhook_deliveries (id, created_at, updated_at, endpoint_id, event_id, status, next_attempt_at)
+SELECT
+    lower(
+        hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-4' || substr(hex(randomblob(2)), 2) || '-'
+        || substr('89ab', 1 + abs(random()) % 4, 1) || substr(hex(randomblob(2)), 2) || '-' || hex(randomblob(6))
+    )
+    ,sqlc.arg(now)
+    ,sqlc.arg(now)
+    ,webhook_endpoints.id
+    ,sqlc.arg(event_id)
+    ,'pending'
+    ,sqlc.arg(now)
+FROM webhook_endpoints
+WHERE active
+    AND instr(events, json_quote(CAST(sqlc.arg(event_type) AS TEXT))) > 0
+    AND (user_id IS NULL OR user_id = sqlc.narg(user_id));
+
+-- name: GetDueWebhookDeliveries :many
+SELECT *
+FROM webhook_deliveries
+WHERE status = 'pending'
+    AND next_attempt_at <= sqlc.arg(now)
+ORDER BY next_attempt_at
+LIMIT sqlc.arg(limit);
+
+-- name: ClaimWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET updated_at = sqlc.arg(now)
+    ,next_attempt_at = sqlc.arg(lease_until)
+WHERE id = sqlc.arg(id)
+    AND status = 'pending'
+    AND next_attempt_at <= sqlc.arg(now);
+
+-- name: MarkWebhookDeliverySucceeded :exec
+UPDATE webhook_deliveries
+SET updated_at = sqlc.arg(now)
+    ,status = 'succeeded'
+    ,attempts = attempts + 1
+    ,last_status_code = sqlc.narg(last_status_code)
+    ,last_error = NULL
+    ,delivered_at = sqlc.arg(now)
+WHERE id = sqlc.arg(id);
+
+-- name: MarkWebhookDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET updated_at = sqlc.arg(now)
+    ,status = sqlc.arg(status)
+    ,attempts = attempts + 1
+    ,last_status_code = sqlc.narg(last_status_code)
+    ,last_error = sqlc.narg(last_error)
+    ,next_attempt_at = sqlc.arg(next_attempt_at)
+WHERE id = sqlc.arg(id);
+
+-- name: GetWebhookDeliveriesForEndpoint :many
+SELECT *
+FROM webhook_deliveries
+WHERE endpoint_id = ?
+ORDER BY created_at DESC
+LIMIT ?
+OFFSET ?;
\ No newline at end of file
@@ -0,0 +1,19 @@
+-- name: PinChirp :exec
+INSERT INTO pinned_chirps (user_id, chirp_id, created_at)
+VALUES (
+    sqlc.arg(user_id)
+    ,sqlc.arg(chirp_id)
+    ,sqlc.arg(now)
+)
+ON CONFLICT DO NOTHING;
+
+-- name: UnpinChirp :exec
+DELETE FROM pinned_chirps
+WHERE user_id = ?
+    AND chirp_id = ?;
+
+-- name: GetPinnedChirpIDs :many
+SELECT chirp_id
+FROM pinned_chirps
+WHERE user_id = ?
+ORDER BY created_at;
\ No newline at end of file
@@ -0,0 +1,34 @@
+-- name: CreateRefreshToken :exec
+INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at)
+VALUES (
+    sqlc.arg(token)
+    ,sqlc.arg(now)
+    ,sqlc.arg(now)
+    ,sqlc.arg(user_id)
+    ,sqlc.arg(expires_at)
+);
+
+-- name: GetRefreshToken :one
+SELECT *
+FROM refresh_tokens
+WHERE token = ?;
+
+-- name: GetUserFromRefreshToken :one
+SELECT user_id
+FROM refresh_tokens
+WHERE revoked_at IS NULL
+    AND expires_at > sqlc.arg(now)
+    AND token = sqlc.arg(token);
+
+-- name: RevokeRefreshToken :exec
+UPDATE refresh_tokens
+SET updated_at = sqlc.arg(now)
+    ,revoked_at = sqlc.arg(now)
+WHERE token = sqlc.arg(token);
+
+-- name: RevokeAllRefreshTokensForUser :exec
+UPDATE refresh_tokens
+SET updated_at = sqlc.arg(now)
+    ,revoked_at = sqlc.arg(now)
+WHERE user_id = sqlc.arg(user_id)
+    AND revoked_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,62 @@
+-- name: CreateReport :exec
+INSERT INTO reports (id, created_at, updated_at, reporter_id, target_user_id, target_chirp_id, reason, details)
+VALUES (
+    sqlc.arg(id)
+    ,sqlc.arg(now)
+    ,sqlc.arg(now)
+    ,sqlc.narg(reporter_id)
+    ,sqlc.arg(target_user_id)
+    ,sqlc.narg(target_chirp_id)
+    ,sqlc.arg(reason)
+    ,sqlc.arg(details)
+);
+
+-- name: GetReport :one
+SELECT *
+FROM reports
+WHERE id = ?;
+
+-- name: GetReports :many
+SELECT *
+FROM reports
+ORDER BY created_at
+LIMIT ?
+OFFSET ?;
+
+-- name: GetReportsByState :many
+SELECT *
+FROM reports
+WHERE state = ?
+ORDER BY created_at
+LIMIT ?
+OFFSET ?;
+
+-- name: CloseReport :execrows
+UPDATE reports
+SET updated_at = sqlc.arg(now)
+    ,state = sqlc.arg(state)
+WHERE id = sqlc.arg(id)
+    AND state = 'open';
+
+-- name: CreateModerationAction :exec
+INSERT INTO moderation_actions (id, created_at, report_id, target_user_id, moderator_id, action, reason)
+VALUES (
+    sqlc.arg(id)
+    ,sqlc.arg(now)
+    ,sqlc.narg(report_id)
+    ,sqlc.arg(target_user_id)
+    ,sqlc.narg(moderator_id)
+    ,sqlc.arg(action)
+    ,sqlc.arg(reason)
+);
+
+-- name: GetModerationAction :one
+SELECT *
+FROM moderation_actions
+WHERE id = ?;
+
+-- name: GetModerationActionsForReport :many
+SELECT *
+FROM moderation_actions
+WHERE report_id = ?
+ORDER BY created_at;
\ No newline at end of file
@@ -0,0 +1,63 @@
+-- name: ActivateSubscription :exec
+INSERT INTO subscriptions (user_id, created_at, updated_at, plan, status, current_period_end)
+VALUES (
+    sqlc.arg(user_id)
+    ,sqlc.arg(now)
+    ,sqlc.arg(now)
+    ,sqlc.arg(plan)
+    ,'active'
+    ,sqlc.arg(current_period_end)
+)
+ON CONFLICT (user_id) DO UPDATE
+SET updated_at = excluded.updated_at
+    ,plan = excluded.plan
+    ,status = 'active'
+    ,current_period_end = excluded.current_period_end
+    ,grace_period_end = NULL;
+
+-- name: MarkSubscriptionPastDue :exec
+UPDATE subscriptions
+SET updated_at = sqlc.arg(now)
+    ,status = 'past_due'
+    ,grace_period_end = strftime('%Y-%m-%d %H:%M:%f+00:00', max(current_period_end, sqlc.arg(now)), '+7 days')
+WHERE user_id = sqlc.arg(user_id)
+    AND status = 'active';
+
+-- name: CancelSubscription :exec
+UPDATE subscriptions
+SET updated_at = sqlc.arg(now)
+    ,status = 'canceled'
+WHERE user_id = sqlc.arg(user_id)
+    AND status IN ('active', 'past_due');
+
+-- name: ExpireSubscription :exec
+UPDATE subscriptions
+SET updated_at = sqlc.arg(now)
+    ,status = 'expired'
+    ,grace_period_end = NULL
+WHERE user_id = sqlc.arg(user_id);
+
+-- name: ExpireSubscriptions :execrows
+UPDATE subscriptions
+SET updated_at = sqlc.arg(now)
+    ,status = CASE WHEN status = 'active' THEN 'past_due' ELSE 'expired' END
+    ,grace_period_end = CASE
+        WHEN status = 'active' THEN strftime('%Y-%m-%d %H:%M:%f+00:00', current_period_end, '+7 days')
+        ELSE grace_period_end
+    END
+WHERE (status = 'active' AND current_period_end <= sqlc.arg(now))
+    OR (status = 'past_due' AND grace_period_end <= sqlc.arg(now))
+    OR (status = 'canceled' AND current_period_end <= sqlc.arg(now));
+
+-- name: GetSubscription :one
+SELECT *
+FROM subscriptions
+WHERE user_id = ?;
+
+-- name: IsChirpyRed :one
+SELECT CAST(EXISTS (
+    SELECT 1
+    FROM subscriptions
+    WHERE user_id = ?
+        AND status <> 'expired'
+) AS BOOLEAN);
\ No newline at end of file
@@ -0,0 +1,106 @@
+-- name: CreateUser :exec
+INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+VALUES (
+    sqlc.arg(id)
+    ,sqlc.arg(now)
+    ,sqlc.arg(now)
+    ,sqlc.arg(email)
+    ,sqlc.arg(hashed_password)
+);
+
+-- name: ResetUsers :exec
+DELETE FROM users;
+
+-- name: GetUser :one
+SELECT *
+FROM users
+WHERE id = ?;
+
+-- name: GetUserWithEmail :one
+SELECT *
+FROM users
+WHERE email = ?;
+
+-- name: UpdateUser :execrows
+UPDATE users
+SET updated_at = sqlc.arg(now)
+    ,email = sqlc.arg(email)
+    ,hashed_password = sqlc.arg(hashed_password)
+WHERE id = sqlc.arg(id);
+
+-- name: UpdateUserProtected :exec
+UPDATE users
+SET updated_at = sqlc.arg(now)
+    ,protected = sqlc.arg(protected)
+WHERE id = sqlc.arg(id);
+
+-- name: ScheduleUserDeletion :execrows
+UPDATE users
+SET updated_at = sqlc.arg(now)
+    ,deletion_scheduled_at = sqlc.arg(deletion_scheduled_at)
+WHERE id = sqlc.arg(id);
+
+-- name: CancelUserDeletion :exec
+UPDATE users
+SET updated_at = sqlc.arg(now)
+    ,deletion_scheduled_at = NULL
+WHERE id = sqlc.arg(id)
+    AND deletion_scheduled_at IS NOT NULL;
+
+-- name: GetUsersDueForDeletion :many
+SELECT id, deletion_scheduled_at
+FROM users
+WHERE deletion_scheduled_at <= sqlc.arg(now);
+
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = sqlc.arg(id)
+    AND deletion_scheduled_at <= sqlc.arg(now);
+
+-- name: CreateAccountDeletion :exec
+INSERT INTO account_deletions (user_id, scheduled_at, deleted_at)
+VALUES (
+    sqlc.arg(user_id)
+    ,sqlc.arg(scheduled_at)
+    ,sqlc.arg(now)
+);
+
+-- name: GetUserRole :one
+SELECT role
+FROM users
+WHERE id = ?;
+
+-- name: UpdateUserRole :execrows
+UPDATE users
+SET updated_at = sqlc.arg(now)
+    ,role = sqlc.arg(role)
+WHERE id = sqlc.arg(id);
+
+-- name: UpdateUserRoleByEmail :execrows
+UPDATE users
+SET updated_at = sqlc.arg(now)
+    ,role = sqlc.arg(role)
+WHERE email = sqlc.arg(email);
+
+-- name: SuspendUser :exec
+UPDATE users
+SET updated_at = sqlc.arg(now)
+    ,suspension = sqlc.arg(suspension)
+    ,suspended_until = sqlc.narg(suspended_until)
+WHERE id = sqlc.arg(id);
+
+-- name: GetActiveSuspension :one
+SELECT active.suspension
+FROM users
+LEFT JOIN users AS active
+    ON active.id = users.id
+    AND (active.suspended_until IS NULL OR active.suspended_until > sqlc.arg(now))
+WHERE users.id = sqlc.arg(id);
+
+-- name: LiftSuspension :execrows
+UPDATE users
+SET updated_at = sqlc.arg(now)
+    ,suspension = NULL
+    ,suspended_until = NULL
+WHERE id = sqlc.arg(id)
+    AND suspension IS NOT NULL;
\ No newline at end of file
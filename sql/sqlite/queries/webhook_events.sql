@@ -0,0 +1,56 @@
+-- name: CreateWebhookEvent :execrows
+INSERT INTO webhook_events (id, created_at, updated_at, provider, event_id, event_type, status, payload)
+VALUES (
+    sqlc.arg(id)
+    ,sqlc.arg(now)
+    ,sqlc.arg(now)
+    ,sqlc.arg(provider)
+    ,sqlc.arg(event_id)
+    ,sqlc.arg(event_type)
+    ,'received'
+    ,sqlc.arg(payload)
+)
+ON CONFLICT (provider, event_id) DO NOTHING;
+
+-- name: GetWebhookEvent :one
+SELECT *
+FROM webhook_events
+WHERE id = ?;
+
+-- name: GetWebhookEventByEventID :one
+SELECT *
+FROM webhook_events
+WHERE provider = ?
+    AND event_id = ?;
+
+-- name: GetWebhookEvents :many
+SELECT *
+FROM webhook_events
+ORDER BY created_at DESC
+LIMIT ?
+OFFSET ?;
+
+-- name: GetWebhookEventsByStatus :many
+SELECT *
+FROM webhook_events
+WHERE status = ?
+ORDER BY created_at DESC
+LIMIT ?
+OFFSET ?;
+
+-- name: MarkWebhookEventProcessed :exec
+UPDATE webhook_events
+SET updated_at = sqlc.arg(now)
+    ,status = sqlc.arg(status)
+    ,attempts = attempts + 1
+    ,last_error = NULL
+    ,processed_at = sqlc.arg(now)
+WHERE id = sqlc.arg(id);
+
+-- name: MarkWebhookEventFailed :exec
+UPDATE webhook_events
+SET updated_at = sqlc.arg(now)
+    ,status = 'failed'
+    ,attempts = attempts + 1
+    ,last_error = sqlc.arg(last_error)
+WHERE id = sqlc.arg(id);
\ No newline at end of file
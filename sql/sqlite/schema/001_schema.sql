@@ -0,0 +1,236 @@
+-- +goose Up
+CREATE TABLE users (
+    id UUID NOT NULL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,updated_at TIMESTAMP NOT NULL
+    ,email TEXT NOT NULL
+    ,hashed_password TEXT NOT NULL DEFAULT 'unset'
+    ,protected BOOLEAN NOT NULL DEFAULT false
+    ,deletion_scheduled_at TIMESTAMP
+    ,role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'moderator', 'admin'))
+    ,suspension TEXT CHECK (suspension IN ('suspended', 'read_only', 'shadow_limited'))
+    ,suspended_until TIMESTAMP
+);
+
+CREATE TABLE chirps (
+    id UUID NOT NULL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,updated_at TIMESTAMP NOT NULL
+    ,body TEXT NOT NULL
+    ,user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+);
+
+CREATE TABLE refresh_tokens (
+    token TEXT NOT NULL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,updated_at TIMESTAMP NOT NULL
+    ,user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+    ,expires_at TIMESTAMP NOT NULL
+    ,revoked_at TIMESTAMP
+);
+
+CREATE TABLE bookmarks (
+    user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+    ,chirp_id UUID NOT NULL REFERENCES chirps ON DELETE CASCADE
+    ,created_at TIMESTAMP NOT NULL
+    ,PRIMARY KEY (user_id, chirp_id)
+);
+
+CREATE TABLE pinned_chirps (
+    user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+    ,chirp_id UUID NOT NULL REFERENCES chirps ON DELETE CASCADE
+    ,created_at TIMESTAMP NOT NULL
+    ,PRIMARY KEY (user_id, chirp_id)
+);
+
+CREATE TABLE blocks (
+    blocker_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+    ,blocked_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+    ,created_at TIMESTAMP NOT NULL
+    ,PRIMARY KEY (blocker_id, blocked_id)
+);
+
+CREATE TABLE mutes (
+    muter_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+    ,muted_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+    ,created_at TIMESTAMP NOT NULL
+    ,PRIMARY KEY (muter_id, muted_id)
+);
+
+CREATE TABLE follows (
+    follower_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+    ,followee_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+    ,status TEXT NOT NULL CHECK (status IN ('pending', 'accepted'))
+    ,created_at TIMESTAMP NOT NULL
+    ,updated_at TIMESTAMP NOT NULL
+    ,PRIMARY KEY (follower_id, followee_id)
+);
+
+CREATE TABLE account_deletions (
+    user_id UUID NOT NULL PRIMARY KEY
+    ,scheduled_at TIMESTAMP NOT NULL
+    ,deleted_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE data_exports (
+    id UUID NOT NULL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,updated_at TIMESTAMP NOT NULL
+    ,user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+    ,status TEXT NOT NULL CHECK (status IN ('pending', 'ready', 'failed'))
+    ,file_path TEXT
+    ,expires_at TIMESTAMP
+);
+
+CREATE TABLE webhook_events (
+    id UUID NOT NULL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,updated_at TIMESTAMP NOT NULL
+    ,provider TEXT NOT NULL
+    ,event_id TEXT NOT NULL
+    ,event_type TEXT NOT NULL
+    ,status TEXT NOT NULL CHECK (status IN ('received', 'processed', 'ignored', 'failed'))
+    ,attempts INTEGER NOT NULL DEFAULT 0
+    ,payload JSON NOT NULL
+    ,last_error TEXT
+    ,processed_at TIMESTAMP
+    ,UNIQUE (provider, event_id)
+);
+
+CREATE TABLE subscriptions (
+    user_id UUID NOT NULL PRIMARY KEY REFERENCES users ON DELETE CASCADE
+    ,created_at TIMESTAMP NOT NULL
+    ,updated_at TIMESTAMP NOT NULL
+    ,plan TEXT NOT NULL
+    ,status TEXT NOT NULL CHECK (status IN ('active', 'past_due', 'canceled', 'expired'))
+    ,current_period_end TIMESTAMP NOT NULL
+    ,grace_period_end TIMESTAMP
+);
+
+CREATE TABLE entitlement_overrides (
+    user_id UUID NOT NULL PRIMARY KEY REFERENCES users ON DELETE CASCADE
+    ,created_at TIMESTAMP NOT NULL
+    ,updated_at TIMESTAMP NOT NULL
+    ,overrides JSON NOT NULL
+);
+
+-- events is a JSON array of event types, as SQLite has no arrays.
+CREATE TABLE webhook_endpoints (
+    id UUID NOT NULL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,updated_at TIMESTAMP NOT NULL
+    ,user_id UUID REFERENCES users ON DELETE CASCADE
+    ,url TEXT NOT NULL
+    ,secret TEXT NOT NULL
+    ,events JSON NOT NULL
+    ,active BOOLEAN NOT NULL DEFAULT true
+);
+
+CREATE TABLE outbox_events (
+    id UUID NOT NULL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,event_type TEXT NOT NULL
+    ,user_id UUID REFERENCES users ON DELETE CASCADE
+    ,payload JSON NOT NULL
+    ,dispatched_at TIMESTAMP
+    ,traceparent TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX outbox_events_undispatched_idx ON outbox_events (created_at) WHERE dispatched_at IS NULL;
+
+CREATE TABLE webhook_deliveries (
+    id UUID NOT NULL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,updated_at TIMESTAMP NOT NULL
+    ,endpoint_id UUID NOT NULL REFERENCES webhook_endpoints ON DELETE CASCADE
+    ,event_id UUID NOT NULL REFERENCES outbox_events ON DELETE CASCADE
+    ,status TEXT NOT NULL CHECK (status IN ('pending', 'succeeded', 'dead'))
+    ,attempts INTEGER NOT NULL DEFAULT 0
+    ,next_attempt_at TIMESTAMP NOT NULL
+    ,last_status_code INTEGER
+    ,last_error TEXT
+    ,delivered_at TIMESTAMP
+);
+
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE reports (
+    id UUID NOT NULL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,updated_at TIMESTAMP NOT NULL
+    ,reporter_id UUID REFERENCES users ON DELETE SET NULL
+    ,target_user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+    ,target_chirp_id UUID REFERENCES chirps ON DELETE SET NULL
+    ,reason TEXT NOT NULL CHECK (reason IN ('spam', 'harassment', 'hate', 'violence', 'sexual', 'self_harm', 'impersonation', 'other'))
+    ,details TEXT NOT NULL
+    ,state TEXT NOT NULL DEFAULT 'open' CHECK (state IN ('open', 'resolved', 'dismissed'))
+);
+
+CREATE INDEX reports_state_idx ON reports (state, created_at);
+
+CREATE TABLE moderation_actions (
+    id UUID NOT NULL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,report_id UUID REFERENCES reports ON DELETE CASCADE
+    ,moderator_id UUID REFERENCES users ON DELETE SET NULL
+    ,action TEXT NOT NULL CHECK (action IN ('dismiss', 'delete_chirp', 'warn', 'suspend', 'read_only', 'shadow_limit', 'lift_suspension'))
+    ,reason TEXT NOT NULL
+    ,target_user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+);
+
+CREATE TABLE notifications (
+    id UUID NOT NULL PRIMARY KEY
+    ,created_at TIMESTAMP NOT NULL
+    ,user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE
+    ,kind TEXT NOT NULL
+    ,body TEXT NOT NULL
+    ,read_at TIMESTAMP
+);
+
+CREATE TABLE audit_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT
+    ,created_at TIMESTAMP NOT NULL
+    ,action TEXT NOT NULL
+    ,actor_id UUID
+    ,target_type TEXT NOT NULL
+    ,target_id TEXT NOT NULL
+    ,ip TEXT NOT NULL
+    ,user_agent TEXT NOT NULL
+    ,request_id TEXT NOT NULL
+    ,metadata JSON NOT NULL
+    ,prev_hash TEXT NOT NULL
+    ,hash TEXT NOT NULL
+);
+
+CREATE INDEX audit_events_created_at_idx ON audit_events (created_at);
+CREATE INDEX audit_events_actor_id_idx ON audit_events (actor_id);
+
+-- +goose StatementBegin
+CREATE TRIGGER audit_events_no_update
+BEFORE UPDATE ON audit_events
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE audit_events;
+DROP TABLE notifications;
+DROP TABLE moderation_actions;
+DROP TABLE reports;
+DROP TABLE webhook_deliveries;
+DROP TABLE outbox_events;
+DROP TABLE webhook_endpoints;
+DROP TABLE entitlement_overrides;
+DROP TABLE subscriptions;
+DROP TABLE webhook_events;
+DROP TABLE data_exports;
+DROP TABLE account_deletions;
+DROP TABLE follows;
+DROP TABLE mutes;
+DROP TABLE blocks;
+DROP TABLE pinned_chirps;
+DROP TABLE bookmarks;
+DROP TABLE refresh_tokens;
+DROP TABLE chirps;
+DROP TABLE users;
\ No newline at end of file
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE audit_chain (
+    id INTEGER NOT NULL PRIMARY KEY CHECK (id = 1)
+    ,head_hash TEXT NOT NULL
+    ,pruning BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+INSERT INTO audit_chain (id, head_hash)
+VALUES (1, COALESCE((SELECT hash FROM audit_events ORDER BY id DESC LIMIT 1), ''));
+
+-- +goose StatementBegin
+CREATE TRIGGER audit_events_no_delete
+BEFORE DELETE ON audit_events
+WHEN NOT (SELECT pruning FROM audit_chain)
+BEGIN
+    SELECT RAISE(ABORT, 'audit_events can only be deleted by the retention policy');
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER audit_events_no_delete;
+DROP TABLE audit_chain;
\ No newline at end of file
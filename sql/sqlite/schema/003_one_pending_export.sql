@@ -0,0 +1,20 @@
+-- +goose Up
+UPDATE data_exports
+SET updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+    ,status = 'failed'
+WHERE status = 'pending'
+    AND EXISTS (
+        SELECT 1
+        FROM data_exports AS newer
+        WHERE newer.user_id = data_exports.user_id
+            AND newer.status = 'pending'
+            AND (
+                newer.created_at > data_exports.created_at
+                OR (newer.created_at = data_exports.created_at AND newer.id > data_exports.id)
+            )
+    );
+
+CREATE UNIQUE INDEX data_exports_one_pending ON data_exports (user_id) WHERE status = 'pending';
+
+-- +goose Down
+DROP INDEX data_exports_one_pending;
\ No newline at end of file
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+// suspensionStates maps the moderation actions that restrict an account to
+// the state they put it in:
+//
+//   - suspended: can't log in or refresh, and their chirps are hidden.
+//   - read_only: can log in and read but not write.
+//   - shadow_limited: everything works, but their chirps are only visible
+//     to themselves.
+//
+// Each state is temporary when it has an expiry and permanent otherwise.
+var suspensionStates = map[string]string{
+	"suspend":      "suspended",
+	"read_only":    "read_only",
+	"shadow_limit": "shadow_limited",
+}
+
+// restrictUser puts the user into the state for action and returns the
+// notice to send them, which is empty for a shadow limit.
+func restrictUser(ctx context.Context, q database.Querier, userID uuid.UUID, action string, durationHours *int32) (string, error) {
+	state := suspensionStates[action]
+
+	duration := sql.NullInt32{}
+	if durationHours != nil {
+		duration = sql.NullInt32{Int32: *durationHours, Valid: true}
+	}
+
+	err := q.SuspendUser(ctx, database.SuspendUserParams{
+		Suspension:    sql.NullString{String: state, Valid: true},
+		DurationHours: duration,
+		ID:            userID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var notice string
+	switch state {
+	case "suspended":
+		err = q.RevokeAllRefreshTokensForUser(ctx, userID)
+		if err != nil {
+			return "", err
+		}
+		notice = "Your account has been suspended"
+	case "read_only":
+		notice = "Your account has been made read-only"
+	case "shadow_limited":
+		return "", nil
+	}
+
+	if durationHours != nil {
+		notice = fmt.Sprintf("%s for %d hours", notice, *durationHours)
+	}
+	return notice, nil
+}
+
+// checkCanWrite writes a 403 and returns false if the user is suspended or
+// read-only. Write handlers call it straight after authenticating, since a
+// JWT issued before the suspension stays valid until it expires. Blocks and
+// mutes deliberately don't: they only change what the user sees and who can
+// reach them, and a restricted user may still need them to stay safe.
+func (cfg *apiConfig) checkCanWrite(w http.ResponseWriter, r *http.Request, userID uuid.UUID) bool {
+	suspension, err := cfg.db.GetActiveSuspension(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return false
+	}
+
+	switch suspension.String {
+	case "suspended":
+		respondWithError(w, 403, "Account suspended")
+		return false
+	case "read_only":
+		respondWithError(w, 403, "Account is read-only")
+		return false
+	}
+	return true
+}
+
+func (cfg *apiConfig) putUserSuspension(w http.ResponseWriter, r *http.Request) {
+	type suspensionParams struct {
+		Action        string `json:"action"`
+		Reason        string `json:"reason"`
+		DurationHours *int32 `json:"duration_hours"`
+	}
+
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := suspensionParams{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Invalid request")
+		return
+	}
+
+	if _, ok := suspensionStates[params.Action]; !ok {
+		respondWithError(w, 400, "Unknown action")
+		return
+	}
+	if params.Reason == "" {
+		respondWithError(w, 400, "A reason is required")
+		return
+	}
+	if params.DurationHours != nil && *params.DurationHours <= 0 {
+		respondWithError(w, 400, "duration_hours must be positive")
+		return
+	}
+	if userID == actorID(r) {
+		respondWithError(w, 400, "Cannot restrict yourself")
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context())
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	target, err := tx.GetUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 404, "User not found")
+		return
+	}
+	if !outranks(actorRole(r), target.Role) {
+		respondWithError(w, 403, "Cannot restrict a user whose role is not below yours")
+		return
+	}
+
+	notice, err := restrictUser(r.Context(), tx, userID, params.Action, params.DurationHours)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	action, err := tx.CreateModerationAction(r.Context(), database.CreateModerationActionParams{
+		TargetUserID: userID,
+		ModeratorID:  uuid.NullUUID{UUID: actorID(r), Valid: true},
+		Action:       params.Action,
+		Reason:       params.Reason,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	err = appendAudit(r.Context(), tx, r, auditRecord{
+		Action:     "moderation." + params.Action,
+		ActorID:    actorID(r),
+		TargetType: "user",
+		TargetID:   userID.String(),
+		Metadata:   map[string]any{"reason": params.Reason, "duration_hours": params.DurationHours},
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	if notice != "" {
+		err = tx.CreateNotification(r.Context(), database.CreateNotificationParams{
+			UserID: userID,
+			Kind:   "moderation",
+			Body:   fmt.Sprintf("%s: %s", notice, params.Reason),
+		})
+		if err != nil {
+			respondWithError(w, 500, err.Error())
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 201, action)
+}
+
+func (cfg *apiConfig) deleteUserSuspension(w http.ResponseWriter, r *http.Request) {
+	type liftParams struct {
+		Reason string `json:"reason"`
+	}
+
+	userID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := liftParams{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, 400, "Invalid request")
+		return
+	}
+	if params.Reason == "" {
+		respondWithError(w, 400, "A reason is required")
+		return
+	}
+	if userID == actorID(r) {
+		respondWithError(w, 400, "Cannot lift your own restriction")
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(r.Context())
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	target, err := tx.GetUser(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 404, "User not found")
+		return
+	}
+	if !outranks(actorRole(r), target.Role) {
+		respondWithError(w, 403, "Cannot lift the restriction of a user whose role is not below yours")
+		return
+	}
+
+	n, err := tx.LiftSuspension(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	if n == 0 {
+		respondWithError(w, 404, "User is not restricted")
+		return
+	}
+
+	action, err := tx.CreateModerationAction(r.Context(), database.CreateModerationActionParams{
+		TargetUserID: userID,
+		ModeratorID:  uuid.NullUUID{UUID: actorID(r), Valid: true},
+		Action:       "lift_suspension",
+		Reason:       params.Reason,
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	err = appendAudit(r.Context(), tx, r, auditRecord{
+		Action:     "moderation.lift_suspension",
+		ActorID:    actorID(r),
+		TargetType: "user",
+		TargetID:   userID.String(),
+		Metadata:   map[string]string{"reason": params.Reason},
+	})
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 201, action)
+}
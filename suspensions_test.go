@@ -0,0 +1,120 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSuspension(t *testing.T) {
+	a := newTestAPI(t)
+	mod := a.signup("mod")
+	a.setRole(mod, roleModerator)
+	bob := a.signup("bob")
+
+	path := "/admin/users/" + bob.ID.String() + "/suspension"
+	a.expect(403, "PUT", path, bob.Token, map[string]any{"action": "suspend", "reason": "spam"})
+	a.expect(400, "PUT", path, mod.Token, map[string]any{"action": "banish", "reason": "spam"})
+	a.expect(400, "PUT", path, mod.Token, map[string]any{"action": "suspend"})
+	a.expect(400, "PUT", path, mod.Token, map[string]any{"action": "suspend", "reason": "spam", "duration_hours": 0})
+	a.expect(400, "PUT", "/admin/users/"+mod.ID.String()+"/suspension", mod.Token, map[string]any{"action": "suspend", "reason": "spam"})
+	a.expect(404, "PUT", "/admin/users/"+uuid.NewString()+"/suspension", mod.Token, map[string]any{"action": "suspend", "reason": "spam"})
+	a.expect(404, "DELETE", path, mod.Token, map[string]any{"reason": "appeal"})
+
+	a.expect(201, "PUT", path, mod.Token, map[string]any{"action": "suspend", "reason": "spam", "duration_hours": 24})
+	a.expect(403, "POST", "/api/login", "", map[string]string{"email": bob.Email, "password": "hunter2"})
+	// Suspending revokes refresh tokens, and the JWT can no longer write.
+	a.expect(401, "POST", "/api/refresh", bob.RefreshToken, nil)
+	a.expect(403, "POST", "/api/chirps", bob.Token, map[string]string{"body": "let me out"})
+
+	a.expect(400, "DELETE", path, mod.Token, map[string]any{})
+	a.expect(201, "DELETE", path, mod.Token, map[string]any{"reason": "appeal"})
+	a.expect(200, "POST", "/api/login", "", map[string]string{"email": bob.Email, "password": "hunter2"})
+
+	a.expect(201, "PUT", path, mod.Token, map[string]any{"action": "read_only", "reason": "cool off"})
+	a.expect(200, "POST", "/api/login", "", map[string]string{"email": bob.Email, "password": "hunter2"})
+	a.expect(403, "POST", "/api/chirps", bob.Token, map[string]string{"body": "still here"})
+	a.expectAudited("moderation.read_only")
+
+	// Only users below the moderator's role can be restricted.
+	admin := a.signup("admin")
+	a.setRole(admin, roleAdmin)
+	mod2 := a.signup("mod2")
+	a.setRole(mod2, roleModerator)
+	a.expect(403, "PUT", "/admin/users/"+admin.ID.String()+"/suspension", mod.Token, map[string]any{"action": "suspend", "reason": "coup"})
+	a.expect(403, "PUT", "/admin/users/"+mod2.ID.String()+"/suspension", mod.Token, map[string]any{"action": "read_only", "reason": "rival"})
+	a.expect(200, "POST", "/api/refresh", admin.RefreshToken, nil)
+	a.expect(201, "PUT", "/admin/users/"+mod2.ID.String()+"/suspension", admin.Token, map[string]any{"action": "read_only", "reason": "abuse"})
+}
+
+func TestLiftSuspension(t *testing.T) {
+	a := newTestAPI(t)
+	admin := a.signup("admin")
+	a.setRole(admin, roleAdmin)
+	mod := a.signup("mod")
+	a.setRole(mod, roleModerator)
+	mod2 := a.signup("mod2")
+	a.setRole(mod2, roleModerator)
+
+	path := "/admin/users/" + mod2.ID.String() + "/suspension"
+	a.expect(201, "PUT", path, admin.Token, map[string]any{"action": "read_only", "reason": "abuse"})
+
+	// A moderator can't lift a restriction a peer is under, nor their own.
+	a.expect(403, "DELETE", path, mod.Token, map[string]any{"reason": "mate"})
+	a.expect(400, "DELETE", "/admin/users/"+mod.ID.String()+"/suspension", mod.Token, map[string]any{"reason": "me"})
+	a.expect(404, "DELETE", "/admin/users/"+uuid.NewString()+"/suspension", mod.Token, map[string]any{"reason": "who"})
+
+	a.expect(201, "DELETE", path, admin.Token, map[string]any{"reason": "appeal"})
+	a.expectAudited("moderation.lift_suspension")
+}
+
+// TestRestrictedChirpsHidden checks that a suspended user's chirps are
+// hidden from everyone, and a shadow limited user's from everyone else.
+func TestRestrictedChirpsHidden(t *testing.T) {
+	a := newTestAPI(t)
+	mod := a.signup("mod")
+	a.setRole(mod, roleModerator)
+	alice := a.signup("alice")
+	bob := a.signup("bob")
+	carol := a.signup("carol")
+	a.chirp(alice, "visible")
+	bobChirp := a.chirp(bob, "too loud")
+	carolChirp := a.chirp(carol, "spam")
+
+	a.expect(201, "PUT", "/admin/users/"+bob.ID.String()+"/suspension", mod.Token, map[string]any{"action": "shadow_limit", "reason": "too loud"})
+	a.expect(201, "PUT", "/admin/users/"+carol.ID.String()+"/suspension", mod.Token, map[string]any{"action": "suspend", "reason": "spam"})
+
+	chirps := decode[[]returnChirp](t, a.expect(200, "GET", "/api/chirps", alice.Token, nil))
+	if len(chirps) != 1 || chirps[0].UserID != alice.ID {
+		t.Errorf("Want only alice's chirp, got %+v", chirps)
+	}
+	a.expect(404, "GET", "/api/chirps/"+bobChirp.ID.String(), alice.Token, nil)
+	a.expect(404, "GET", "/api/chirps/"+carolChirp.ID.String(), alice.Token, nil)
+
+	// To bob, nothing looks any different.
+	a.expect(200, "GET", "/api/chirps/"+bobChirp.ID.String(), bob.Token, nil)
+	chirps = decode[[]returnChirp](t, a.expect(200, "GET", "/api/chirps?author_id="+bob.ID.String(), bob.Token, nil))
+	if len(chirps) != 1 {
+		t.Errorf("Want bob's own chirp listed for bob, got %+v", chirps)
+	}
+	a.chirp(bob, "still talking")
+}
+
+// TestSuspensionExpires checks that a temporary suspension lapses on its
+// own, and a permanent one doesn't.
+func TestSuspensionExpires(t *testing.T) {
+	a := newTestAPI(t)
+	mod := a.signup("mod")
+	a.setRole(mod, roleModerator)
+	bob := a.signup("bob")
+	carol := a.signup("carol")
+
+	a.expect(201, "PUT", "/admin/users/"+bob.ID.String()+"/suspension", mod.Token, map[string]any{"action": "suspend", "reason": "spam", "duration_hours": 24})
+	a.expect(201, "PUT", "/admin/users/"+carol.ID.String()+"/suspension", mod.Token, map[string]any{"action": "suspend", "reason": "spam"})
+	a.expect(403, "POST", "/api/login", "", map[string]string{"email": bob.Email, "password": "hunter2"})
+
+	a.store.SetClock(func() time.Time { return time.Now().Add(25 * time.Hour) })
+	a.expect(200, "POST", "/api/login", "", map[string]string{"email": bob.Email, "password": "hunter2"})
+	a.expect(403, "POST", "/api/login", "", map[string]string{"email": carol.Email, "password": "hunter2"})
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/joshckidd/chirpy/internal/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// middlewareTracing starts a server span for each request, continuing the
+// caller's trace when it sent a traceparent header. Handlers and the
+// queries they run pick the span up from the request's context.
+func (cfg *apiConfig) middlewareTracing(next http.Handler) http.Handler {
+	return otelhttp.NewHandler(next, "http.server",
+		otelhttp.WithTracerProvider(cfg.tracerProvider),
+		otelhttp.WithPropagators(tracing.Propagator),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method
+		}),
+	)
+}
+
+// nameSpan names the server span after the route rather than the path, so
+// IDs in the URL don't make every span name unique.
+func nameSpan(r *http.Request, route string) {
+	span := trace.SpanFromContext(r.Context())
+	span.SetName(r.Method + " " + route)
+	span.SetAttributes(semconv.HTTPRoute(route))
+}
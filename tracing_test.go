@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// serverSpan returns the one server span exported so far.
+func (a *testAPI) serverSpan() tracetest.SpanStub {
+	a.t.Helper()
+
+	var found []tracetest.SpanStub
+	for _, s := range a.spans.GetSpans() {
+		if s.SpanKind == trace.SpanKindServer {
+			found = append(found, s)
+		}
+	}
+	if len(found) != 1 {
+		a.t.Fatalf("Want one server span, got %d", len(found))
+	}
+	return found[0]
+}
+
+func TestTracingContinuesTrace(t *testing.T) {
+	a := newTestAPI(t)
+	alice := a.signup("alice")
+	chirp := a.chirp(alice, "hello")
+	a.spans.Reset()
+
+	req := httptest.NewRequest("GET", "/api/chirps/"+chirp.ID.String(), nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := a.serve(req)
+	if rec.Code != 200 {
+		t.Fatalf("Want 200, got %d", rec.Code)
+	}
+
+	span := a.serverSpan()
+	if span.Name != "GET /api/chirps/{chirpID}" {
+		t.Errorf("Want the span named after the route, got %q", span.Name)
+	}
+	if span.SpanContext.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || span.Parent.SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("Want the span to continue the caller's trace, got %v with parent %v", span.SpanContext.TraceID(), span.Parent.SpanID())
+	}
+	route := ""
+	for _, kv := range span.Attributes {
+		if kv.Key == "http.route" {
+			route = kv.Value.AsString()
+		}
+	}
+	if route != "/api/chirps/{chirpID}" {
+		t.Errorf("Want the http.route attribute, got %q", route)
+	}
+}
+
+func TestTracingUnmatchedRoute(t *testing.T) {
+	a := newTestAPI(t)
+
+	a.expect(404, "GET", "/api/nothing-here", "", nil)
+	if span := a.serverSpan(); span.Name != "GET" {
+		t.Errorf("Want an unmatched request named after its method, got %q", span.Name)
+	}
+}
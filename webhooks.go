@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+// recordWebhookEvent stores a new event and applies it in one
+// transaction, so a crash or cancelled request leaves no row behind and
+// the provider's retry starts afresh. An event that fails to apply is
+// stored as failed, for the next retry or an admin to replay. It returns
+// sql.ErrNoRows if the event has already been stored.
+func (cfg *apiConfig) recordWebhookEvent(ctx context.Context, params database.CreateWebhookEventParams) error {
+	tx, err := cfg.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	event, err := tx.CreateWebhookEvent(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	status, err := applyPolkaEvent(ctx, tx, event.Payload)
+	if err != nil {
+		tx.Rollback()
+		failed, createErr := cfg.db.CreateWebhookEvent(ctx, params)
+		if createErr != nil {
+			// A concurrent delivery of the same event got there first.
+			if errors.Is(createErr, sql.ErrNoRows) {
+				return err
+			}
+			return createErr
+		}
+		markErr := cfg.db.MarkWebhookEventFailed(ctx, database.MarkWebhookEventFailedParams{
+			LastError: sql.NullString{String: err.Error(), Valid: true},
+			ID:        failed.ID,
+		})
+		if markErr != nil {
+			return markErr
+		}
+		return err
+	}
+
+	err = tx.MarkWebhookEventProcessed(ctx, database.MarkWebhookEventProcessedParams{
+		Status: status,
+		ID:     event.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// processWebhookEvent retries a stored event and records the outcome. The
+// change and the processed status commit together, so a crash in between
+// leaves the event to be retried rather than half applied.
+func (cfg *apiConfig) processWebhookEvent(ctx context.Context, event database.WebhookEvent) error {
+	tx, err := cfg.db.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	status, err := applyPolkaEvent(ctx, tx, event.Payload)
+	if err != nil {
+		tx.Rollback()
+		markErr := cfg.db.MarkWebhookEventFailed(ctx, database.MarkWebhookEventFailedParams{
+			LastError: sql.NullString{String: err.Error(), Valid: true},
+			ID:        event.ID,
+		})
+		if markErr != nil {
+			return markErr
+		}
+		return err
+	}
+
+	err = tx.MarkWebhookEventProcessed(ctx, database.MarkWebhookEventProcessedParams{
+		Status: status,
+		ID:     event.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// retryableWebhookEvent reports whether a stored event can be applied
+// again. Events are only committed once applied or failed, so one still
+// received was left behind by an earlier release that stored it first.
+func retryableWebhookEvent(event database.WebhookEvent) bool {
+	return event.Status == "failed" || event.Status == "received"
+}
+
+// applyPolkaEvent moves the user's subscription through its lifecycle. It
+// returns "processed" for events that changed something and "ignored" for
+// event types Chirpy doesn't act on.
+func applyPolkaEvent(ctx context.Context, q database.Querier, payload []byte) (string, error) {
+	type dataParams struct {
+		UserID           uuid.UUID  `json:"user_id"`
+		Plan             string     `json:"plan"`
+		CurrentPeriodEnd *time.Time `json:"current_period_end"`
+	}
+
+	type redParams struct {
+		Event string     `json:"event"`
+		Data  dataParams `json:"data"`
+	}
+
+	inParams := redParams{}
+	err := json.Unmarshal(payload, &inParams)
+	if err != nil {
+		return "", err
+	}
+
+	userID := inParams.Data.UserID
+
+	switch inParams.Event {
+	case "user.upgraded", "subscription.renewed", "payment.succeeded":
+		plan := inParams.Data.Plan
+		if plan == "" {
+			plan = "chirpy_red"
+		}
+		periodEnd := sql.NullTime{}
+		if inParams.Data.CurrentPeriodEnd != nil {
+			periodEnd = sql.NullTime{Time: inParams.Data.CurrentPeriodEnd.UTC(), Valid: true}
+		}
+		err = q.ActivateSubscription(ctx, database.ActivateSubscriptionParams{
+			UserID:           userID,
+			Plan:             plan,
+			CurrentPeriodEnd: periodEnd,
+		})
+		if err == nil && inParams.Event == "user.upgraded" {
+			err = enqueueEvent(ctx, q, "user.upgraded", userID, map[string]any{
+				"user_id": userID,
+				"plan":    plan,
+			})
+		}
+	case "payment.failed":
+		err = q.MarkSubscriptionPastDue(ctx, userID)
+	case "subscription.canceled":
+		err = q.CancelSubscription(ctx, userID)
+	case "user.downgraded", "subscription.expired":
+		err = q.ExpireSubscription(ctx, userID)
+	default:
+		return "ignored", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	err = appendAudit(ctx, q, nil, auditRecord{
+		Action:     "billing.plan_changed",
+		TargetType: "user",
+		TargetID:   userID.String(),
+		Metadata:   map[string]string{"event": inParams.Event, "plan": inParams.Data.Plan},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return "processed", nil
+}
+
+// expireSubscriptions moves lapsed subscriptions into their grace period
+// and ends the ones whose grace period has run out.
+func (cfg *apiConfig) expireSubscriptions(ctx context.Context) error {
+	_, err := cfg.db.ExpireSubscriptions(ctx)
+	return err
+}
+
+func (cfg *apiConfig) getWebhookEvents(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := getPagination(r)
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	var events []database.WebhookEvent
+	status := r.URL.Query().Get("status")
+	if status != "" {
+		events, err = cfg.db.GetWebhookEventsByStatus(r.Context(), database.GetWebhookEventsByStatusParams{
+			Status: status,
+			Limit:  limit,
+			Offset: offset,
+		})
+	} else {
+		events, err = cfg.db.GetWebhookEvents(r.Context(), database.GetWebhookEventsParams{
+			Limit:  limit,
+			Offset: offset,
+		})
+	}
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+	if events == nil {
+		events = []database.WebhookEvent{}
+	}
+
+	respondWithJSON(w, 200, events)
+}
+
+func (cfg *apiConfig) replayWebhookEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("eventID"))
+	if err != nil {
+		respondWithError(w, 400, err.Error())
+		return
+	}
+
+	event, err := cfg.db.GetWebhookEvent(r.Context(), id)
+	if err != nil {
+		respondWithError(w, 404, "Webhook event not found")
+		return
+	}
+	if !retryableWebhookEvent(event) {
+		respondWithError(w, 409, "Only failed events can be replayed")
+		return
+	}
+
+	err = cfg.processWebhookEvent(r.Context(), event)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	cfg.audit(r, auditRecord{
+		Action:     "admin.webhook_replayed",
+		ActorID:    actorID(r),
+		TargetType: "webhook_event",
+		TargetID:   id.String(),
+	})
+
+	event, err = cfg.db.GetWebhookEvent(r.Context(), id)
+	if err != nil {
+		respondWithError(w, 500, err.Error())
+		return
+	}
+
+	respondWithJSON(w, 200, event)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joshckidd/chirpy/internal/database"
+)
+
+func TestWebhookEvents(t *testing.T) {
+	a := newTestAPI(t)
+	admin := a.signup("admin")
+	a.setRole(admin, roleAdmin)
+	bob := a.signup("bob")
+
+	// An event for a user that doesn't exist can't be applied.
+	rec := a.polka(map[string]any{"id": "evt_1", "event": "user.upgraded", "data": map[string]any{"user_id": uuid.New()}}, testPolkaSecret)
+	if rec.Code != 500 {
+		t.Fatalf("Want 500 for an event that can't be applied, got %d", rec.Code)
+	}
+	if rec := a.polka(map[string]any{"id": "evt_2", "event": "user.unknown"}, testPolkaSecret); rec.Code != 204 {
+		t.Fatalf("Want 204 for an ignored event, got %d", rec.Code)
+	}
+
+	a.expect(403, "GET", "/admin/webhooks/events", bob.Token, nil)
+	events := decode[[]database.WebhookEvent](t, a.expect(200, "GET", "/admin/webhooks/events", admin.Token, nil))
+	if len(events) != 2 {
+		t.Errorf("Want 2 events, got %+v", events)
+	}
+	events = decode[[]database.WebhookEvent](t, a.expect(200, "GET", "/admin/webhooks/events?status=failed", admin.Token, nil))
+	if len(events) != 1 || events[0].EventID != "evt_1" || !events[0].LastError.Valid {
+		t.Fatalf("Want the failed event, got %+v", events)
+	}
+	failed := events[0]
+	a.expect(400, "GET", "/admin/webhooks/events?limit=-1", admin.Token, nil)
+
+	a.expect(500, "POST", "/admin/webhooks/events/"+failed.ID.String()+"/replay", admin.Token, nil)
+	a.expect(404, "POST", "/admin/webhooks/events/"+uuid.NewString()+"/replay", admin.Token, nil)
+
+	// Fail an event for a real user, as if a database error had stopped it.
+	ctx := context.Background()
+	payload, _ := json.Marshal(map[string]any{"id": "evt_3", "event": "user.upgraded", "data": map[string]any{"user_id": bob.ID}})
+	event, err := a.store.CreateWebhookEvent(ctx, database.CreateWebhookEventParams{Provider: "polka", EventID: "evt_3", EventType: "user.upgraded", Payload: payload})
+	if err != nil {
+		t.Fatalf("Error creating event: %v", err)
+	}
+	err = a.store.MarkWebhookEventFailed(ctx, database.MarkWebhookEventFailedParams{LastError: sql.NullString{String: "connection reset", Valid: true}, ID: event.ID})
+	if err != nil {
+		t.Fatalf("Error failing event: %v", err)
+	}
+
+	path := "/admin/webhooks/events/" + event.ID.String() + "/replay"
+	replayed := decode[database.WebhookEvent](t, a.expect(200, "POST", path, admin.Token, nil))
+	if replayed.Status != "processed" {
+		t.Errorf("Want the replayed event processed, got %+v", replayed)
+	}
+	a.expect(409, "POST", path, admin.Token, nil)
+	a.expectAudited("admin.webhook_replayed")
+
+	red, err := a.store.IsChirpyRed(ctx, bob.ID)
+	if err != nil || !red {
+		t.Errorf("Want bob upgraded by the replay, got %v, %v", red, err)
+	}
+}
+
+// TestWebhookEventLeftReceived checks that an event stored but never
+// applied, as a crash could leave one, is applied when Polka retries it.
+func TestWebhookEventLeftReceived(t *testing.T) {
+	a := newTestAPI(t)
+	bob := a.signup("bob")
+
+	body := map[string]any{"id": "evt_1", "event": "user.upgraded", "data": map[string]any{"user_id": bob.ID}}
+	payload, _ := json.Marshal(body)
+	_, err := a.store.CreateWebhookEvent(context.Background(), database.CreateWebhookEventParams{Provider: "polka", EventID: "evt_1", EventType: "user.upgraded", Payload: payload})
+	if err != nil {
+		t.Fatalf("Error creating event: %v", err)
+	}
+
+	if rec := a.polka(body, testPolkaSecret); rec.Code != 204 {
+		t.Fatalf("Want 204 for the retry, got %d", rec.Code)
+	}
+	red, err := a.store.IsChirpyRed(context.Background(), bob.ID)
+	if err != nil || !red {
+		t.Errorf("Want bob upgraded by the retry, got %v, %v", red, err)
+	}
+	if rec := a.polka(body, testPolkaSecret); rec.Code != 204 {
+		t.Errorf("Want 204 for a duplicate, got %d", rec.Code)
+	}
+	event, err := a.store.GetWebhookEventByEventID(context.Background(), database.GetWebhookEventByEventIDParams{Provider: "polka", EventID: "evt_1"})
+	if err != nil || event.Status != "processed" {
+		t.Errorf("Want the event processed, got %+v, %v", event, err)
+	}
+}
+
+// TestWebhookEventRecord checks what is kept for each event, and that a
+// duplicate isn't recorded or applied again.
+func TestWebhookEventRecord(t *testing.T) {
+	a := newTestAPI(t)
+	admin := a.signup("admin")
+	a.setRole(admin, roleAdmin)
+	bob := a.signup("bob")
+
+	body := map[string]any{"id": "evt_1", "event": "user.upgraded", "data": map[string]any{"user_id": bob.ID}}
+	for range 2 {
+		if rec := a.polka(body, testPolkaSecret); rec.Code != 204 {
+			t.Fatalf("Want 204, got %d", rec.Code)
+		}
+	}
+
+	events := decode[[]database.WebhookEvent](t, a.expect(200, "GET", "/admin/webhooks/events", admin.Token, nil))
+	if len(events) != 1 {
+		t.Fatalf("Want the event recorded once, got %+v", events)
+	}
+	e := events[0]
+	if e.Provider != "polka" || e.EventID != "evt_1" || e.EventType != "user.upgraded" || e.Status != "processed" || e.Attempts != 1 || !e.ProcessedAt.Valid {
+		t.Errorf("Want one processed attempt, got %+v", e)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(e.Payload, &payload); err != nil || payload["id"] != "evt_1" {
+		t.Errorf("Want the raw payload kept, got %s, %v", e.Payload, err)
+	}
+
+	a.expect(403, "POST", "/admin/webhooks/events/"+e.ID.String()+"/replay", bob.Token, nil)
+	a.expect(400, "POST", "/admin/webhooks/events/not-an-id/replay", admin.Token, nil)
+}
+
+func TestSubscriptionLifecycle(t *testing.T) {
+	a := newTestAPI(t)
+	bob := a.signup("bob")
+	ctx := context.Background()
+
+	var n int
+	send := func(event string, data map[string]any) {
+		t.Helper()
+		n++
+		data["user_id"] = bob.ID
+		rec := a.polka(map[string]any{"id": fmt.Sprintf("evt_%d", n), "event": event, "data": data}, testPolkaSecret)
+		if rec.Code != 204 {
+			t.Fatalf("%s: want 204, got %d: %s", event, rec.Code, rec.Body.String())
+		}
+	}
+	expect := func(status string, red bool) database.Subscription {
+		t.Helper()
+		sub, err := a.store.GetSubscription(ctx, bob.ID)
+		if err != nil || sub.Status != status {
+			t.Errorf("Want a %s subscription, got %+v, %v", status, sub, err)
+		}
+		isRed, err := a.store.IsChirpyRed(ctx, bob.ID)
+		if err != nil || isRed != red {
+			t.Errorf("Want Red %v while %s, got %v, %v", red, status, isRed, err)
+		}
+		return sub
+	}
+	expireAt := func(at time.Time) {
+		t.Helper()
+		a.store.SetClock(func() time.Time { return at })
+		err := a.cfg.expireSubscriptions(ctx)
+		if err != nil {
+			t.Fatalf("expireSubscriptions returned error: %v", err)
+		}
+	}
+
+	periodEnd := time.Now().AddDate(0, 1, 0).UTC().Truncate(time.Second)
+	send("user.upgraded", map[string]any{"current_period_end": periodEnd})
+	if sub := expect("active", true); !sub.CurrentPeriodEnd.Equal(periodEnd) || sub.Plan != "chirpy_red" {
+		t.Errorf("Want the period end and plan from the event, got %+v", sub)
+	}
+
+	// A failed payment leaves a grace period after the period ends.
+	send("payment.failed", map[string]any{})
+	if sub := expect("past_due", true); !sub.GracePeriodEnd.Valid || !sub.GracePeriodEnd.Time.Equal(periodEnd.AddDate(0, 0, 7)) {
+		t.Errorf("Want a week's grace after the period, got %+v", sub)
+	}
+	send("payment.succeeded", map[string]any{"current_period_end": periodEnd})
+	if sub := expect("active", true); sub.GracePeriodEnd.Valid {
+		t.Errorf("Want no grace period once paid, got %+v", sub)
+	}
+
+	// A canceled subscription lasts until the end of the period.
+	send("subscription.canceled", map[string]any{})
+	expect("canceled", true)
+	expireAt(periodEnd.Add(-time.Hour))
+	expect("canceled", true)
+	expireAt(periodEnd.Add(time.Hour))
+	expect("expired", false)
+
+	// An active subscription that isn't renewed goes past due, then
+	// expires once the grace period is over.
+	send("subscription.renewed", map[string]any{"current_period_end": periodEnd.AddDate(0, 1, 0)})
+	expect("active", true)
+	expireAt(periodEnd.AddDate(0, 1, 1))
+	expect("past_due", true)
+	expireAt(periodEnd.AddDate(0, 1, 8))
+	expect("expired", false)
+
+	send("user.upgraded", map[string]any{})
+	expect("active", true)
+	send("user.downgraded", map[string]any{})
+	expect("expired", false)
+}
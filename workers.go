@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// runWorker calls fn once straight away and then every interval until ctx
+// is cancelled. Errors are logged and the worker carries on. A run that is
+// in progress when ctx is cancelled is left to finish, so fn doesn't see
+// the cancellation.
+func runWorker(ctx context.Context, logger *slog.Logger, interval time.Duration, fn func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := fn(context.WithoutCancel(ctx))
+		if err != nil {
+			logger.Error("worker run failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// startWorker runs a worker in the background until ctx is cancelled, and
+// registers it with the readiness check. maxRun is the longest one run of
+// fn can take before the worker is reported stuck.
+func (cfg *apiConfig) startWorker(ctx context.Context, name string, interval, maxRun time.Duration, fn func(context.Context) error) {
+	health := &workerHealth{interval: interval, maxRun: maxRun}
+	cfg.workersMu.Lock()
+	if cfg.workers == nil {
+		cfg.workers = map[string]*workerHealth{}
+	}
+	cfg.workers[name] = health
+	cfg.workersMu.Unlock()
+
+	cfg.background.Add(1)
+	go func() {
+		defer cfg.background.Done()
+		runWorker(ctx, cfg.logs.Logger("worker").With("worker", name), interval, func(ctx context.Context) error {
+			health.begin()
+			err := fn(ctx)
+			health.finish(err)
+			return err
+		})
+	}()
+}
+
+// shutdown reports not ready and waits for delay, so load balancers stop
+// sending new requests, then stops accepting connections. It waits up to
+// timeout for in-flight requests, and then for workers and data exports, to
+// finish, and writes any queued audit events. It returns the exit code: 1
+// if anything was still running, or unwritten, at the deadline.
+func (cfg *apiConfig) shutdown(delay, timeout time.Duration, servers ...*http.Server) int {
+	logger := cfg.logs.Logger("server")
+
+	cfg.shuttingDown.Store(true)
+	if delay > 0 {
+		logger.Info("waiting for traffic to drain", "delay", delay)
+		time.Sleep(delay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	exitCode := 0
+	for _, server := range servers {
+		err := server.Shutdown(ctx)
+		if err != nil {
+			logger.Error("failed to drain requests", "addr", server.Addr, "error", err)
+			exitCode = 1
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		cfg.background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logger.Error("background work still running at shutdown deadline", "timeout", timeout)
+		exitCode = 1
+	}
+
+	// Write the auth events of the requests that were drained.
+	err := cfg.flushAuditQueue(ctx)
+	if err != nil {
+		logger.Error("failed to write audit events", "error", err)
+		exitCode = 1
+	}
+
+	// Export the spans of the requests that were drained.
+	err = cfg.tracerProvider.Shutdown(ctx)
+	if err != nil {
+		logger.Error("failed to export traces", "error", err)
+	}
+	return exitCode
+}